@@ -0,0 +1,87 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+AddOffsetsToTxn Request (Version: 0) => transactional_id producer_id producer_epoch group_id
+  transactional_id => STRING
+  producer_id => INT64
+  producer_epoch => INT16
+  group_id => STRING
+
+version 1 is the same as version 0
+*/
+
+// AddOffsetsToTxnRequest adds the offsets of the group to the transaction, it is sent to transaction coordinator before TxnOffsetCommit
+type AddOffsetsToTxnRequest struct {
+	*RequestHeader
+	TransactionalID string
+	ProducerID      int64
+	ProducerEpoch   int16
+	GroupID         string
+}
+
+// NewAddOffsetsToTxnRequest creates a new AddOffsetsToTxnRequest
+func NewAddOffsetsToTxnRequest(clientID string, transactionalID string, producerID int64, producerEpoch int16, groupID string) *AddOffsetsToTxnRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_AddOffsetsToTxn,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &AddOffsetsToTxnRequest{
+		RequestHeader:   requestHeader,
+		TransactionalID: transactionalID,
+		ProducerID:      producerID,
+		ProducerEpoch:   producerEpoch,
+		GroupID:         groupID,
+	}
+}
+
+// Encode encodes AddOffsetsToTxnRequest to []byte
+func (r *AddOffsetsToTxnRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	writeString(buf, r.TransactionalID)
+	binary.Write(buf, binary.BigEndian, r.ProducerID)
+	binary.Write(buf, binary.BigEndian, r.ProducerEpoch)
+	writeString(buf, r.GroupID)
+
+	return buf.Bytes()
+}
+
+// DecodeAddOffsetsToTxnRequest decodes []byte to AddOffsetsToTxnRequest, just for test
+func DecodeAddOffsetsToTxnRequest(payload []byte) (r AddOffsetsToTxnRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	r.TransactionalID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	r.ProducerID = int64(binary.BigEndian.Uint64(payload[offset:]))
+	offset += 8
+
+	r.ProducerEpoch = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	r.GroupID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	return r, nil
+}
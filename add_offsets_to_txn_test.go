@@ -0,0 +1,32 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddOffsetsToTxnEncodeDecode(t *testing.T) {
+	convey.Convey("Test AddOffsetsToTxn Request and Response Encode and Decode", t, func() {
+		for _, version := range availableVersions[API_AddOffsetsToTxn] {
+			t.Logf("version: %v", version)
+
+			request := NewAddOffsetsToTxnRequest("healer", "txn-1", 100, 2, "group-1")
+			request.APIVersion = version
+
+			decodedRequest, err := DecodeAddOffsetsToTxnRequest(request.Encode(version))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+			response := AddOffsetsToTxnResponse{
+				CorrelationID:  1,
+				ThrottleTimeMS: 10,
+				ErrorCode:      0,
+			}
+			decodedResponse, err := NewAddOffsetsToTxnResponse(response.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(decodedResponse, convey.ShouldResemble, response)
+			convey.So(decodedResponse.Error(), convey.ShouldBeNil)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+AddPartitionsToTxn Request (Version: 0) => transactional_id producer_id producer_epoch [topics]
+  transactional_id => STRING
+  producer_id => INT64
+  producer_epoch => INT16
+  topics => name [partitions]
+    name => STRING
+    partitions => INT32
+
+version 1 is the same as version 0
+*/
+
+// AddPartitionsToTxnRequest adds partitions to the transaction before producing to them
+type AddPartitionsToTxnRequest struct {
+	*RequestHeader
+	TransactionalID string
+	ProducerID      int64
+	ProducerEpoch   int16
+	Topics          []AddPartitionsToTxnTopic
+}
+
+// AddPartitionsToTxnTopic is the partitions of one topic in AddPartitionsToTxnRequest
+type AddPartitionsToTxnTopic struct {
+	Name       string
+	Partitions []int32
+}
+
+// NewAddPartitionsToTxnRequest creates a new AddPartitionsToTxnRequest without partitions
+func NewAddPartitionsToTxnRequest(clientID string, transactionalID string, producerID int64, producerEpoch int16) *AddPartitionsToTxnRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_AddPartitionsToTxn,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &AddPartitionsToTxnRequest{
+		RequestHeader:   requestHeader,
+		TransactionalID: transactionalID,
+		ProducerID:      producerID,
+		ProducerEpoch:   producerEpoch,
+		Topics:          make([]AddPartitionsToTxnTopic, 0),
+	}
+}
+
+// AddPartition adds a partition to the request
+func (r *AddPartitionsToTxnRequest) AddPartition(topic string, partition int32) {
+	for i := range r.Topics {
+		if r.Topics[i].Name == topic {
+			r.Topics[i].Partitions = append(r.Topics[i].Partitions, partition)
+			return
+		}
+	}
+	r.Topics = append(r.Topics, AddPartitionsToTxnTopic{Name: topic, Partitions: []int32{partition}})
+}
+
+// Encode encodes AddPartitionsToTxnRequest to []byte
+func (r *AddPartitionsToTxnRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	writeString(buf, r.TransactionalID)
+	binary.Write(buf, binary.BigEndian, r.ProducerID)
+	binary.Write(buf, binary.BigEndian, r.ProducerEpoch)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeAddPartitionsToTxnRequest decodes []byte to AddPartitionsToTxnRequest, just for test
+func DecodeAddPartitionsToTxnRequest(payload []byte) (r AddPartitionsToTxnRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	r.TransactionalID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	r.ProducerID = int64(binary.BigEndian.Uint64(payload[offset:]))
+	offset += 8
+
+	r.ProducerEpoch = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]AddPartitionsToTxnTopic, topicCount)
+	for i := range r.Topics {
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]int32, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			r.Topics[i].Partitions[j] = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+		}
+	}
+
+	return r, nil
+}
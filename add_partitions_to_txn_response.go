@@ -0,0 +1,111 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+AddPartitionsToTxn Response (Version: 0) => throttle_time_ms [results]
+  throttle_time_ms => INT32
+  results => name [results]
+    name => STRING
+    results => partition_index partition_error_code
+      partition_index => INT32
+      partition_error_code => INT16
+*/
+
+// AddPartitionsToTxnResponse is the response of AddPartitionsToTxnRequest
+type AddPartitionsToTxnResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	Results        []AddPartitionsToTxnTopicResult
+}
+
+// AddPartitionsToTxnTopicResult is the result of one topic in AddPartitionsToTxnResponse
+type AddPartitionsToTxnTopicResult struct {
+	Name    string
+	Results []AddPartitionsToTxnPartitionResult
+}
+
+// AddPartitionsToTxnPartitionResult is the result of one partition in AddPartitionsToTxnResponse
+type AddPartitionsToTxnPartitionResult struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
+
+// Error returns the first error in the partitions
+func (r AddPartitionsToTxnResponse) Error() error {
+	for _, topic := range r.Results {
+		for _, partition := range topic.Results {
+			if partition.ErrorCode != 0 {
+				return fmt.Errorf("add partition %s-%d to txn error: %w", topic.Name, partition.PartitionIndex, KafkaError(partition.ErrorCode))
+			}
+		}
+	}
+	return nil
+}
+
+// NewAddPartitionsToTxnResponse creates a new AddPartitionsToTxnResponse from []byte
+func NewAddPartitionsToTxnResponse(payload []byte, version uint16) (r AddPartitionsToTxnResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("AddPartitionsToTxn response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Results = make([]AddPartitionsToTxnTopicResult, topicCount)
+	for i := range r.Results {
+		var o int
+		r.Results[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Results[i].Results = make([]AddPartitionsToTxnPartitionResult, partitionCount)
+		for j := range r.Results[i].Results {
+			r.Results[i].Results[j].PartitionIndex = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			r.Results[i].Results[j].ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+		}
+	}
+
+	return r, nil
+}
+
+// Encode encodes AddPartitionsToTxnResponse to []byte, just for test
+func (r AddPartitionsToTxnResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Results)))
+	for _, topic := range r.Results {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Results)))
+		for _, partition := range topic.Results {
+			binary.Write(buf, binary.BigEndian, partition.PartitionIndex)
+			binary.Write(buf, binary.BigEndian, partition.ErrorCode)
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,45 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddPartitionsToTxnEncodeDecode(t *testing.T) {
+	convey.Convey("Test AddPartitionsToTxn Request and Response Encode and Decode", t, func() {
+		for _, version := range availableVersions[API_AddPartitionsToTxn] {
+			t.Logf("version: %v", version)
+
+			request := NewAddPartitionsToTxnRequest("healer", "txn-1", 100, 2)
+			request.APIVersion = version
+			request.AddPartition("topic-1", 0)
+			request.AddPartition("topic-1", 1)
+			request.AddPartition("topic-2", 0)
+			convey.So(len(request.Topics), convey.ShouldEqual, 2)
+
+			decodedRequest, err := DecodeAddPartitionsToTxnRequest(request.Encode(version))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+			response := AddPartitionsToTxnResponse{
+				CorrelationID:  1,
+				ThrottleTimeMS: 10,
+				Results: []AddPartitionsToTxnTopicResult{
+					{
+						Name: "topic-1",
+						Results: []AddPartitionsToTxnPartitionResult{
+							{PartitionIndex: 0, ErrorCode: 0},
+							{PartitionIndex: 1, ErrorCode: 51},
+						},
+					},
+				},
+			}
+			decodedResponse, err := NewAddPartitionsToTxnResponse(response.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(decodedResponse, convey.ShouldResemble, response)
+			convey.So(errors.Is(decodedResponse.Error(), KafkaError(51)), convey.ShouldBeTrue)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+/*
+AlterClientQuotas Request (Version: 0) => [entries] validate_only
+  entries => [entity] [ops]
+    entity => entity_type entity_name
+      entity_type => STRING
+      entity_name => NULLABLE_STRING
+    ops => key value remove
+      key => STRING
+      value => FLOAT64
+      remove => BOOLEAN
+  validate_only => BOOLEAN
+*/
+
+// AlterClientQuotasRequest alters the quotas of the entities.
+// if ValidateOnly is true, the request is validated but the quotas are not altered
+type AlterClientQuotasRequest struct {
+	*RequestHeader
+	Entries      []AlterClientQuotasEntry
+	ValidateOnly bool
+}
+
+// AlterClientQuotasEntry is the quota operations of one entity
+type AlterClientQuotasEntry struct {
+	Entity []QuotaEntity
+	Ops    []QuotaOp
+}
+
+// NewAlterClientQuotasRequest creates a new AlterClientQuotasRequest
+func NewAlterClientQuotasRequest(clientID string, entries []AlterClientQuotasEntry, validateOnly bool) *AlterClientQuotasRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_AlterClientQuotas,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	if entries == nil {
+		entries = make([]AlterClientQuotasEntry, 0)
+	}
+	return &AlterClientQuotasRequest{
+		RequestHeader: requestHeader,
+		Entries:       entries,
+		ValidateOnly:  validateOnly,
+	}
+}
+
+// Encode encodes AlterClientQuotasRequest to []byte
+func (r *AlterClientQuotasRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Entries)))
+	for _, entry := range r.Entries {
+		writeQuotaEntity(buf, entry.Entity)
+		binary.Write(buf, binary.BigEndian, int32(len(entry.Ops)))
+		for _, op := range entry.Ops {
+			writeString(buf, op.Key)
+			binary.Write(buf, binary.BigEndian, op.Value)
+			binary.Write(buf, binary.BigEndian, op.Remove)
+		}
+	}
+	binary.Write(buf, binary.BigEndian, r.ValidateOnly)
+
+	return buf.Bytes()
+}
+
+// DecodeAlterClientQuotasRequest decodes []byte to AlterClientQuotasRequest, just for test
+func DecodeAlterClientQuotasRequest(payload []byte, version uint16) (r AlterClientQuotasRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	entryCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Entries = make([]AlterClientQuotasEntry, entryCount)
+	for i := range r.Entries {
+		r.Entries[i].Entity, o = decodeQuotaEntity(payload[offset:])
+		offset += o
+
+		opCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Entries[i].Ops = make([]QuotaOp, opCount)
+		for j := range r.Entries[i].Ops {
+			op := &r.Entries[i].Ops[j]
+			op.Key, o = nonnullableString(payload[offset:])
+			offset += o
+			op.Value = math.Float64frombits(binary.BigEndian.Uint64(payload[offset:]))
+			offset += 8
+			op.Remove = payload[offset] != 0
+			offset++
+		}
+	}
+	r.ValidateOnly = payload[offset] != 0
+
+	return r, nil
+}
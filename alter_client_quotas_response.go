@@ -0,0 +1,101 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+AlterClientQuotas Response (Version: 0) => throttle_time_ms [entries]
+  throttle_time_ms => INT32
+  entries => error_code error_message [entity]
+    error_code => INT16
+    error_message => NULLABLE_STRING
+    entity => entity_type entity_name
+      entity_type => STRING
+      entity_name => NULLABLE_STRING
+*/
+
+// AlterClientQuotasResponse is the response of AlterClientQuotasRequest
+type AlterClientQuotasResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	Entries        []AlterClientQuotasEntryResult
+}
+
+// AlterClientQuotasEntryResult is the result of one entity
+type AlterClientQuotasEntryResult struct {
+	ErrorCode    int16
+	ErrorMessage *string
+	Entity       []QuotaEntity
+}
+
+// Error returns the first error in the entries
+func (r AlterClientQuotasResponse) Error() error {
+	for _, entry := range r.Entries {
+		if entry.ErrorCode == 0 {
+			continue
+		}
+		if entry.ErrorMessage != nil {
+			return fmt.Errorf("alter client quotas of %s error: %w: %s", quotaEntityString(entry.Entity), KafkaError(entry.ErrorCode), *entry.ErrorMessage)
+		}
+		return fmt.Errorf("alter client quotas of %s error: %w", quotaEntityString(entry.Entity), KafkaError(entry.ErrorCode))
+	}
+	return nil
+}
+
+// NewAlterClientQuotasResponse creates a new AlterClientQuotasResponse from []byte
+func NewAlterClientQuotasResponse(payload []byte, version uint16) (r AlterClientQuotasResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("AlterClientQuotas response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	entryCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Entries = make([]AlterClientQuotasEntryResult, entryCount)
+	for i := range r.Entries {
+		entry := &r.Entries[i]
+		entry.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+		offset += 2
+		var o int
+		entry.ErrorMessage, o = nullableString(payload[offset:])
+		offset += o
+		entry.Entity, o = decodeQuotaEntity(payload[offset:])
+		offset += o
+	}
+
+	return r, nil
+}
+
+// Encode encodes AlterClientQuotasResponse to []byte, just for test
+func (r AlterClientQuotasResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Entries)))
+	for _, entry := range r.Entries {
+		binary.Write(buf, binary.BigEndian, entry.ErrorCode)
+		writeNullableString(buf, entry.ErrorMessage)
+		writeQuotaEntity(buf, entry.Entity)
+	}
+
+	return buf.Bytes()
+}
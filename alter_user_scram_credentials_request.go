@@ -0,0 +1,172 @@
+package healer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+AlterUserScramCredentials Request (Version: 0) => [deletions] [upsertions] TAG_BUFFER
+  deletions => name mechanism TAG_BUFFER
+    name => COMPACT_STRING
+    mechanism => INT8
+  upsertions => name mechanism iterations salt salted_password TAG_BUFFER
+    name => COMPACT_STRING
+    mechanism => INT8
+    iterations => INT32
+    salt => COMPACT_BYTES
+    salted_password => COMPACT_BYTES
+*/
+
+// the default iterations and salt length are the same as kafka-configs.sh
+const (
+	DefaultScramIterations = 4096
+	scramSaltLength        = 32
+)
+
+// ScramCredentialDeletion deletes the credential of the mechanism of the user
+type ScramCredentialDeletion struct {
+	Name      string
+	Mechanism ScramMechanismType
+}
+
+// ScramCredentialUpsertion creates or updates the credential of the mechanism of the user.
+// SaltedPassword is Hi(password, salt, iterations), the password itself is never sent to the broker
+type ScramCredentialUpsertion struct {
+	Name           string
+	Mechanism      ScramMechanismType
+	Iterations     int32
+	Salt           []byte
+	SaltedPassword []byte
+}
+
+// NewScramCredentialUpsertion salts the password with a random salt and creates a ScramCredentialUpsertion
+func NewScramCredentialUpsertion(name string, mechanismType ScramMechanismType, password string, iterations int32) (ScramCredentialUpsertion, error) {
+	mechanism, err := mechanismType.Mechanism()
+	if err != nil {
+		return ScramCredentialUpsertion{}, err
+	}
+	if iterations <= 0 {
+		return ScramCredentialUpsertion{}, fmt.Errorf("invalid scram iterations: %d", iterations)
+	}
+
+	salt := make([]byte, scramSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return ScramCredentialUpsertion{}, fmt.Errorf("generate scram salt error: %w", err)
+	}
+	return ScramCredentialUpsertion{
+		Name:           name,
+		Mechanism:      mechanismType,
+		Iterations:     iterations,
+		Salt:           salt,
+		SaltedPassword: mechanism.SaltedPassword(password, salt, int(iterations)),
+	}, nil
+}
+
+// AlterUserScramCredentialsRequest deletes and upserts SCRAM credentials
+type AlterUserScramCredentialsRequest struct {
+	*RequestHeader
+	Deletions  []ScramCredentialDeletion
+	Upsertions []ScramCredentialUpsertion
+}
+
+// NewAlterUserScramCredentialsRequest creates a new AlterUserScramCredentialsRequest
+func NewAlterUserScramCredentialsRequest(clientID string, deletions []ScramCredentialDeletion, upsertions []ScramCredentialUpsertion) *AlterUserScramCredentialsRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_AlterUserScramCredentials,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	if deletions == nil {
+		deletions = make([]ScramCredentialDeletion, 0)
+	}
+	if upsertions == nil {
+		upsertions = make([]ScramCredentialUpsertion, 0)
+	}
+	return &AlterUserScramCredentialsRequest{
+		RequestHeader: requestHeader,
+		Deletions:     deletions,
+		Upsertions:    upsertions,
+	}
+}
+
+// Encode encodes AlterUserScramCredentialsRequest to []byte
+func (r *AlterUserScramCredentialsRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	buf.Write(encodeCompactArrayLength(len(r.Deletions)))
+	for _, d := range r.Deletions {
+		writeCompactString(buf, d.Name)
+		binary.Write(buf, binary.BigEndian, d.Mechanism)
+		buf.Write(TaggedFields(nil).Encode())
+	}
+
+	buf.Write(encodeCompactArrayLength(len(r.Upsertions)))
+	for _, u := range r.Upsertions {
+		writeCompactString(buf, u.Name)
+		binary.Write(buf, binary.BigEndian, u.Mechanism)
+		binary.Write(buf, binary.BigEndian, u.Iterations)
+		buf.Write(encodeCompactBytes(u.Salt))
+		buf.Write(encodeCompactBytes(u.SaltedPassword))
+		buf.Write(TaggedFields(nil).Encode())
+	}
+	buf.Write(TaggedFields(nil).Encode())
+
+	return buf.Bytes()
+}
+
+// DecodeAlterUserScramCredentialsRequest decodes []byte to AlterUserScramCredentialsRequest, just for test
+func DecodeAlterUserScramCredentialsRequest(payload []byte, version uint16) (r AlterUserScramCredentialsRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	deletionCount, o := compactArrayLength(payload[offset:])
+	offset += o
+	r.Deletions = make([]ScramCredentialDeletion, deletionCount)
+	for i := range r.Deletions {
+		d := &r.Deletions[i]
+		d.Name, o = compactString(payload[offset:])
+		offset += o
+		d.Mechanism = ScramMechanismType(payload[offset])
+		offset++
+		_, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	upsertionCount, o := compactArrayLength(payload[offset:])
+	offset += o
+	r.Upsertions = make([]ScramCredentialUpsertion, upsertionCount)
+	for i := range r.Upsertions {
+		u := &r.Upsertions[i]
+		u.Name, o = compactString(payload[offset:])
+		offset += o
+		u.Mechanism = ScramMechanismType(payload[offset])
+		offset++
+		u.Iterations = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		u.Salt, o = compactBytes(payload[offset:])
+		offset += o
+		u.SaltedPassword, o = compactBytes(payload[offset:])
+		offset += o
+		_, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	return r, nil
+}
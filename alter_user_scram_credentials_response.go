@@ -0,0 +1,110 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+AlterUserScramCredentials Response (Version: 0) => throttle_time_ms [results] TAG_BUFFER
+  throttle_time_ms => INT32
+  results => user error_code error_message TAG_BUFFER
+    user => COMPACT_STRING
+    error_code => INT16
+    error_message => COMPACT_NULLABLE_STRING
+*/
+
+// AlterUserScramCredentialsResponse is the response of AlterUserScramCredentialsRequest
+type AlterUserScramCredentialsResponse struct {
+	ResponseHeader
+	ThrottleTimeMS int32                             `json:"throttle_time_ms"`
+	Results        []AlterUserScramCredentialsResult `json:"results"`
+
+	TaggedFields TaggedFields `json:"tagged_fields"`
+}
+
+// AlterUserScramCredentialsResult is the result of one user
+type AlterUserScramCredentialsResult struct {
+	User         string  `json:"user"`
+	ErrorCode    int16   `json:"error_code"`
+	ErrorMessage *string `json:"error_message"`
+
+	TaggedFields TaggedFields `json:"tagged_fields"`
+}
+
+// Error returns the first error of the users
+func (r AlterUserScramCredentialsResponse) Error() error {
+	for _, result := range r.Results {
+		if result.ErrorCode != 0 {
+			return fmt.Errorf("alter scram credentials of %s error: %w: %s", result.User, KafkaError(result.ErrorCode), stringOrEmpty(result.ErrorMessage))
+		}
+	}
+	return nil
+}
+
+// NewAlterUserScramCredentialsResponse creates a new AlterUserScramCredentialsResponse from []byte
+func NewAlterUserScramCredentialsResponse(payload []byte, version uint16) (r AlterUserScramCredentialsResponse, err error) {
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("AlterUserScramCredentials response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset := 4
+
+	header, o := DecodeResponseHeader(payload[offset:], API_AlterUserScramCredentials, version)
+	offset += o
+	r.ResponseHeader = header
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	resultCount, o := compactArrayLength(payload[offset:])
+	offset += o
+	if resultCount >= 0 {
+		r.Results = make([]AlterUserScramCredentialsResult, resultCount)
+	}
+	for i := range r.Results {
+		result := &r.Results[i]
+		result.User, o = compactString(payload[offset:])
+		offset += o
+
+		result.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+		offset += 2
+
+		result.ErrorMessage, o = compactNullableString(payload[offset:])
+		offset += o
+
+		result.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	r.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+	offset += o
+
+	return r, nil
+}
+
+// Encode encodes AlterUserScramCredentialsResponse to []byte, just for test
+func (r AlterUserScramCredentialsResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	buf.Write(r.ResponseHeader.Encode())
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+
+	buf.Write(encodeCompactArrayLength(len(r.Results)))
+	for _, result := range r.Results {
+		writeCompactString(buf, result.User)
+		binary.Write(buf, binary.BigEndian, result.ErrorCode)
+		writeCompactNullableString(buf, result.ErrorMessage)
+		buf.Write(result.TaggedFields.Encode())
+	}
+	buf.Write(r.TaggedFields.Encode())
+
+	return buf.Bytes()
+}
@@ -1,18 +1,169 @@
 package healer
 
 import (
+	"errors"
+	"fmt"
 	"sort"
+	"strings"
+	"sync"
 )
 
+var errUnknownAssignmentStrategy = errors.New("unknown partition.assignment.strategy")
+
 // AssignmentStrategy is the interface for different assignment strategies, it returns GroupAssignment
 type AssignmentStrategy interface {
+	// Name is the protocol name sent in JoinGroup request
+	Name() string
 	// generally topicMetadatas is returned by metaDataRequest sent by GroupConsumer
 	Assign([]Member, []TopicMetadata) GroupAssignment
 }
 
+// CooperativeAssignmentStrategy is implemented by the strategies which support the cooperative rebalance protocol.
+// With these strategies, members keep consuming during rebalance, and only stop the partitions which are revoked from them
+type CooperativeAssignmentStrategy interface {
+	AssignmentStrategy
+	Cooperative() bool
+}
+
+func isCooperative(s AssignmentStrategy) bool {
+	c, ok := s.(CooperativeAssignmentStrategy)
+	return ok && c.Cooperative()
+}
+
+// UserDataAssignmentStrategy could be implemented by AssignmentStrategy to send its own user data in JoinGroup request.
+// the leader gets the user data of all members from their ProtocolMetadata in Assign
+type UserDataAssignmentStrategy interface {
+	AssignmentStrategy
+	// UserData is called before joining group, assignments is the last assignment of the member
+	UserData(assignments []*PartitionAssignment, generationID int32) []byte
+}
+
+// strategies are shared by all group consumers, so they should not keep any state
+var assignmentStrategies = struct {
+	sync.RWMutex
+	m map[string]AssignmentStrategy
+}{m: make(map[string]AssignmentStrategy)}
+
+func init() {
+	RegisterAssignmentStrategy(&rangeAssignmentStrategy{})
+	RegisterAssignmentStrategy(&roundRobinAssignmentStrategy{})
+	RegisterAssignmentStrategy(&stickyAssignmentStrategy{})
+	RegisterAssignmentStrategy(&cooperativeStickyAssignmentStrategy{})
+}
+
+// RegisterAssignmentStrategy registers an AssignmentStrategy, then it could be used in partition.assignment.strategy by its name.
+// the strategy registered before with the same name is replaced
+func RegisterAssignmentStrategy(s AssignmentStrategy) {
+	assignmentStrategies.Lock()
+	defer assignmentStrategies.Unlock()
+	assignmentStrategies.m[s.Name()] = s
+}
+
+// getAssignmentStrategies returns the strategies of partition.assignment.strategy, which is a comma separated list of names in preference order.
+// range is used if it is empty
+func getAssignmentStrategies(names string) ([]AssignmentStrategy, error) {
+	assignmentStrategies.RLock()
+	defer assignmentStrategies.RUnlock()
+
+	strategies := make([]AssignmentStrategy, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		s, ok := assignmentStrategies.m[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownAssignmentStrategy, name)
+		}
+		strategies = append(strategies, s)
+	}
+	if len(strategies) == 0 {
+		strategies = append(strategies, assignmentStrategies.m[AssignmentStrategyRange])
+	}
+	return strategies, nil
+}
+
+type topicPartition struct {
+	topic       string
+	partitionID int32
+}
+
+func sortTopicPartitions(partitions []topicPartition) {
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].topic != partitions[j].topic {
+			return partitions[i].topic < partitions[j].topic
+		}
+		return partitions[i].partitionID < partitions[j].partitionID
+	})
+}
+
+// subscribedPartitions returns the sorted member ids, the subscriptions of each member,
+// and the sorted partitions of the topics which are subscribed by any member
+func subscribedPartitions(members []Member, topicMetadatas []TopicMetadata) (memberIDs []string, subscriptions map[string]map[string]bool, partitions []topicPartition) {
+	memberIDs = make([]string, 0, len(members))
+	subscriptions = make(map[string]map[string]bool)
+	partitions = make([]topicPartition, 0)
+
+	subscribed := make(map[string]bool)
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.MemberID)
+		subscriptions[member.MemberID] = make(map[string]bool)
+		for _, topic := range NewProtocolMetadata(member.MemberMetadata).Subscription {
+			subscriptions[member.MemberID][topic] = true
+			subscribed[topic] = true
+		}
+	}
+	sort.Strings(memberIDs)
+
+	for _, topicMetadata := range topicMetadatas {
+		if !subscribed[topicMetadata.TopicName] {
+			continue
+		}
+		for _, p := range topicMetadata.PartitionMetadatas {
+			partitions = append(partitions, topicPartition{topicMetadata.TopicName, p.PartitionID})
+		}
+	}
+	sortTopicPartitions(partitions)
+	return
+}
+
+// newGroupAssignment encodes the assignments of all members to GroupAssignment
+func newGroupAssignment(assignments map[string][]topicPartition) GroupAssignment {
+	memberIDs := make([]string, 0, len(assignments))
+	for memberID := range assignments {
+		memberIDs = append(memberIDs, memberID)
+	}
+	sort.Strings(memberIDs)
+
+	groupAssignment := make(GroupAssignment, len(memberIDs))
+	for i, memberID := range memberIDs {
+		sortTopicPartitions(assignments[memberID])
+		memberAssignment := &MemberAssignment{
+			Version:              0,
+			PartitionAssignments: make([]*PartitionAssignment, 0),
+		}
+		for _, tp := range assignments[memberID] {
+			n := len(memberAssignment.PartitionAssignments)
+			if n == 0 || memberAssignment.PartitionAssignments[n-1].Topic != tp.topic {
+				memberAssignment.PartitionAssignments = append(memberAssignment.PartitionAssignments, &PartitionAssignment{Topic: tp.topic})
+				n++
+			}
+			memberAssignment.PartitionAssignments[n-1].Partitions = append(memberAssignment.PartitionAssignments[n-1].Partitions, tp.partitionID)
+		}
+		groupAssignment[i].MemberID = memberID
+		groupAssignment[i].MemberAssignment = memberAssignment.Encode()
+	}
+	return groupAssignment
+}
+
 type rangeAssignmentStrategy struct {
 }
 
+// Name implements AssignmentStrategy interface
+func (r *rangeAssignmentStrategy) Name() string {
+	return AssignmentStrategyRange
+}
+
 // partitions in one topic
 // XXX (3,5)=>[(0,2),(2,2),(4,1)]  (5,10)=>[(0,2), (2,2), (4,2), (6,2), (8,2)]
 func (r *rangeAssignmentStrategy) assignPartitions(members []string, partitions []int32) map[string][]int32 {
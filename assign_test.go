@@ -1,6 +1,11 @@
 package healer
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
 
 func TestAssgin(t *testing.T) {
 	var (
@@ -37,3 +42,50 @@ func TestAssgin(t *testing.T) {
 		t.Error("partitions in memeber 2 != 5")
 	}
 }
+
+type testAssignmentStrategy struct {
+	rangeAssignmentStrategy
+}
+
+func (s *testAssignmentStrategy) Name() string {
+	return "test"
+}
+
+func TestGetAssignmentStrategies(t *testing.T) {
+	convey.Convey("get strategies by names in preference order", t, func() {
+		strategies, err := getAssignmentStrategies("")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(strategies, convey.ShouldResemble, []AssignmentStrategy{&rangeAssignmentStrategy{}})
+
+		_, err = getAssignmentStrategies("range,test")
+		convey.So(errors.Is(err, errUnknownAssignmentStrategy), convey.ShouldBeTrue)
+
+		RegisterAssignmentStrategy(&testAssignmentStrategy{})
+		strategies, err = getAssignmentStrategies("test, cooperative-sticky,roundrobin")
+		convey.So(err, convey.ShouldBeNil)
+		names := []string{}
+		for _, s := range strategies {
+			names = append(names, s.Name())
+		}
+		convey.So(names, convey.ShouldResemble, []string{"test", "cooperative-sticky", "roundrobin"})
+		convey.So(isCooperative(strategies[0]), convey.ShouldBeFalse)
+		convey.So(isCooperative(strategies[1]), convey.ShouldBeTrue)
+	})
+}
+
+func TestRoundRobinAssign(t *testing.T) {
+	convey.Convey("partitions are assigned one by one, members not subscribing the topic are skipped", t, func() {
+		s := &roundRobinAssignmentStrategy{}
+		members := []Member{
+			newTestMember("a", []string{"test", "other"}, nil),
+			newTestMember("b", []string{"test"}, nil),
+			newTestMember("c", []string{"test", "other"}, nil),
+		}
+		topics := []TopicMetadata{newTestTopicMetadata("test", 4), newTestTopicMetadata("other", 3)}
+		convey.So(decodeGroupAssignment(s.Assign(members, topics)), convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"other": {0, 2}, "test": {2}},
+			"b": {"test": {0, 3}},
+			"c": {"other": {1}, "test": {1}},
+		})
+	})
+}
@@ -3,13 +3,13 @@ package healer
 import (
 	"bytes"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -23,14 +23,23 @@ type Broker struct {
 	conn        net.Conn
 	apiVersions []APIVersion
 
+	// pipeline of conn, it is created when the first request is sent on the conn
+	pipeline *pipeline
+
 	correlationID uint32
 
-	mux       sync.Mutex
-	closeLock sync.Mutex
+	// the sasl session of the conn expires at saslSessionExpiry if it is not zero, the conn is re-authenticated at saslReauthAt
+	saslReauthAt      time.Time
+	saslSessionExpiry time.Time
+	// tokenProvider of OAUTHBEARER is created at the first authentication and reused later, so the token is cached by it
+	tokenProvider TokenProvider
+
+	// mux protects conn and pipeline: creating, closing and request sending. it is not held while waiting for responses
+	mux sync.Mutex
 }
 
 var (
-	errTLSConfig = errors.New("tls is enabled but either cert or key or ca is not set")
+	errBrokerClosed = errors.New("broker is closed")
 )
 
 // NewBroker is only called in NewBrokers, user must always init a Brokers instance by NewBrokers
@@ -115,10 +124,12 @@ func newConn(address string, config *BrokerConfig) (net.Conn, error) {
 	}
 
 	if config.TLSEnabled {
-		if config.TLS == nil || config.TLS.Cert == "" || config.TLS.Key == "" || config.TLS.CA == "" {
-			return nil, errTLSConfig
+		// one-way tls with system roots if tls is not configured
+		c := config.TLS
+		if c == nil {
+			c = &TLSConfig{}
 		}
-		if tlsConfig, err := createTLSConfig(config.TLS); err != nil {
+		if tlsConfig, err := createTLSConfig(c); err != nil {
 			return nil, err
 		} else {
 			return tls.DialWithDialer(&dialer, "tcp", address, tlsConfig)
@@ -128,62 +139,78 @@ func newConn(address string, config *BrokerConfig) (net.Conn, error) {
 	}
 }
 
-func createTLSConfig(tlsConfig *TLSConfig) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(tlsConfig.Cert, tlsConfig.Key)
-	if err != nil {
-		return nil, err
-	}
-
-	caCert, err := os.ReadFile(tlsConfig.CA)
-	if err != nil {
-		return nil, err
-	}
-
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-
-	t := &tls.Config{
-		Certificates:       []tls.Certificate{cert},
-		RootCAs:            caCertPool,
-		InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
-	}
-
-	if tlsConfig.ServerName != "" {
-		t.ServerName = tlsConfig.ServerName
-	}
-
-	return t, nil
-}
+// sasl session is re-authenticated after this fraction of its lifetime
+const saslReauthFactor = 0.9
 
 func (broker *Broker) sendSaslAuthenticate() error {
 	var (
 		clientID  = "healer-sals-authenticate"
 		mechanism = broker.config.Sasl.Mechanism
-		user      = broker.config.Sasl.User
-		password  = broker.config.Sasl.Password
 	)
 
-	saslHandShakeRequest := NewSaslHandShakeRequest(clientID, mechanism)
-	resp, err := broker.RequestAndGet(saslHandShakeRequest)
+	saslConfig, err := broker.saslConfig()
 	if err != nil {
 		return err
 	}
-	if resp.Error() != nil {
-		return resp.Error()
+	saslAuth, err := NewSaslAuth(saslConfig)
+	if err != nil {
+		return err
+	}
+	authAt := time.Now()
+
+	// not use RequestAndGet, broker.mux is held if it is called when reopening the conn
+	saslHandShakeRequest := NewSaslHandShakeRequest(clientID, mechanism)
+	if _, err := broker.requestAndParse(saslHandShakeRequest); err != nil {
+		return err
 	}
 
 	// authenticate
-	saslAuthenticateRequest := NewSaslAuthenticateRequest(clientID, user, password, mechanism)
-	resp, err = broker.RequestAndGet(saslAuthenticateRequest)
+	resp, err := broker.requestAndParse(NewSaslAuthenticateRequestWithBytes(clientID, saslAuth.Encode()))
 	if err != nil {
 		return err
 	}
-	if resp.Error() != nil {
-		return resp.Error()
+
+	if challenger, ok := saslAuth.(SaslAuthChallenger); ok {
+		for {
+			clientBytes, done, err := challenger.Challenge(resp.(SaslAuthenticateResponse).SaslAuthBytes)
+			if err != nil {
+				return fmt.Errorf("sasl %s authenticate error: %w", mechanism, err)
+			}
+			if done {
+				break
+			}
+			if resp, err = broker.requestAndParse(NewSaslAuthenticateRequestWithBytes(clientID, clientBytes)); err != nil {
+				return err
+			}
+		}
+	}
+
+	broker.saslReauthAt, broker.saslSessionExpiry = time.Time{}, time.Time{}
+	if lifetime := time.Duration(resp.(SaslAuthenticateResponse).SessionLifetimeMS) * time.Millisecond; lifetime > 0 {
+		broker.saslReauthAt = authAt.Add(time.Duration(float64(lifetime) * saslReauthFactor))
+		broker.saslSessionExpiry = authAt.Add(lifetime)
+		logger.V(1).Info("sasl session lifetime", "broker", broker.String(), "lifetime", lifetime, "reauthAt", broker.saslReauthAt)
 	}
 	return nil
 }
 
+// saslConfig returns the sasl config with the token provider of the broker if the mechanism is OAUTHBEARER
+func (broker *Broker) saslConfig() (SaslConfig, error) {
+	config := broker.config.Sasl
+	if !strings.EqualFold(config.Mechanism, "OAUTHBEARER") {
+		return config, nil
+	}
+	if broker.tokenProvider == nil {
+		provider, err := config.tokenProvider()
+		if err != nil {
+			return config, err
+		}
+		broker.tokenProvider = provider
+	}
+	config.TokenProvider = broker.tokenProvider
+	return config, nil
+}
+
 // GetAddress returns the broker address
 func (broker *Broker) GetAddress() string {
 	return broker.address
@@ -195,23 +222,58 @@ func (broker *Broker) String() string {
 
 // Close closes the connection to the broker
 func (broker *Broker) Close() {
-	logger.Info("close broker", "broker", broker.String())
+	broker.mux.Lock()
+	defer broker.mux.Unlock()
+	broker.close()
+}
 
-	broker.closeLock.Lock()
-	defer broker.closeLock.Unlock()
+// close closes the conn and the pipeline of it. broker.mux must be held
+func (broker *Broker) close() {
+	logger.Info("close broker", "broker", broker.String())
 
+	if broker.pipeline != nil {
+		broker.pipeline.close(errBrokerClosed)
+		broker.pipeline = nil
+	}
 	if broker.conn != nil {
 		broker.conn.Close()
 		broker.conn = nil
 	}
 }
+
+// ensureOpen creates a new conn if the broker is closed or the pipeline of the conn failed. broker.mux must be held.
+// the conn is re-authenticated if the sasl session is going to expire, and recreated if it has expired
 func (broker *Broker) ensureOpen() (err error) {
 	if broker.conn != nil {
-		return nil
+		if broker.pipeline != nil && broker.pipeline.closed() {
+			broker.close()
+			return broker.createConnAndAuth()
+		}
+		if broker.saslSessionExpiry.IsZero() {
+			return nil
+		}
+
+		now := time.Now()
+		if now.Before(broker.saslReauthAt) {
+			return nil
+		}
+		if now.Before(broker.saslSessionExpiry) {
+			logger.Info("re-authenticate sasl session", "broker", broker.String())
+			if err = broker.sendSaslAuthenticate(); err == nil {
+				return nil
+			}
+			logger.Error(err, "re-authenticate failed, recreate the conn", "broker", broker.String())
+		}
+		broker.close()
 	}
 	return broker.createConnAndAuth()
 }
 
+// isConnError returns true if the conn could not be used anymore
+func isConnError(err error) bool {
+	return os.IsTimeout(err) || errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, syscall.EPIPE)
+}
+
 // Request sends a request to the broker and returns a readParser
 // user should call RequestAndGet() to get the response
 func (broker *Broker) Request(r Request) (ReadParser, error) {
@@ -235,26 +297,27 @@ func (broker *Broker) Request(r Request) (ReadParser, error) {
 	return rp, err
 }
 
-// RequestAndGet sends a request to the broker and returns the response
+// RequestAndGet sends a request to the broker and returns the response.
+// broker.mux is only held while sending the request, so requests from different goroutines are in flight at the same time,
+// at most max.in.flight.requests.per.connection
 func (broker *Broker) RequestAndGet(r Request) (resp Response, err error) {
 	broker.mux.Lock()
-	defer broker.mux.Unlock()
-
 	if err := broker.ensureOpen(); err != nil {
+		broker.mux.Unlock()
 		return nil, err
 	}
-
-	defer func() {
-		if os.IsTimeout(err) || errors.Is(err, io.EOF) || errors.Is(err, syscall.EPIPE) {
-			broker.Close()
-		}
-	}()
-
 	rp, err := broker.Request(r)
+	broker.mux.Unlock()
 	if err != nil {
 		return nil, err
 	}
 
+	defer func() {
+		if p, ok := rp.(defaultReadParser); ok && isConnError(err) {
+			broker.closePipeline(p.pipeline, err)
+		}
+	}()
+
 	resp, err = rp.ReadAndParse()
 	if err != nil {
 		return nil, err
@@ -262,75 +325,87 @@ func (broker *Broker) RequestAndGet(r Request) (resp Response, err error) {
 	return resp, resp.Error()
 }
 
+// closePipeline marks the pipeline failed after a conn error, the conn is closed and reopened by ensureOpen in the next request.
+// broker.conn is not touched here, it is only changed with broker.mux held
+func (broker *Broker) closePipeline(p *pipeline, err error) {
+	if p != nil {
+		p.close(err)
+	}
+}
+
+// request puts the payload to the pipeline of the conn, and returns a readParser to wait for the response. broker.mux must be held
 func (broker *Broker) request(payload []byte, timeout int) (defaultReadParser, error) {
+	if broker.conn == nil {
+		return defaultReadParser{}, errBrokerClosed
+	}
 	logger.V(5).Info("send request", "src", broker.conn.LocalAddr(), "dst", broker.conn.RemoteAddr())
-	api := ApiKey(binary.BigEndian.Uint16(payload[4:]))
-	apiVersion := binary.BigEndian.Uint16(payload[6:])
-	correlationID := binary.BigEndian.Uint32(payload[8:])
-	logger.V(5).Info("request info", "length", len(payload), "api", api, "apiVersion", apiVersion, "correlationID", correlationID, "timeout", timeout)
 
-	if _, err := io.Copy(broker.conn, bytes.NewBuffer(payload)); err != nil {
+	if broker.pipeline == nil {
+		broker.pipeline = newPipeline(broker.conn, broker.config.MaxInFlightRequestsPerConnection)
+	}
+	p := broker.pipeline
+
+	correlationID := binary.BigEndian.Uint32(payload[8:])
+	req, err := p.send(correlationID, payload, timeout)
+	if err != nil {
 		return defaultReadParser{}, err
 	}
 
 	rp := defaultReadParser{
-		broker:  broker,
-		timeout: timeout,
+		broker:   broker,
+		timeout:  timeout,
+		pipeline: p,
+		req:      req,
 	}
 	return rp, nil
 }
 
-func (broker *Broker) requestStreamingly(payload []byte, timeout int) (r io.Reader, responseLength uint32, err error) {
-	defer func() {
-		if err != nil {
-			broker.Close()
-		}
-	}()
-
-	logger.V(5).Info("send request", "src", broker.conn.LocalAddr(), "dst", broker.conn.RemoteAddr())
-	api := ApiKey(binary.BigEndian.Uint16(payload[4:]))
-	apiVersion := binary.BigEndian.Uint16(payload[6:])
-	correlationID := binary.BigEndian.Uint32(payload[8:])
-	logger.V(5).Info("request info", "length", len(payload), "api", api, "apiVersion", apiVersion, "correlationID", correlationID, "timeout", timeout)
-
-	io.Copy(broker.conn, bytes.NewBuffer(payload))
-
-	responseLengthBuf := make([]byte, 4)
-	// if timeout > 0 {
-	// 	broker.conn.SetReadDeadline(time.Now().Add(time.Duration(timeout) * time.Millisecond))
-	// }
-	n, err := broker.conn.Read(responseLengthBuf)
+// requestBuffered sends the payload and returns the response without the 4 bytes length.
+// the whole response is read into memory by the reader goroutine of the pipeline (up to fetch.max.bytes for fetch),
+// so other requests could be in flight during a long fetch
+func (broker *Broker) requestBuffered(payload []byte, timeout int) (r io.Reader, responseLength uint32, err error) {
+	broker.mux.Lock()
+	rp, err := broker.request(payload, timeout)
+	broker.mux.Unlock()
 	if err != nil {
-		return nil, responseLength, err
+		return nil, 0, err
 	}
-	if n != 4 {
-		return nil, responseLength, errShortRead
+
+	data, err := rp.Read()
+	if err != nil {
+		broker.closePipeline(rp.pipeline, err)
+		return nil, 0, err
 	}
 
-	responseLength = binary.BigEndian.Uint32(responseLengthBuf)
+	responseLength = uint32(len(data) - 4)
 	logger.V(5).Info("got responseLength", "responseLength", responseLength)
-
-	reader := &io.LimitedReader{
-		R: broker.conn,
-		N: int64(responseLength),
-	}
-	return reader, responseLength, nil
+	return bytes.NewReader(data[4:]), responseLength, nil
 }
 
-// used in broker init and reopen after close.
-// not use RequeAndResponse to avoid dead lock
-func (broker *Broker) requestAPIVersions(clientID string) (r APIVersionsResponse, err error) {
-	apiVersionRequest := NewApiVersionsRequest(clientID)
-	rp, err := broker.Request(apiVersionRequest)
+// requestAndParse sends the request and parses the response without broker.mux.
+// used in broker init and reopen after close, not use RequestAndGet to avoid dead lock
+func (broker *Broker) requestAndParse(r Request) (Response, error) {
+	rp, err := broker.Request(r)
 	if err != nil {
-		return r, err
+		return nil, err
 	}
 
 	resp, err := rp.ReadAndParse()
 	if err != nil {
-		return r, err
+		return nil, err
 	}
 	if err = resp.Error(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// used in broker init and reopen after close.
+// not use RequeAndResponse to avoid dead lock
+func (broker *Broker) requestAPIVersions(clientID string) (r APIVersionsResponse, err error) {
+	apiVersionRequest := NewApiVersionsRequest(clientID)
+	resp, err := broker.requestAndParse(apiVersionRequest)
+	if err != nil {
 		return r, err
 	}
 	return resp.(APIVersionsResponse), nil
@@ -367,29 +442,31 @@ func (broker *Broker) requestOffsets(clientID, topic string, partitionIDs []int3
 	return r, err
 }
 
-func (broker *Broker) requestFetchStreamingly(fetchRequest *FetchRequest) (r io.Reader, responseLength uint32, err error) {
+// requestFetch sends the fetch request and returns the buffered response, see requestBuffered
+func (broker *Broker) requestFetch(fetchRequest *FetchRequest) (r io.Reader, responseLength uint32, err error) {
+	broker.mux.Lock()
 	if err := broker.ensureOpen(); err != nil {
+		broker.mux.Unlock()
 		return nil, 0, err
 	}
 
-	broker.mux.Lock()
-	defer broker.mux.Unlock()
-
 	broker.correlationID++
 
 	fetchRequest.SetCorrelationID(broker.correlationID)
 	payload := fetchRequest.Encode(broker.getHighestAvailableAPIVersion(API_FetchRequest))
+	broker.mux.Unlock()
 
 	timeout := broker.config.Net.TimeoutMS
 	if len(broker.config.Net.TimeoutMSForEachAPI) > int(fetchRequest.API()) {
 		timeout = broker.config.Net.TimeoutMSForEachAPI[fetchRequest.API()]
 	}
 
-	return broker.requestStreamingly(payload, timeout)
+	return broker.requestBuffered(payload, timeout)
 }
 
-func (broker *Broker) findCoordinator(clientID, groupID string) (r FindCoordinatorResponse, err error) {
-	request := NewFindCoordinatorRequest(clientID, groupID)
+func (broker *Broker) findCoordinator(clientID, key string, keyType int8) (r FindCoordinatorResponse, err error) {
+	request := NewFindCoordinatorRequest(clientID, key)
+	request.KeyType = keyType
 
 	resp, err := broker.RequestAndGet(request)
 	if v, ok := resp.(FindCoordinatorResponse); ok {
@@ -398,14 +475,14 @@ func (broker *Broker) findCoordinator(clientID, groupID string) (r FindCoordinat
 	return r, err
 }
 
-func (broker *Broker) requestJoinGroup(clientID, groupID string, sessionTimeoutMS int32, memberID, protocolType string, gps []*GroupProtocol) (r JoinGroupResponse, err error) {
+func (broker *Broker) requestJoinGroup(clientID, groupID string, sessionTimeoutMS int32, memberID string, groupInstanceID *string, protocolType string, gps []*GroupProtocol) (r JoinGroupResponse, err error) {
 	joinGroupRequest := NewJoinGroupRequest(1, clientID)
 	joinGroupRequest.GroupID = groupID
 	joinGroupRequest.SessionTimeout = sessionTimeoutMS
 	joinGroupRequest.RebalanceTimeout = 60000
 	joinGroupRequest.MemberID = memberID
+	joinGroupRequest.GroupInstanceID = groupInstanceID
 	joinGroupRequest.ProtocolType = protocolType
-	joinGroupRequest.AddGroupProtocal(&GroupProtocol{"range", []byte{}})
 	joinGroupRequest.GroupProtocols = gps
 
 	resp, err := broker.RequestAndGet(joinGroupRequest)
@@ -415,8 +492,9 @@ func (broker *Broker) requestJoinGroup(clientID, groupID string, sessionTimeoutM
 	return r, err
 }
 
-func (broker *Broker) requestSyncGroup(clientID, groupID string, generationID int32, memberID string, groupAssignment GroupAssignment) (r SyncGroupResponse, err error) {
+func (broker *Broker) requestSyncGroup(clientID, groupID string, generationID int32, memberID string, groupInstanceID *string, groupAssignment GroupAssignment) (r SyncGroupResponse, err error) {
 	syncGroupRequest := NewSyncGroupRequest(clientID, groupID, generationID, memberID, groupAssignment)
+	syncGroupRequest.GroupInstanceID = groupInstanceID
 
 	resp, err := broker.RequestAndGet(syncGroupRequest)
 	if v, ok := resp.(SyncGroupResponse); ok {
@@ -425,8 +503,9 @@ func (broker *Broker) requestSyncGroup(clientID, groupID string, generationID in
 	return r, err
 }
 
-func (broker *Broker) requestHeartbeat(clientID, groupID string, generationID int32, memberID string) (r HeartbeatResponse, err error) {
+func (broker *Broker) requestHeartbeat(clientID, groupID string, generationID int32, memberID string, groupInstanceID *string) (r HeartbeatResponse, err error) {
 	req := NewHeartbeatRequest(clientID, groupID, generationID, memberID)
+	req.GroupInstanceID = groupInstanceID
 
 	resp, err := broker.RequestAndGet(req)
 	if v, ok := resp.(HeartbeatResponse); ok {
@@ -0,0 +1,182 @@
+package healer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultMaxInFlightRequestsPerConnection is used if max.in.flight.requests.per.connection is not set
+const defaultMaxInFlightRequestsPerConnection = 5
+
+var errCorrelationIDMismatch = errors.New("correlation id of response does not match the request")
+
+// inFlightRequest is a request written by the writer goroutine, it waits for the response from the reader goroutine
+type inFlightRequest struct {
+	correlationID uint32
+	payload       []byte
+	timeout       int
+	response      chan []byte
+}
+
+// pipeline writes requests and reads responses of one connection in two goroutines, so that requests do not wait for the responses of others.
+// Kafka sends responses in the order of requests on a connection, so the reader goroutine takes the earliest in-flight request
+// and checks the correlation id of the response against it.
+// The connection is closed if any error occurs, and all the requests waiting for responses get the error.
+type pipeline struct {
+	conn net.Conn
+
+	// requests to be written by the writer goroutine
+	writes chan *inFlightRequest
+	// requests written and waiting for responses, the capacity is max.in.flight.requests.per.connection
+	inFlight chan *inFlightRequest
+
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+func newPipeline(conn net.Conn, maxInFlight int) *pipeline {
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlightRequestsPerConnection
+	}
+	p := &pipeline{
+		conn:     conn,
+		writes:   make(chan *inFlightRequest),
+		inFlight: make(chan *inFlightRequest, maxInFlight),
+		done:     make(chan struct{}),
+	}
+	go p.writeLoop()
+	go p.readLoop()
+	return p
+}
+
+// send puts the request to the write queue, it blocks if max.in.flight.requests.per.connection requests are waiting for responses
+func (p *pipeline) send(correlationID uint32, payload []byte, timeout int) (*inFlightRequest, error) {
+	req := &inFlightRequest{
+		correlationID: correlationID,
+		payload:       payload,
+		timeout:       timeout,
+		response:      make(chan []byte, 1),
+	}
+	select {
+	case p.writes <- req:
+		return req, nil
+	case <-p.done:
+		return nil, p.err
+	}
+}
+
+// wait returns the whole response of the request, including the 4 bytes length
+func (p *pipeline) wait(req *inFlightRequest) ([]byte, error) {
+	select {
+	case data := <-req.response:
+		return data, nil
+	case <-p.done:
+		// the response may be got just before the pipeline is closed
+		select {
+		case data := <-req.response:
+			return data, nil
+		default:
+			return nil, p.err
+		}
+	}
+}
+
+// close closes the connection, err is returned to all the requests waiting for responses
+func (p *pipeline) close(err error) {
+	p.closeOnce.Do(func() {
+		p.err = err
+		close(p.done)
+		p.conn.Close()
+	})
+}
+
+func (p *pipeline) closed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *pipeline) writeLoop() {
+	for {
+		var req *inFlightRequest
+		select {
+		case req = <-p.writes:
+		case <-p.done:
+			return
+		}
+
+		// put it in flight before writing, or else the response may come before the reader knows the request
+		select {
+		case p.inFlight <- req:
+		case <-p.done:
+			return
+		}
+
+		logger.V(5).Info("request info", "length", len(req.payload), "api", ApiKey(binary.BigEndian.Uint16(req.payload[4:])), "apiVersion", binary.BigEndian.Uint16(req.payload[6:]), "correlationID", req.correlationID, "timeout", req.timeout)
+		if req.timeout > 0 {
+			p.conn.SetWriteDeadline(time.Now().Add(time.Duration(req.timeout) * time.Millisecond))
+		} else {
+			p.conn.SetWriteDeadline(time.Time{})
+		}
+		if _, err := p.conn.Write(req.payload); err != nil {
+			p.close(err)
+			return
+		}
+	}
+}
+
+func (p *pipeline) readLoop() {
+	for {
+		var req *inFlightRequest
+		select {
+		case req = <-p.inFlight:
+		case <-p.done:
+			return
+		}
+
+		if req.timeout > 0 {
+			p.conn.SetReadDeadline(time.Now().Add(time.Duration(req.timeout) * time.Millisecond))
+		} else {
+			p.conn.SetReadDeadline(time.Time{})
+		}
+		data, err := readResponse(p.conn)
+		if err != nil {
+			p.close(err)
+			return
+		}
+		if correlationID := binary.BigEndian.Uint32(data[4:]); correlationID != req.correlationID {
+			p.close(fmt.Errorf("%w: %d != %d", errCorrelationIDMismatch, correlationID, req.correlationID))
+			return
+		}
+		req.response <- data
+	}
+}
+
+// readResponse reads a whole response from the connection. it firstly reads length of the response, then reads the whole response
+func readResponse(conn net.Conn) ([]byte, error) {
+	responseLengthBuf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, responseLengthBuf); err != nil {
+		return nil, err
+	}
+	responseLength := int(binary.BigEndian.Uint32(responseLengthBuf))
+	// response has correlation id at least
+	if responseLength < 4 {
+		return nil, errShortRead
+	}
+
+	resp := make([]byte, 4+responseLength)
+	copy(resp, responseLengthBuf)
+	if _, err := io.ReadFull(conn, resp[4:]); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
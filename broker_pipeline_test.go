@@ -0,0 +1,95 @@
+package healer
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func newMockRequestPayload(correlationID uint32) []byte {
+	payload := make([]byte, 12)
+	binary.BigEndian.PutUint32(payload, 8)
+	binary.BigEndian.PutUint16(payload[4:], API_MetadataRequest)
+	binary.BigEndian.PutUint32(payload[8:], correlationID)
+	return payload
+}
+
+func TestPipelineInFlight(t *testing.T) {
+	convey.Convey("requests are in flight at the same time, and every request gets its own response", t, func() {
+		count := 3
+
+		// mock broker does not respond until it receives all the requests
+		conn, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			correlationIDs := make([]uint32, 0, count)
+			for i := 0; i < count; i++ {
+				request, err := readResponse(server)
+				if err != nil {
+					return
+				}
+				correlationIDs = append(correlationIDs, binary.BigEndian.Uint32(request[8:]))
+			}
+			for _, correlationID := range correlationIDs {
+				response := make([]byte, 8)
+				binary.BigEndian.PutUint32(response, 4)
+				binary.BigEndian.PutUint32(response[4:], correlationID)
+				if _, err := server.Write(response); err != nil {
+					return
+				}
+			}
+		}()
+
+		p := newPipeline(conn, count)
+		defer p.close(errBrokerClosed)
+
+		wg := sync.WaitGroup{}
+		wg.Add(count)
+		results := make([]uint32, count)
+		errs := make([]error, count)
+		for i := 0; i < count; i++ {
+			go func(i int) {
+				defer wg.Done()
+				req, err := p.send(uint32(i+1), newMockRequestPayload(uint32(i+1)), 1000)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				data, err := p.wait(req)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				results[i] = binary.BigEndian.Uint32(data[4:])
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < count; i++ {
+			convey.So(errs[i], convey.ShouldBeNil)
+			convey.So(results[i], convey.ShouldEqual, i+1)
+		}
+	})
+}
+
+func TestPipelineCorrelationIDMismatch(t *testing.T) {
+	convey.Convey("pipeline is closed if the correlation id of the response does not match", t, func() {
+		conn := newMockServerConn(func(correlationID uint32) uint32 {
+			return correlationID + 1
+		})
+		p := newPipeline(conn, 1)
+
+		req, err := p.send(1, newMockRequestPayload(1), 1000)
+		convey.So(err, convey.ShouldBeNil)
+		_, err = p.wait(req)
+		convey.So(errors.Is(err, errCorrelationIDMismatch), convey.ShouldBeTrue)
+		convey.So(p.closed(), convey.ShouldBeTrue)
+
+		_, err = p.send(2, newMockRequestPayload(2), 1000)
+		convey.So(errors.Is(err, errCorrelationIDMismatch), convey.ShouldBeTrue)
+	})
+}
@@ -1,6 +1,7 @@
 package healer
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 	"net"
@@ -11,6 +12,32 @@ import (
 	"github.com/smartystreets/goconvey/convey"
 )
 
+// newMockServerConn returns a conn to a mock broker, which responds each request with a response only having the correlation id.
+// respond is called before writing each response if it is not nil
+func newMockServerConn(respond func(correlationID uint32) uint32) net.Conn {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		for {
+			request, err := readResponse(server) // request has the same length prefix as response
+			if err != nil {
+				return
+			}
+			correlationID := binary.BigEndian.Uint32(request[8:])
+			if respond != nil {
+				correlationID = respond(correlationID)
+			}
+			response := make([]byte, 8)
+			binary.BigEndian.PutUint32(response, 4)
+			binary.BigEndian.PutUint32(response[4:], correlationID)
+			if _, err := server.Write(response); err != nil {
+				return
+			}
+		}
+	}()
+	return client
+}
+
 func newMockBroker() *Broker {
 	return &Broker{
 		address: "localhost:9092",
@@ -70,10 +97,12 @@ func TestGetHighestAvailableAPIVersion(t *testing.T) {
 func TestReopenConn(t *testing.T) {
 	mockey.PatchConvey("conn EOF and reopen new conn", t, func() {
 		mockey.Mock(newAPIVersionsResponse).Return(APIVersionsResponse{}, nil).Build()
-		mockey.Mock((*net.Dialer).Dial).Return(&MockConn{}, nil).Build()
+		mockey.Mock((*net.Dialer).Dial).To(func(d *net.Dialer, network, address string) (net.Conn, error) {
+			return newMockServerConn(nil), nil
+		}).Build()
 		mockey.Mock(NewMetadataResponse).Return(MetadataResponse{}, nil).Build()
-		brokerCloseOrigin := (*Broker).Close
-		brokerClose := mockey.Mock((*Broker).Close).To(func(broker *Broker) { (brokerCloseOrigin)(broker) }).Origin(&brokerCloseOrigin).Build()
+		brokerCloseOrigin := (*Broker).close
+		brokerClose := mockey.Mock((*Broker).close).To(func(broker *Broker) { (brokerCloseOrigin)(broker) }).Origin(&brokerCloseOrigin).Build()
 		ensureOpenOrigin := (*Broker).ensureOpen
 		ensureOpen := mockey.Mock((*Broker).ensureOpen).To(func(broker *Broker) error { return (ensureOpenOrigin)(broker) }).Origin(&ensureOpenOrigin).Build()
 		createConnOrigin := (*Broker).createConn
@@ -99,7 +128,9 @@ func TestReopenConn(t *testing.T) {
 		req := NewMetadataRequest("healer-unittest", []string{"test-topic"})
 		_, err = broker.RequestAndGet(req) // EOF
 		convey.So(errors.Is(err, io.EOF), convey.ShouldBeTrue)
-		convey.So(brokerClose.Times(), convey.ShouldEqual, 1)
+		// the pipeline is marked failed, conn is closed and reopened in the next request
+		convey.So(broker.pipeline.closed(), convey.ShouldBeTrue)
+		convey.So(brokerClose.Times(), convey.ShouldEqual, 0)
 		convey.So(ensureOpen.Times(), convey.ShouldEqual, 1)
 		convey.So(createConn.Times(), convey.ShouldEqual, 1)
 
@@ -115,7 +146,9 @@ func TestReopenConn(t *testing.T) {
 func TestRequestLock(t *testing.T) {
 	mockey.PatchConvey("ONE broker do Request in multi goroutines in the same time. One closes because of EOF, others should reopen and then complete Request, no nil point panic", t, func() {
 		mockey.Mock(newAPIVersionsResponse).Return(APIVersionsResponse{}, nil).Build()
-		mockey.Mock((*net.Dialer).Dial).Return(&MockConn{}, nil).Build()
+		mockey.Mock((*net.Dialer).Dial).To(func(d *net.Dialer, network, address string) (net.Conn, error) {
+			return newMockServerConn(nil), nil
+		}).Build()
 		mockey.Mock(NewMetadataResponse).Return(MetadataResponse{}, nil).Build()
 
 		broker, err := NewBroker("127.0.0.1:9092", 0, DefaultBrokerConfig())
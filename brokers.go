@@ -312,6 +312,15 @@ func (brokers *Brokers) findLeader(clientID, topic string, partitionID int32) (i
 
 // FindCoordinator try to requests FindCoordinator from all brokers and returns response
 func (brokers *Brokers) FindCoordinator(clientID, groupID string) (r FindCoordinatorResponse, err error) {
+	return brokers.findCoordinator(clientID, groupID, CoordinatorTypeGroup)
+}
+
+// FindTransactionCoordinator try to requests FindCoordinator(key_type=transaction) from all brokers and returns response
+func (brokers *Brokers) FindTransactionCoordinator(clientID, transactionalID string) (r FindCoordinatorResponse, err error) {
+	return brokers.findCoordinator(clientID, transactionalID, CoordinatorTypeTransaction)
+}
+
+func (brokers *Brokers) findCoordinator(clientID, key string, keyType int8) (r FindCoordinatorResponse, err error) {
 	var broker *Broker
 	for _, brokerInfo := range brokers.brokersInfo {
 		broker, err = brokers.GetBroker(brokerInfo.NodeID)
@@ -319,7 +328,7 @@ func (brokers *Brokers) FindCoordinator(clientID, groupID string) (r FindCoordin
 			logger.Error(err, "get broker failed", "nodeId", brokerInfo.NodeID)
 			continue
 		}
-		r, err = broker.findCoordinator(clientID, groupID)
+		r, err = broker.findCoordinator(clientID, key, keyType)
 		if err != nil {
 			logger.Error(err, "could not find coordinator", "coordinator", broker.address)
 		} else {
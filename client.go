@@ -17,12 +17,17 @@ type Client struct {
 
 // NewClient creates a new Client
 func NewClient(bs, clientID string) (*Client, error) {
+	return NewClientWithConfig(bs, clientID, DefaultBrokerConfig())
+}
+
+// NewClientWithConfig creates a new Client with the broker config, such as sasl and tls
+func NewClientWithConfig(bs, clientID string, config *BrokerConfig) (*Client, error) {
 	var err error
 	client := &Client{
 		clientID: clientID,
 		logger:   GetLogger().WithName(clientID),
 	}
-	client.brokers, err = NewBrokers(bs)
+	client.brokers, err = NewBrokersWithConfig(bs, config)
 	return client, err
 }
 
@@ -250,3 +255,234 @@ func (c *Client) DescribeConfigs(resourceType, resourceName string, keys []strin
 	}
 	return resp.(DescribeConfigsResponse), nil
 }
+
+// the time to wait for the deletion of records to be replicated
+const deleteRecordsTimeoutMS = 30000
+
+// DeleteRecordsResult is the result of deleting records of one partition. LowWatermark is the new log start offset if Err is nil.
+// Err is the error of the partition, or the error of the request to its leader
+type DeleteRecordsResult struct {
+	LowWatermark int64
+	Err          error
+}
+
+// partitionLeader returns the leader of the partition from metadata, error is returned if the partition is unknown or has no leader
+func partitionLeader(meta MetadataResponse, topic string, partition int32) (int32, error) {
+	for _, t := range meta.TopicMetadatas {
+		if t.TopicName != topic {
+			continue
+		}
+		if t.TopicErrorCode != 0 {
+			return -1, KafkaError(t.TopicErrorCode)
+		}
+		for _, p := range t.PartitionMetadatas {
+			if p.PartitionID != partition {
+				continue
+			}
+			if p.PartitionErrorCode != 0 {
+				return -1, KafkaError(p.PartitionErrorCode)
+			}
+			if p.Leader == -1 {
+				return -1, KafkaError(5) // LEADER_NOT_AVAILABLE
+			}
+			return p.Leader, nil
+		}
+	}
+	return -1, KafkaError(3) // UNKNOWN_TOPIC_OR_PARTITION
+}
+
+// DeleteRecords deletes the records before the offsets, offsets is topic -> partition -> offset, offset -1 means the high watermark.
+// Leaders of all the partitions are resolved before any request is sent, nothing is deleted if any of them could not be found.
+// Then requests are sent to all the leaders, and the result of each partition is returned. err is not nil if any partition fails
+func (c *Client) DeleteRecords(offsets map[string]map[int32]int64) (map[string]map[int32]DeleteRecordsResult, error) {
+	c.logger.Info("delete records", "offsets", offsets)
+
+	topics := make([]string, 0, len(offsets))
+	for topic := range offsets {
+		topics = append(topics, topic)
+	}
+	meta, err := c.brokers.RequestMetaData(c.clientID, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	rst := make(map[string]map[int32]DeleteRecordsResult)
+	failed := 0
+	setResult := func(topic string, partition int32, r DeleteRecordsResult) {
+		if _, ok := rst[topic]; !ok {
+			rst[topic] = make(map[int32]DeleteRecordsResult)
+		}
+		rst[topic][partition] = r
+		if r.Err != nil {
+			failed++
+		}
+	}
+	// setRequestError sets err to all the partitions in the request
+	setRequestError := func(req *DeleteRecordsRequest, err error) {
+		for _, topic := range req.Topics {
+			for _, p := range topic.Partitions {
+				setResult(topic.Name, p.PartitionIndex, DeleteRecordsResult{LowWatermark: -1, Err: err})
+			}
+		}
+	}
+
+	reqs := make(map[int32]*DeleteRecordsRequest)
+	for topic, partitions := range offsets {
+		for partition, offset := range partitions {
+			leader, err := partitionLeader(meta, topic, partition)
+			if err != nil {
+				setResult(topic, partition, DeleteRecordsResult{LowWatermark: -1, Err: fmt.Errorf("find leader of %s-%d error: %w", topic, partition, err)})
+				continue
+			}
+			if _, ok := reqs[leader]; !ok {
+				reqs[leader] = NewDeleteRecordsRequest(c.clientID, deleteRecordsTimeoutMS)
+			}
+			reqs[leader].AddPartition(topic, partition, offset)
+		}
+	}
+	leaders := make(map[int32]*Broker)
+	for leader, req := range reqs {
+		broker, err := c.brokers.GetBroker(leader)
+		if err != nil {
+			setRequestError(req, fmt.Errorf("get leader %d error: %w", leader, err))
+			continue
+		}
+		leaders[leader] = broker
+	}
+	if failed > 0 {
+		return rst, fmt.Errorf("could not find leaders of %d partitions, no records are deleted", failed)
+	}
+
+	for leader, req := range reqs {
+		resp, err := leaders[leader].RequestAndGet(req)
+		r, ok := resp.(DeleteRecordsResponse)
+		if !ok {
+			c.logger.Error(err, "delete records failed", "broker", leaders[leader].String())
+			setRequestError(req, err)
+			continue
+		}
+		for _, topic := range r.Topics {
+			for _, p := range topic.Partitions {
+				result := DeleteRecordsResult{LowWatermark: p.LowWatermark}
+				if p.ErrorCode != 0 {
+					result.Err = fmt.Errorf("delete records of %s-%d error: %w", topic.Name, p.PartitionIndex, KafkaError(p.ErrorCode))
+				}
+				setResult(topic.Name, p.PartitionIndex, result)
+			}
+		}
+	}
+	if failed > 0 {
+		return rst, fmt.Errorf("failed to delete records of %d partitions", failed)
+	}
+	return rst, nil
+}
+
+// DescribeClientQuotas describes the quotas of the entities matching all the components
+func (c *Client) DescribeClientQuotas(components []QuotaFilterComponent, strict bool) (r DescribeClientQuotasResponse, err error) {
+	req := NewDescribeClientQuotasRequest(c.clientID, components, strict)
+
+	controller, err := c.brokers.GetController()
+	if err != nil {
+		return r, err
+	}
+
+	resp, err := controller.RequestAndGet(req)
+	if err != nil {
+		return r, err
+	}
+	return resp.(DescribeClientQuotasResponse), nil
+}
+
+// AlterClientQuotas alters the quotas of the entities, the quotas are only validated if validateOnly is true
+func (c *Client) AlterClientQuotas(entries []AlterClientQuotasEntry, validateOnly bool) (r AlterClientQuotasResponse, err error) {
+	c.logger.Info("alter client quotas", "entries", entries, "validateOnly", validateOnly)
+
+	req := NewAlterClientQuotasRequest(c.clientID, entries, validateOnly)
+
+	controller, err := c.brokers.GetController()
+	if err != nil {
+		return r, err
+	}
+
+	resp, err := controller.RequestAndGet(req)
+	if resp != nil {
+		r = resp.(AlterClientQuotasResponse)
+	}
+	return r, err
+}
+
+// DescribeUserScramCredentials describes the SCRAM credentials of the users, all users are described if users is nil
+func (c *Client) DescribeUserScramCredentials(users []string) (r DescribeUserScramCredentialsResponse, err error) {
+	req := NewDescribeUserScramCredentialsRequest(c.clientID, users)
+
+	controller, err := c.brokers.GetController()
+	if err != nil {
+		return r, err
+	}
+
+	resp, err := controller.RequestAndGet(req)
+	if resp != nil {
+		r = resp.(DescribeUserScramCredentialsResponse)
+	}
+	return r, err
+}
+
+// AlterUserScramCredentials deletes and upserts SCRAM credentials. use NewScramCredentialUpsertion to salt the password
+func (c *Client) AlterUserScramCredentials(deletions []ScramCredentialDeletion, upsertions []ScramCredentialUpsertion) (r AlterUserScramCredentialsResponse, err error) {
+	users := make([]string, 0, len(deletions)+len(upsertions))
+	for _, d := range deletions {
+		users = append(users, d.Name)
+	}
+	for _, u := range upsertions {
+		users = append(users, u.Name)
+	}
+	c.logger.Info("alter user scram credentials", "users", users)
+
+	req := NewAlterUserScramCredentialsRequest(c.clientID, deletions, upsertions)
+
+	controller, err := c.brokers.GetController()
+	if err != nil {
+		return r, err
+	}
+
+	resp, err := controller.RequestAndGet(req)
+	if resp != nil {
+		r = resp.(AlterUserScramCredentialsResponse)
+	}
+	return r, err
+}
+
+// DeleteGroupOffsets deletes the committed offsets of the partitions of the topic in the group, all partitions of the topic if partitions is empty.
+// errors of the partitions, such as GROUP_SUBSCRIBED_TO_TOPIC, are in the response
+func (c *Client) DeleteGroupOffsets(group, topic string, partitions []int32) (r OffsetDeleteResponse, err error) {
+	c.logger.Info("delete group offsets", "group", group, "topic", topic, "partitions", partitions)
+
+	if len(partitions) == 0 {
+		meta, err := c.brokers.RequestMetaData(c.clientID, []string{topic})
+		if err != nil {
+			return r, err
+		}
+		for _, topicMetadata := range meta.TopicMetadatas {
+			for _, partition := range topicMetadata.PartitionMetadatas {
+				partitions = append(partitions, partition.PartitionID)
+			}
+		}
+	}
+
+	coordinatorResponse, err := c.brokers.FindCoordinator(c.clientID, group)
+	if err != nil {
+		return r, err
+	}
+	coordinator, err := c.brokers.GetBroker(coordinatorResponse.Coordinator.NodeID)
+	if err != nil {
+		return r, err
+	}
+
+	req := NewOffsetDeleteRequest(c.clientID, group)
+	req.AddPartitions(topic, partitions...)
+	resp, err := coordinator.RequestAndGet(req)
+	if resp != nil {
+		r = resp.(OffsetDeleteResponse)
+	}
+	return r, err
+}
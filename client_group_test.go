@@ -72,3 +72,41 @@ func TestListGroups(t *testing.T) {
 		convey.So(len(groups[1]), convey.ShouldEqual, 2)
 	})
 }
+
+func TestDeleteGroupOffsets(t *testing.T) {
+	brokers := &Brokers{}
+	c := &Client{
+		clientID: "test",
+		brokers:  brokers,
+	}
+	mockey.PatchConvey("TestDeleteGroupOffsets", t, func() {
+		mockey.Mock((*Brokers).RequestMetaData).Return(MetadataResponse{
+			TopicMetadatas: []TopicMetadata{
+				{
+					TopicName: "test",
+					PartitionMetadatas: []*PartitionMetadataInfo{
+						{PartitionID: 0},
+						{PartitionID: 1},
+					},
+				},
+			},
+		}, nil).Build()
+		mockey.Mock((*Brokers).FindCoordinator).Return(FindCoordinatorResponse{Coordinator: Coordinator{NodeID: 1}}, nil).Build()
+		mockey.Mock((*Brokers).GetBroker).Return(&Broker{}, nil).Build()
+
+		var request *OffsetDeleteRequest
+		mockey.Mock((*Broker).RequestAndGet).To(func(b *Broker, req Request) (Response, error) {
+			request = req.(*OffsetDeleteRequest)
+			return OffsetDeleteResponse{}, nil
+		}).Build()
+
+		_, err := c.DeleteGroupOffsets("test-group", "test", nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(request.GroupID, convey.ShouldEqual, "test-group")
+		convey.So(request.Topics, convey.ShouldResemble, []OffsetDeleteTopic{{Name: "test", Partitions: []int32{0, 1}}})
+
+		_, err = c.DeleteGroupOffsets("test-group", "test", []int32{1})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(request.Topics, convey.ShouldResemble, []OffsetDeleteTopic{{Name: "test", Partitions: []int32{1}}})
+	})
+}
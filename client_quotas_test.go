@@ -0,0 +1,84 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestDescribeClientQuotasEncodeDecode(t *testing.T) {
+	convey.Convey("Test DescribeClientQuotas Request and Response Encode and Decode", t, func() {
+		alice := "alice"
+		version := uint16(0)
+
+		request := NewDescribeClientQuotasRequest("healer", []QuotaFilterComponent{
+			{EntityType: QuotaEntityUser, MatchType: QuotaMatchExact, Match: &alice},
+			{EntityType: QuotaEntityClientID, MatchType: QuotaMatchDefault},
+		}, true)
+		decodedRequest, err := DecodeDescribeClientQuotasRequest(request.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+		response := DescribeClientQuotasResponse{
+			CorrelationID:  1,
+			ThrottleTimeMS: 10,
+			Entries: []DescribeClientQuotasEntry{
+				{
+					Entity: []QuotaEntity{{EntityType: QuotaEntityUser, EntityName: &alice}, {EntityType: QuotaEntityClientID}},
+					Values: map[string]float64{"producer_byte_rate": 1024, "consumer_byte_rate": 2048.5},
+				},
+			},
+		}
+		decodedResponse, err := NewDescribeClientQuotasResponse(response.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(decodedResponse, convey.ShouldResemble, response)
+		convey.So(decodedResponse.Error(), convey.ShouldBeNil)
+
+		message := "invalid entity"
+		response = DescribeClientQuotasResponse{CorrelationID: 2, ErrorCode: 42, ErrorMessage: &message}
+		decodedResponse, err = NewDescribeClientQuotasResponse(response.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(decodedResponse, convey.ShouldResemble, response)
+		convey.So(errors.Is(decodedResponse.Error(), KafkaError(42)), convey.ShouldBeTrue)
+	})
+}
+
+func TestAlterClientQuotasEncodeDecode(t *testing.T) {
+	convey.Convey("Test AlterClientQuotas Request and Response Encode and Decode", t, func() {
+		alice := "alice"
+		version := uint16(0)
+
+		request := NewAlterClientQuotasRequest("healer", []AlterClientQuotasEntry{
+			{
+				Entity: []QuotaEntity{{EntityType: QuotaEntityUser, EntityName: &alice}},
+				Ops: []QuotaOp{
+					{Key: "producer_byte_rate", Value: 1024},
+					{Key: "consumer_byte_rate", Remove: true},
+				},
+			},
+			{
+				Entity: []QuotaEntity{{EntityType: QuotaEntityClientID}},
+				Ops:    []QuotaOp{{Key: "request_percentage", Value: 50.5}},
+			},
+		}, true)
+		decodedRequest, err := DecodeAlterClientQuotasRequest(request.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+		message := "unknown key"
+		response := AlterClientQuotasResponse{
+			CorrelationID:  1,
+			ThrottleTimeMS: 10,
+			Entries: []AlterClientQuotasEntryResult{
+				{Entity: []QuotaEntity{{EntityType: QuotaEntityUser, EntityName: &alice}}},
+				{ErrorCode: 42, ErrorMessage: &message, Entity: []QuotaEntity{{EntityType: QuotaEntityClientID}}},
+			},
+		}
+		decodedResponse, err := NewAlterClientQuotasResponse(response.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(decodedResponse, convey.ShouldResemble, response)
+		convey.So(errors.Is(decodedResponse.Error(), KafkaError(42)), convey.ShouldBeTrue)
+		convey.So(decodedResponse.Error().Error(), convey.ShouldContainSubstring, "client-id=<default>")
+	})
+}
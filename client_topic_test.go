@@ -1,6 +1,8 @@
 package healer
 
 import (
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/bytedance/mockey"
@@ -26,3 +28,68 @@ func TestDeleteTopics(t *testing.T) {
 		convey.So(err, convey.ShouldBeNil)
 	})
 }
+
+func TestDeleteRecords(t *testing.T) {
+	brokers := &Brokers{}
+	c := &Client{
+		clientID: "test",
+		brokers:  brokers,
+	}
+	mockey.PatchConvey("TestDeleteRecords", t, func() {
+		mockey.Mock((*Brokers).RequestMetaData).Return(MetadataResponse{
+			TopicMetadatas: []TopicMetadata{
+				{
+					TopicName: "test",
+					PartitionMetadatas: []*PartitionMetadataInfo{
+						{PartitionID: 0, Leader: 1},
+						{PartitionID: 1, Leader: 2},
+						{PartitionID: 2, Leader: 1},
+						{PartitionID: 3, Leader: -1},
+					},
+				},
+			},
+		}, nil).Build()
+		mockey.Mock((*Brokers).GetBroker).To(func(nodeID int32) (*Broker, error) {
+			return &Broker{nodeID: nodeID}, nil
+		}).Build()
+
+		requests := make(map[int32]*DeleteRecordsRequest)
+		mockey.Mock((*Broker).RequestAndGet).To(func(b *Broker, req Request) (Response, error) {
+			r := req.(*DeleteRecordsRequest)
+			requests[b.nodeID] = r
+			if b.nodeID == 2 && r.Topics[0].Partitions[0].Offset == 40 {
+				return nil, io.EOF
+			}
+			resp := DeleteRecordsResponse{}
+			for _, topic := range r.Topics {
+				result := DeleteRecordsTopicResult{Name: topic.Name}
+				for _, p := range topic.Partitions {
+					result.Partitions = append(result.Partitions, DeleteRecordsPartitionResult{PartitionIndex: p.PartitionIndex, LowWatermark: p.Offset})
+				}
+				resp.Topics = append(resp.Topics, result)
+			}
+			return resp, nil
+		}).Build()
+
+		results, err := c.DeleteRecords(map[string]map[int32]int64{"test": {0: 10, 1: 20, 2: 30}})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(len(results["test"]), convey.ShouldEqual, 3)
+		convey.So(len(requests[1].Topics[0].Partitions), convey.ShouldEqual, 2)
+		convey.So(requests[2].Topics[0].Partitions, convey.ShouldResemble, []DeleteRecordsPartition{{PartitionIndex: 1, Offset: 20}})
+		convey.So(results["test"][1], convey.ShouldResemble, DeleteRecordsResult{LowWatermark: 20})
+
+		// nothing is sent if any partition is unknown or has no leader
+		requests = make(map[int32]*DeleteRecordsRequest)
+		results, err = c.DeleteRecords(map[string]map[int32]int64{"test": {0: 10, 3: 10, 4: 10}})
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(len(requests), convey.ShouldEqual, 0)
+		convey.So(errors.Is(results["test"][3].Err, KafkaError(5)), convey.ShouldBeTrue)
+		convey.So(errors.Is(results["test"][4].Err, KafkaError(3)), convey.ShouldBeTrue)
+
+		// the other leaders are requested if one of them fails
+		results, err = c.DeleteRecords(map[string]map[int32]int64{"test": {0: 10, 1: 40}})
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(results["test"][0], convey.ShouldResemble, DeleteRecordsResult{LowWatermark: 10})
+		convey.So(errors.Is(results["test"][1].Err, io.EOF), convey.ShouldBeTrue)
+	})
+}
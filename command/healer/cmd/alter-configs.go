@@ -39,7 +39,7 @@ var alterConfigsCmd = &cobra.Command{
 			return err
 		}
 
-		bs, err := healer.NewBrokers(brokers)
+		bs, err := newBrokers(cmd, brokers)
 		if err != nil {
 			return fmt.Errorf("failed to create brokers from %s", brokers)
 		}
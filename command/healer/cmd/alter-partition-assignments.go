@@ -37,7 +37,10 @@ var alterPartitionReassignmentsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		config := healer.DefaultBrokerConfig()
+		config, err := getBrokerConfig(cmd)
+		if err != nil {
+			return err
+		}
 		config.Net.TimeoutMSForEachAPI = make([]int, 68)
 		config.Net.TimeoutMSForEachAPI[healer.API_AlterPartitionReassignments] = int(timeoutMS)
 		bs, err := healer.NewBrokersWithConfig(brokers, config)
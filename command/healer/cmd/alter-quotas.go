@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/childe/healer"
+	"github.com/spf13/cobra"
+)
+
+var alterQuotasCmd = &cobra.Command{
+	Use:   "alter-quotas",
+	Short: "alter client quotas of an entity",
+	Example: `healer alter-quotas --user alice --client-id app --add producer_byte_rate=1048576,consumer_byte_rate=2097152
+healer alter-quotas --user-default --delete request_percentage`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		add, err := cmd.Flags().GetStringSlice("add")
+		if err != nil {
+			return err
+		}
+		del, err := cmd.Flags().GetStringSlice("delete")
+		if err != nil {
+			return err
+		}
+		validateOnly, err := cmd.Flags().GetBool("validate-only")
+		if err != nil {
+			return err
+		}
+		entity, err := getQuotaEntity(cmd)
+		if err != nil {
+			return err
+		}
+		if len(entity) == 0 {
+			return errors.New("at least one of --user, --client-id, --ip or their defaults must be set")
+		}
+
+		ops := make([]healer.QuotaOp, 0, len(add)+len(del))
+		for _, kv := range add {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("invalid quota %s, it should be key=value", kv)
+			}
+			value, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid quota value of %s: %w", k, err)
+			}
+			ops = append(ops, healer.QuotaOp{Key: k, Value: value})
+		}
+		for _, k := range del {
+			ops = append(ops, healer.QuotaOp{Key: k, Remove: true})
+		}
+		if len(ops) == 0 {
+			return errors.New("one of --add and --delete must be set")
+		}
+
+		admin, err := newClient(cmd, brokers, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		resp, err := admin.AlterClientQuotas([]healer.AlterClientQuotasEntry{{Entity: entity, Ops: ops}}, validateOnly)
+		if err != nil {
+			return err
+		}
+
+		s, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(s))
+
+		return nil
+	},
+}
+
+func init() {
+	addQuotaEntityFlags(alterQuotasCmd.Flags())
+	alterQuotasCmd.Flags().StringSlice("add", nil, "quotas to set, key=value separated by comma")
+	alterQuotasCmd.Flags().StringSlice("delete", nil, "quota keys to remove, separated by comma")
+	alterQuotasCmd.Flags().Bool("validate-only", false, "only validate the quotas, do not alter them")
+
+	rootCmd.AddCommand(alterQuotasCmd)
+}
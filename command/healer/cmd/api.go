@@ -28,6 +28,15 @@ var apiCmd = &cobra.Command{
 		if len(client) == 0 {
 			client = "healer"
 		}
+		brokerConfig, err := getBrokerConfig(cmd)
+		if err != nil {
+			return err
+		}
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		apicontrollers.SetBrokerConfig(brokers, brokerConfig)
 
 		fullAddress := net.JoinHostPort(address, strconv.Itoa(int(port)))
 		router := gin.Default()
@@ -87,6 +96,13 @@ var apiCmd = &cobra.Command{
 		router.POST("/create-acls", wrap(apicontrollers.CreateAcls, client))
 		router.DELETE("/delete-acls", wrap(apicontrollers.DeleteAcls, client))
 
+		router.POST("/describe-quotas", wrap(apicontrollers.DescribeQuotas, client))
+		router.POST("/alter-quotas", wrap(apicontrollers.AlterQuotas, client))
+
+		router.POST("/describe-users", wrap(apicontrollers.DescribeUsers, client))
+		router.POST("/upsert-user", wrap(apicontrollers.UpsertUser, client))
+		router.DELETE("/delete-user", wrap(apicontrollers.DeleteUser, client))
+
 		router.Run(fullAddress)
 		return nil
 	},
@@ -15,8 +15,8 @@ func DescribeAcls(c *gin.Context, clientID string) {
 		return
 	}
 
-	bootstrapServers := c.Query("bootstrap")
-	client, err := healer.NewClient(bootstrapServers, clientID)
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -39,8 +39,8 @@ func DeleteAcls(c *gin.Context, clientID string) {
 		return
 	}
 
-	bootstrapServers := c.Query("bootstrap")
-	client, err := healer.NewClient(bootstrapServers, clientID)
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -63,8 +63,8 @@ func CreateAcls(c *gin.Context, clientID string) {
 		return
 	}
 
-	bootstrapServers := c.Query("bootstrap")
-	client, err := healer.NewClient(bootstrapServers, clientID)
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,38 @@
+package apicontrollers
+
+import (
+	"github.com/childe/healer"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// brokerConfig is shared by all the controllers, the api command sets it from the cluster profile and the global flags
+	brokerConfig = healer.DefaultBrokerConfig()
+	// defaultBootstrapServers is used if bootstrap is not set in the query
+	defaultBootstrapServers string
+)
+
+// SetBrokerConfig sets the default bootstrap servers and the broker config (such as tls and sasl) used to connect to kafka
+func SetBrokerConfig(bootstrapServers string, config *healer.BrokerConfig) {
+	defaultBootstrapServers = bootstrapServers
+	brokerConfig = config
+}
+
+// getBootstrapServers returns bootstrap in the query, or the default bootstrap servers
+func getBootstrapServers(c *gin.Context) string {
+	return c.DefaultQuery("bootstrap", defaultBootstrapServers)
+}
+
+// newBrokerConfig returns a copy of the shared broker config, so that the controller could change it, timeouts for example
+func newBrokerConfig() *healer.BrokerConfig {
+	config := *brokerConfig
+	return &config
+}
+
+func newBrokers(bootstrapServers string) (*healer.Brokers, error) {
+	return healer.NewBrokersWithConfig(bootstrapServers, newBrokerConfig())
+}
+
+func newClient(bootstrapServers, clientID string) (*healer.Client, error) {
+	return healer.NewClientWithConfig(bootstrapServers, clientID, newBrokerConfig())
+}
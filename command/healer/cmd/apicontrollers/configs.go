@@ -3,13 +3,12 @@ package apicontrollers
 import (
 	"net/http"
 
-	"github.com/childe/healer"
 	"github.com/gin-gonic/gin"
 )
 
 func GetConfigs(c *gin.Context, resourceType, clientID string) {
-	bootstrapServers := c.Query("bootstrap")
-	client, err := healer.NewClient(bootstrapServers, clientID)
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
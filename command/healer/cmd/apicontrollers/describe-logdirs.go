@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/childe/healer"
 	"github.com/gin-gonic/gin"
 )
 
 func DescribeLogdirs(c *gin.Context, client string) {
-	bootstrapServers := c.Query("bootstrap")
+	bootstrapServers := getBootstrapServers(c)
 
-	admin, err := healer.NewClient(bootstrapServers, client)
+	admin, err := newClient(bootstrapServers, client)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 	}
@@ -28,8 +28,8 @@ func ElectLeaders(c *gin.Context, client string) {
 		return
 	}
 
-	bootstrapServers := c.Query("bootstrap")
-	config := healer.DefaultBrokerConfig()
+	bootstrapServers := getBootstrapServers(c)
+	config := newBrokerConfig()
 	config.Net.TimeoutMSForEachAPI = make([]int, 68)
 	config.Net.TimeoutMSForEachAPI[healer.API_AlterPartitionReassignments] = timeoutMS
 	bs, err := healer.NewBrokersWithConfig(bootstrapServers, config)
@@ -9,9 +9,9 @@ import (
 )
 
 func ListGroups(c *gin.Context, clientID string) {
-	bootstrapServers := c.Query("bootstrap")
+	bootstrapServers := getBootstrapServers(c)
 
-	client, err := healer.NewClient(bootstrapServers, clientID)
+	client, err := newClient(bootstrapServers, clientID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -27,10 +27,10 @@ func ListGroups(c *gin.Context, clientID string) {
 }
 
 func DescribeGroups(c *gin.Context, client string) {
-	bootstrapServers := c.Query("bootstrap")
+	bootstrapServers := getBootstrapServers(c)
 	group := c.Param("group")
 
-	brokers, err := healer.NewBrokers(bootstrapServers)
+	brokers, err := newBrokers(bootstrapServers)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 	}
@@ -129,11 +129,11 @@ func getCommittedOffset(brokers *healer.Brokers, topic string, partitions []int3
 }
 
 func GetPending(c *gin.Context, client string) {
-	bootstrapServers := c.Query("bootstrap")
+	bootstrapServers := getBootstrapServers(c)
 	groupID := c.Param("group")
 	topicName := c.Param("topic")
 
-	bs, err := healer.NewBrokers(bootstrapServers)
+	bs, err := newBrokers(bootstrapServers)
 
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
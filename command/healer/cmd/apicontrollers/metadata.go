@@ -9,8 +9,8 @@ import (
 )
 
 func GetMetadata(c *gin.Context) {
-	bootstrapServers := c.Query("bootstrap")
-	bs, err := healer.NewBrokers(bootstrapServers)
+	bootstrapServers := getBootstrapServers(c)
+	bs, err := newBrokers(bootstrapServers)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -22,10 +22,10 @@ func ListPartitionReassignments(c *gin.Context, client string) {
 		return
 	}
 
-	config := healer.DefaultBrokerConfig()
+	config := newBrokerConfig()
 	config.Net.TimeoutMSForEachAPI = make([]int, 68)
 	config.Net.TimeoutMSForEachAPI[healer.API_ListPartitionReassignments] = timeoutMS
-	bootstrapServers := c.Query("bootstrap")
+	bootstrapServers := getBootstrapServers(c)
 	bs, err := healer.NewBrokersWithConfig(bootstrapServers, config)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
@@ -63,8 +63,8 @@ func AlterPartitionReassignments(c *gin.Context, client string) {
 		return
 	}
 
-	bootstrapServers := c.Query("bootstrap")
-	config := healer.DefaultBrokerConfig()
+	bootstrapServers := getBootstrapServers(c)
+	config := newBrokerConfig()
 	config.Net.TimeoutMSForEachAPI = make([]int, 68)
 	config.Net.TimeoutMSForEachAPI[healer.API_AlterPartitionReassignments] = timeoutMS
 	bs, err := healer.NewBrokersWithConfig(bootstrapServers, config)
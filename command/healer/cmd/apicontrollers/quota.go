@@ -0,0 +1,67 @@
+package apicontrollers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/childe/healer"
+	"github.com/gin-gonic/gin"
+)
+
+type describeQuotasRequest struct {
+	Components []healer.QuotaFilterComponent
+	Strict     bool
+}
+
+type alterQuotasRequest struct {
+	Entries      []healer.AlterClientQuotasEntry
+	ValidateOnly bool
+}
+
+func DescribeQuotas(c *gin.Context, clientID string) {
+	var req describeQuotasRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.String(http.StatusBadRequest, fmt.Sprintf("describe quotas request body format error: %s", err))
+		return
+	}
+
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer client.Close()
+
+	rst, err := client.DescribeClientQuotas(req.Components, req.Strict)
+
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, rst)
+}
+
+func AlterQuotas(c *gin.Context, clientID string) {
+	var req alterQuotasRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.String(http.StatusBadRequest, fmt.Sprintf("alter quotas request body format error: %s", err))
+		return
+	}
+
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer client.Close()
+
+	rst, err := client.AlterClientQuotas(req.Entries, req.ValidateOnly)
+
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, rst)
+}
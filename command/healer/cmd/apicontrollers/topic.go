@@ -11,8 +11,8 @@ import (
 )
 
 func GetTopicConfig(c *gin.Context, client string) {
-	bootstrapServers := c.Query("bootstrap")
-	bs, err := healer.NewBrokers(bootstrapServers)
+	bootstrapServers := getBootstrapServers(c)
+	bs, err := newBrokers(bootstrapServers)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -44,8 +44,8 @@ func GetTopicConfig(c *gin.Context, client string) {
 }
 
 func AlterTopicConfig(c *gin.Context, client string) {
-	bootstrapServers := c.Query("bootstrap")
-	bs, err := healer.NewBrokers(bootstrapServers)
+	bootstrapServers := getBootstrapServers(c)
+	bs, err := newBrokers(bootstrapServers)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -72,8 +72,8 @@ func AlterTopicConfig(c *gin.Context, client string) {
 }
 
 func GetTopicOffsets(c *gin.Context, client string) {
-	bootstrapServers := c.Query("bootstrap")
-	bs, err := healer.NewBrokers(bootstrapServers)
+	bootstrapServers := getBootstrapServers(c)
+	bs, err := newBrokers(bootstrapServers)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -109,8 +109,8 @@ func GetTopicOffsets(c *gin.Context, client string) {
 }
 
 func GetTopicLogDirs(c *gin.Context, clientID string) {
-	bootstrapServers := c.Query("bootstrap")
-	client, err := healer.NewClient(bootstrapServers, clientID)
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
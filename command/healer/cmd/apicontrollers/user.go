@@ -0,0 +1,118 @@
+package apicontrollers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/childe/healer"
+	"github.com/gin-gonic/gin"
+)
+
+type describeUsersRequest struct {
+	Users []string
+}
+
+type upsertUserRequest struct {
+	User       string
+	Password   string
+	Mechanism  healer.ScramMechanismType
+	Iterations int32
+}
+
+type deleteUserRequest struct {
+	User       string
+	Mechanisms []healer.ScramMechanismType
+}
+
+func DescribeUsers(c *gin.Context, clientID string) {
+	var req describeUsersRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.String(http.StatusBadRequest, fmt.Sprintf("describe users request body format error: %s", err))
+		return
+	}
+
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer client.Close()
+
+	rst, err := client.DescribeUserScramCredentials(req.Users)
+
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, rst)
+}
+
+func UpsertUser(c *gin.Context, clientID string) {
+	req := upsertUserRequest{
+		Mechanism:  healer.ScramMechanismTypeSHA512,
+		Iterations: healer.DefaultScramIterations,
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.String(http.StatusBadRequest, fmt.Sprintf("upsert user request body format error: %s", err))
+		return
+	}
+	if req.User == "" || req.Password == "" {
+		c.String(http.StatusBadRequest, "user and password must not be empty")
+		return
+	}
+
+	upsertion, err := healer.NewScramCredentialUpsertion(req.User, req.Mechanism, req.Password, req.Iterations)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer client.Close()
+
+	rst, err := client.AlterUserScramCredentials(nil, []healer.ScramCredentialUpsertion{upsertion})
+
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, rst)
+}
+
+func DeleteUser(c *gin.Context, clientID string) {
+	var req deleteUserRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.String(http.StatusBadRequest, fmt.Sprintf("delete user request body format error: %s", err))
+		return
+	}
+	if len(req.Mechanisms) == 0 {
+		req.Mechanisms = []healer.ScramMechanismType{healer.ScramMechanismTypeSHA512}
+	}
+
+	deletions := make([]healer.ScramCredentialDeletion, 0, len(req.Mechanisms))
+	for _, mechanism := range req.Mechanisms {
+		deletions = append(deletions, healer.ScramCredentialDeletion{Name: req.User, Mechanism: mechanism})
+	}
+
+	bootstrapServers := getBootstrapServers(c)
+	client, err := newClient(bootstrapServers, clientID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer client.Close()
+
+	rst, err := client.AlterUserScramCredentials(deletions, nil)
+
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, rst)
+}
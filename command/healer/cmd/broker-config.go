@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/childe/healer"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultClusterConfigFile = "~/.healer/config.yaml"
+
+// clusterProfiles is the content of the cluster config file. each cluster has the same keys as healer.BrokerConfig, and bootstrap.servers. for example:
+//
+//	clusters:
+//	  prod:
+//	    bootstrap.servers: 10.0.0.1:9093,10.0.0.2:9093
+//	    tls.enabled: true
+//	    tls:
+//	      ca: /etc/kafka/ca.pem
+//	    sasl:
+//	      mechanism: SCRAM-SHA-512
+//	      user: admin
+//	      password: admin-secret
+//	    net:
+//	      timeout.ms: 60000
+type clusterProfiles struct {
+	Clusters map[string]map[string]interface{} `yaml:"clusters"`
+}
+
+// clusterProfile is the cluster chosen by --cluster, it is nil if --cluster is not set
+var clusterProfile map[string]interface{}
+
+// loadClusterProfile loads the cluster chosen by --cluster from the config file.
+// bootstrap.servers of the cluster is used as --brokers if --brokers is not set
+func loadClusterProfile(cmd *cobra.Command) error {
+	cluster, err := cmd.Flags().GetString("cluster")
+	if err != nil {
+		return err
+	}
+	if cluster == "" {
+		return nil
+	}
+
+	path, err := cmd.Flags().GetString("cluster-config")
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read cluster config file error: %w", err)
+	}
+	var profiles clusterProfiles
+	if err := yaml.Unmarshal(content, &profiles); err != nil {
+		return fmt.Errorf("parse cluster config file %s error: %w", path, err)
+	}
+	profile, ok := profiles.Clusters[cluster]
+	if !ok {
+		return fmt.Errorf("cluster %s not found in %s", cluster, path)
+	}
+	clusterProfile = profile
+
+	if !cmd.Flags().Changed("brokers") {
+		if bootstrapServers, ok := profile["bootstrap.servers"].(string); ok {
+			return cmd.Flags().Set("brokers", bootstrapServers)
+		}
+	}
+	return nil
+}
+
+// getSaslConfig returns the sasl config from the global flags
+func getSaslConfig(cmd *cobra.Command) (healer.SaslConfig, error) {
+	var (
+		sasl healer.SaslConfig
+		err  error
+	)
+	if sasl.Mechanism, err = cmd.Flags().GetString("sasl.mechanism"); err != nil {
+		return sasl, err
+	}
+	if sasl.User, err = cmd.Flags().GetString("sasl.user"); err != nil {
+		return sasl, err
+	}
+	if sasl.Password, err = cmd.Flags().GetString("sasl.password"); err != nil {
+		return sasl, err
+	}
+	if sasl.TokenFile, err = cmd.Flags().GetString("sasl.token.file"); err != nil {
+		return sasl, err
+	}
+	if sasl.TokenEnv, err = cmd.Flags().GetString("sasl.token.env"); err != nil {
+		return sasl, err
+	}
+	return sasl, nil
+}
+
+// getBrokerConfig returns the broker config shared by all the commands.
+// it is built from the cluster profile, and the sasl flags take precedence over the sasl in the profile
+func getBrokerConfig(cmd *cobra.Command) (*healer.BrokerConfig, error) {
+	config := healer.DefaultBrokerConfig()
+	if clusterProfile != nil {
+		if err := mapstructure.WeakDecode(clusterProfile, config); err != nil {
+			return nil, fmt.Errorf("decode cluster profile error: %w", err)
+		}
+	}
+
+	sasl, err := getSaslConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if sasl.Mechanism != "" {
+		config.Sasl = sasl
+	}
+	return config, nil
+}
+
+// setBrokerConfig puts the cluster profile and the sasl flags into the consumer or producer config.
+// it should be called before unmarshaling --config, so that --config takes precedence
+func setBrokerConfig(cmd *cobra.Command, config map[string]interface{}) error {
+	for k, v := range clusterProfile {
+		if k != "bootstrap.servers" {
+			config[k] = v
+		}
+	}
+
+	sasl, err := getSaslConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if sasl.Mechanism != "" {
+		config["sasl"] = map[string]interface{}{
+			"mechanism":  sasl.Mechanism,
+			"user":       sasl.User,
+			"password":   sasl.Password,
+			"token.file": sasl.TokenFile,
+			"token.env":  sasl.TokenEnv,
+		}
+	}
+	return nil
+}
+
+func newBrokers(cmd *cobra.Command, bootstrapServers string) (*healer.Brokers, error) {
+	config, err := getBrokerConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return healer.NewBrokersWithConfig(bootstrapServers, config)
+}
+
+func newClient(cmd *cobra.Command, bootstrapServers, clientID string) (*healer.Client, error) {
+	config, err := getBrokerConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return healer.NewClientWithConfig(bootstrapServers, clientID, config)
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/childe/healer"
 	"github.com/spf13/cobra"
@@ -51,6 +54,12 @@ var consoleConsumerCmd = &cobra.Command{
 			return err
 		}
 
+		if err := setBrokerConfig(cmd, consumerConfig); err != nil {
+			return err
+		}
+		if err := setFromTime(cmd, consumerConfig); err != nil {
+			return err
+		}
 		json.Unmarshal([]byte(config), &consumerConfig)
 
 		var (
@@ -71,29 +80,146 @@ var consoleConsumerCmd = &cobra.Command{
 			consumer.Assign(assign)
 		}
 
+		// all partitions of the topic are consumed if --partitions is not set
+		pids := make([]int32, len(partitions))
+		for i, pid := range partitions {
+			pids[i] = int32(pid)
+		}
+		if len(pids) == 0 {
+			bs, err := newBrokers(cmd, brokers)
+			if err != nil {
+				return err
+			}
+			pids, err = topicPartitions(bs, client, topic)
+			bs.Close()
+			if err != nil {
+				return err
+			}
+		}
+		stopped := newStoppedPartitions()
+		stopped.OnPartitionsAssigned([]*healer.PartitionAssignment{{Topic: topic, Partitions: pids}})
+
 		messages, err := consumer.Consume(nil)
 		if err != nil {
 			return err
 		}
 
-		for i := 0; i < maxMessages; i++ {
+		for i := 0; i < maxMessages; {
 			message := <-messages
+			if message.Error != nil {
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", message.TopicName, message.PartitionID, message.Error)
+				if stopped.stop(message.Error) {
+					return nil
+				}
+				continue
+			}
 			if ifJson {
 				b, _ := json.Marshal(message)
 				fmt.Printf("%s\n", string(b))
 			} else {
 				fmt.Printf("%d: %s\n", message.Message.Offset, message.Message.Value)
 			}
+			consumer.Ack(message)
+			i++
 		}
 		return nil
 	},
 }
 
+// setFromTime sets start.from.timestamp by --from-time
+func setFromTime(cmd *cobra.Command, config map[string]interface{}) error {
+	datetime, err := cmd.Flags().GetString("from-time")
+	if err != nil {
+		return err
+	}
+	if datetime == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, datetime)
+	if err != nil {
+		return fmt.Errorf("invalid datetime %s, it should be like 2006-01-02T15:04:05Z07:00: %w", datetime, err)
+	}
+	config["start.from.timestamp"] = t.UnixMilli()
+	return nil
+}
+
+// stoppedPartitions records the partitions which report StopPositionReachedError.
+// consumer commands exit when all the consumed partitions reach stop.at.offset or stop.at.timestamp.
+// It is also the RebalanceListener of group-consumer, so the consumed partitions follow the assignment
+type stoppedPartitions struct {
+	mutex    sync.Mutex
+	consumed map[string]map[int32]bool
+	stopped  map[string]map[int32]bool
+}
+
+func newStoppedPartitions() *stoppedPartitions {
+	return &stoppedPartitions{
+		consumed: make(map[string]map[int32]bool),
+		stopped:  make(map[string]map[int32]bool),
+	}
+}
+
+func (s *stoppedPartitions) OnPartitionsAssigned(partitions []*healer.PartitionAssignment) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range partitions {
+		if _, ok := s.consumed[p.Topic]; !ok {
+			s.consumed[p.Topic] = make(map[int32]bool)
+		}
+		for _, pid := range p.Partitions {
+			s.consumed[p.Topic][pid] = true
+		}
+	}
+}
+
+// OnPartitionsRevoked forgets the partitions, they report StopPositionReachedError again if they are assigned back
+func (s *stoppedPartitions) OnPartitionsRevoked(partitions []*healer.PartitionAssignment) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range partitions {
+		for _, pid := range p.Partitions {
+			delete(s.consumed[p.Topic], pid)
+			delete(s.stopped[p.Topic], pid)
+		}
+	}
+}
+
+func (s *stoppedPartitions) OnPartitionsLost(partitions []*healer.PartitionAssignment) {
+	s.OnPartitionsRevoked(partitions)
+}
+
+// stop records the partition if err is a StopPositionReachedError, and returns true if all the consumed partitions have stopped
+func (s *stoppedPartitions) stop(err error) bool {
+	var stopErr *healer.StopPositionReachedError
+	if !errors.As(err, &stopErr) {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.stopped[stopErr.Topic]; !ok {
+		s.stopped[stopErr.Topic] = make(map[int32]bool)
+	}
+	s.stopped[stopErr.Topic][stopErr.PartitionID] = true
+
+	count := 0
+	for topic, pids := range s.consumed {
+		for pid := range pids {
+			if !s.stopped[topic][pid] {
+				return false
+			}
+			count++
+		}
+	}
+	return count > 0
+}
+
 func init() {
 	consoleConsumerCmd.Flags().String("config", "", `{"xx"="yy","aa"="zz"} refer to https://github.com/childe/healer/blob/master/config.go`)
 	consoleConsumerCmd.Flags().IntSlice("partitions", nil, "partition ids, comma-separated")
-	consoleConsumerCmd.Flags().Int("max-messages", math.MaxInt, "the number of messages to output")
+	consoleConsumerCmd.Flags().Int("max-messages", math.MaxInt, "the number of messages to output. it also exits when all the partitions reach stop.at.offset or stop.at.timestamp in --config")
 	consoleConsumerCmd.Flags().Bool("printoffset", true, "if print offset of each message")
 	consoleConsumerCmd.Flags().Bool("json", false, "print all attributes of message in json format")
 	consoleConsumerCmd.Flags().StringP("topic", "t", "", "topic name")
+	consoleConsumerCmd.Flags().String("from-time", "", "datetime in RFC3339 to start from, like 2026-10-01T00:00:00Z")
 }
@@ -40,6 +40,9 @@ var consoleProducerCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if err := setBrokerConfig(cmd, producerConfig); err != nil {
+			return err
+		}
 		json.Unmarshal([]byte(config), &producerConfig)
 
 		consoleProducer, err := healer.NewProducer(topic, producerConfig)
@@ -60,7 +60,7 @@ var createPartitionsCmd = &cobra.Command{
 			return err
 		}
 
-		bs, err := healer.NewBrokers(brokers)
+		bs, err := newBrokers(cmd, brokers)
 		if err != nil {
 			return fmt.Errorf("failed to create brokers from %s", brokers)
 		}
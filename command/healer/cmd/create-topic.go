@@ -53,7 +53,10 @@ var createTopicCmd = &cobra.Command{
 			return fmt.Errorf(`option "[replica-assignment]" can't be used with option"[replication-factor]"`)
 		}
 
-		config := healer.DefaultBrokerConfig()
+		config, err := getBrokerConfig(cmd)
+		if err != nil {
+			return err
+		}
 		config.Net.TimeoutMSForEachAPI = make([]int, 68)
 		config.Net.TimeoutMSForEachAPI[healer.API_CreateTopics] = int(timeout)
 		bs, err := healer.NewBrokersWithConfig(brokers, config)
@@ -27,7 +27,7 @@ var deleteGroupsCmd = &cobra.Command{
 			return err
 		}
 
-		brokers, err := healer.NewBrokers(bootStrapBrokers)
+		brokers, err := newBrokers(cmd, bootStrapBrokers)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/childe/healer"
+	"github.com/spf13/cobra"
+)
+
+var deleteOffsetsCmd = &cobra.Command{
+	Use:   "delete-offsets",
+	Short: "delete committed offsets of a topic in a group",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		group, err := cmd.Flags().GetString("group")
+		if err != nil {
+			return err
+		}
+		topic, err := cmd.Flags().GetString("topic")
+		if err != nil {
+			return err
+		}
+		partitions, err := cmd.Flags().GetInt32Slice("partitions")
+		if err != nil {
+			return err
+		}
+
+		admin, err := newClient(cmd, brokers, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		resp, err := admin.DeleteGroupOffsets(group, topic, partitions)
+		if resp.ErrorCode != 0 || len(resp.Topics) == 0 {
+			return err
+		}
+
+		// print the result of each partition, the group may be still subscribed to the topic
+		failed := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOPIC\tPARTITION\tRESULT")
+		for _, t := range resp.Topics {
+			for _, p := range t.Partitions {
+				result := "OK"
+				if p.ErrorCode != 0 {
+					failed++
+					result = healer.KafkaError(p.ErrorCode).Error()
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\n", t.Name, p.PartitionIndex, result)
+			}
+		}
+		w.Flush()
+
+		if failed > 0 {
+			return fmt.Errorf("failed to delete offsets of %d partitions", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	deleteOffsetsCmd.Flags().StringP("group", "g", "", "group name")
+	deleteOffsetsCmd.Flags().StringP("topic", "t", "", "topic name")
+	deleteOffsetsCmd.Flags().Int32SliceP("partitions", "p", nil, "partitions. all partitions if not set")
+	deleteOffsetsCmd.MarkFlagRequired("group")
+	deleteOffsetsCmd.MarkFlagRequired("topic")
+
+	rootCmd.AddCommand(deleteOffsetsCmd)
+}
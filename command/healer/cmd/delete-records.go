@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteRecordsCmd = &cobra.Command{
+	Use:   "delete-records",
+	Short: "delete records before the offset or the timestamp of a topic",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bs, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		topic, err := cmd.Flags().GetString("topic")
+		if err != nil {
+			return err
+		}
+		partitions, err := cmd.Flags().GetInt32Slice("partitions")
+		if err != nil {
+			return err
+		}
+		offset, err := cmd.Flags().GetInt64("offset")
+		if err != nil {
+			return err
+		}
+		timestamp, err := cmd.Flags().GetInt64("timestamp")
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("offset") == cmd.Flags().Changed("timestamp") {
+			return errors.New("one of --offset and --timestamp must be set")
+		}
+
+		brokers, err := newBrokers(cmd, bs)
+		if err != nil {
+			return err
+		}
+		defer brokers.Close()
+
+		var offsets map[int32]int64
+		if cmd.Flags().Changed("offset") {
+			pids, err := topicPartitions(brokers, clientID, topic)
+			if err != nil {
+				return err
+			}
+			offsets = make(map[int32]int64, len(pids))
+			for _, pid := range pids {
+				offsets[pid] = offset
+			}
+		} else {
+			if offsets, err = listOffsets(brokers, clientID, topic, timestamp); err != nil {
+				return err
+			}
+			for pid, o := range offsets {
+				if o < 0 {
+					// no message after the timestamp, delete all the records
+					offsets[pid] = -1
+				}
+			}
+		}
+		if len(partitions) > 0 {
+			filtered := make(map[int32]int64)
+			for _, pid := range partitions {
+				o, ok := offsets[pid]
+				if !ok {
+					return fmt.Errorf("partition %d not found in %s", pid, topic)
+				}
+				filtered[pid] = o
+			}
+			offsets = filtered
+		}
+
+		admin, err := newClient(cmd, bs, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		results, err := admin.DeleteRecords(map[string]map[int32]int64{topic: offsets})
+		if len(results) == 0 {
+			return err
+		}
+
+		// print the result of each partition, the error only tells the count of failed partitions
+		pids := make([]int32, 0, len(results[topic]))
+		for pid := range results[topic] {
+			pids = append(pids, pid)
+		}
+		sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOPIC\tPARTITION\tLOW_WATERMARK\tRESULT")
+		for _, pid := range pids {
+			r := results[topic][pid]
+			result := "OK"
+			if r.Err != nil {
+				result = r.Err.Error()
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", topic, pid, r.LowWatermark, result)
+		}
+		w.Flush()
+
+		return err
+	},
+}
+
+func init() {
+	deleteRecordsCmd.Flags().StringP("topic", "t", "", "topic name")
+	deleteRecordsCmd.Flags().Int32SliceP("partitions", "p", nil, "partitions. all partitions if not set")
+	deleteRecordsCmd.Flags().Int64("offset", 0, "delete records before the offset, -1 to delete all the records")
+	deleteRecordsCmd.Flags().Int64("timestamp", 0, "delete records before the timestamp in milliseconds")
+	deleteRecordsCmd.MarkFlagRequired("topic")
+
+	rootCmd.AddCommand(deleteRecordsCmd)
+}
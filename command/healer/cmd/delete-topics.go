@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/childe/healer"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +25,7 @@ var deleteTopicsCmd = &cobra.Command{
 			return err
 		}
 
-		admin, err := healer.NewClient(brokers, clientID)
+		admin, err := newClient(cmd, brokers, clientID)
 
 		if err != nil {
 			return err
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/childe/healer"
+	"github.com/spf13/cobra"
+)
+
+var deleteUserCmd = &cobra.Command{
+	Use:   "delete-user",
+	Short: "delete scram credentials of a user",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			return err
+		}
+		mechanisms, err := cmd.Flags().GetStringSlice("mechanisms")
+		if err != nil {
+			return err
+		}
+
+		deletions := make([]healer.ScramCredentialDeletion, 0, len(mechanisms))
+		for _, mechanism := range mechanisms {
+			var mechanismType healer.ScramMechanismType
+			if err := mechanismType.UnmarshalText([]byte(mechanism)); err != nil {
+				return err
+			}
+			deletions = append(deletions, healer.ScramCredentialDeletion{Name: user, Mechanism: mechanismType})
+		}
+
+		admin, err := newClient(cmd, brokers, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		resp, err := admin.AlterUserScramCredentials(deletions, nil)
+		if err != nil {
+			return err
+		}
+
+		s, err := json.MarshalIndent(resp.Results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(s))
+
+		return nil
+	},
+}
+
+func init() {
+	deleteUserCmd.Flags().String("user", "", "user name")
+	deleteUserCmd.Flags().StringSlice("mechanisms", []string{healer.ScramSHA512.Name}, "mechanisms of the credentials to delete, separated by comma")
+	deleteUserCmd.MarkFlagRequired("user")
+
+	rootCmd.AddCommand(deleteUserCmd)
+}
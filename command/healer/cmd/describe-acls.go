@@ -22,7 +22,7 @@ var describeAclsCmd = &cobra.Command{
 			return err
 		}
 
-		admin, err := healer.NewClient(brokers, clientID)
+		admin, err := newClient(cmd, brokers, clientID)
 
 		if err != nil {
 			return err
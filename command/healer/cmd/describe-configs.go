@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/childe/healer"
 	"github.com/spf13/cobra"
 )
 
@@ -39,7 +38,7 @@ var describeConfigsCmd = &cobra.Command{
 			}
 		}
 
-		admin, err := healer.NewClient(brokers, client)
+		admin, err := newClient(cmd, brokers, client)
 		if err != nil {
 			return err
 		}
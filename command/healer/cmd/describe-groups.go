@@ -27,7 +27,7 @@ var describeGroupsCmd = &cobra.Command{
 			return err
 		}
 
-		brokers, err := healer.NewBrokers(bs)
+		brokers, err := newBrokers(cmd, bs)
 		if err != nil {
 			return err
 		}
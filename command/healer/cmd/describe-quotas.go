@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/childe/healer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var quotaEntityTypes = []string{healer.QuotaEntityUser, healer.QuotaEntityClientID, healer.QuotaEntityIP}
+
+// addQuotaEntityFlags adds --user, --user-default, --client-id, --client-id-default, --ip, --ip-default
+func addQuotaEntityFlags(flags *pflag.FlagSet) {
+	for _, t := range quotaEntityTypes {
+		flags.String(t, "", fmt.Sprintf("%s name", t))
+		flags.Bool(t+"-default", false, fmt.Sprintf("the default %s", t))
+	}
+}
+
+// getQuotaEntity returns the entity from the flags, nil EntityName is the default entity
+func getQuotaEntity(cmd *cobra.Command) ([]healer.QuotaEntity, error) {
+	entity := make([]healer.QuotaEntity, 0)
+	for _, t := range quotaEntityTypes {
+		isDefault, err := cmd.Flags().GetBool(t + "-default")
+		if err != nil {
+			return nil, err
+		}
+		if isDefault && cmd.Flags().Changed(t) {
+			return nil, fmt.Errorf("--%s and --%s-default could not be set together", t, t)
+		}
+		if isDefault {
+			entity = append(entity, healer.QuotaEntity{EntityType: t})
+			continue
+		}
+		if cmd.Flags().Changed(t) {
+			name, err := cmd.Flags().GetString(t)
+			if err != nil {
+				return nil, err
+			}
+			entity = append(entity, healer.QuotaEntity{EntityType: t, EntityName: &name})
+		}
+	}
+	return entity, nil
+}
+
+var describeQuotasCmd = &cobra.Command{
+	Use:   "describe-quotas",
+	Short: "describe client quotas. all quotas are returned if no entity is set",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return err
+		}
+		entity, err := getQuotaEntity(cmd)
+		if err != nil {
+			return err
+		}
+
+		components := make([]healer.QuotaFilterComponent, 0, len(entity))
+		for _, e := range entity {
+			if e.EntityName == nil {
+				components = append(components, healer.QuotaFilterComponent{EntityType: e.EntityType, MatchType: healer.QuotaMatchDefault})
+			} else {
+				components = append(components, healer.QuotaFilterComponent{EntityType: e.EntityType, MatchType: healer.QuotaMatchExact, Match: e.EntityName})
+			}
+		}
+
+		admin, err := newClient(cmd, brokers, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		resp, err := admin.DescribeClientQuotas(components, strict)
+		if err != nil {
+			return err
+		}
+
+		s, err := json.MarshalIndent(resp.Entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(s))
+
+		return nil
+	},
+}
+
+func init() {
+	addQuotaEntityFlags(describeQuotasCmd.Flags())
+	describeQuotasCmd.Flags().Bool("strict", false, "only return the entities that have no other entity types than the ones set")
+
+	rootCmd.AddCommand(describeQuotasCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var describeUsersCmd = &cobra.Command{
+	Use:   "describe-users",
+	Short: "describe scram credentials of users",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		users, err := cmd.Flags().GetStringSlice("users")
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			users = nil
+		}
+
+		admin, err := newClient(cmd, brokers, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		resp, err := admin.DescribeUserScramCredentials(users)
+		if err != nil {
+			return err
+		}
+
+		s, err := json.MarshalIndent(resp.Results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(s))
+
+		return nil
+	},
+}
+
+func init() {
+	describeUsersCmd.Flags().StringSlice("users", nil, "user names, separated by comma. all users if not set")
+
+	rootCmd.AddCommand(describeUsersCmd)
+}
@@ -21,7 +21,7 @@ var electLeadersCmd = &cobra.Command{
 		timeoutMS, err := cmd.Flags().GetInt32("timeout.ms")
 		brokers, err := cmd.Flags().GetString("brokers")
 
-		bs, err := healer.NewBrokers(brokers)
+		bs, err := newBrokers(cmd, brokers)
 		if err != nil {
 			return err
 		}
@@ -18,7 +18,7 @@ var getMetadataCmd = &cobra.Command{
 		topics, err := cmd.Flags().GetStringSlice("topics")
 		format, err := cmd.Flags().GetString("format")
 
-		brokers, err := healer.NewBrokers(bs)
+		brokers, err := newBrokers(cmd, bs)
 
 		if err != nil {
 			return fmt.Errorf("failed to get offsets: %w", err)
@@ -37,7 +37,7 @@ var getOffsetsCmd = &cobra.Command{
 			timestamp = 0
 		}
 
-		brokers, err := healer.NewBrokers(bs)
+		brokers, err := newBrokers(cmd, bs)
 
 		if err != nil {
 			return fmt.Errorf("failed to create brokers from %s: %w", bs, err)
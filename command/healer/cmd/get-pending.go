@@ -258,7 +258,7 @@ var getPendingCmd = &cobra.Command{
 			return nil
 		}
 
-		brokers, err = healer.NewBrokers(bootstrapBrokers)
+		brokers, err = newBrokers(cmd, bootstrapBrokers)
 		if err != nil {
 			return fmt.Errorf("failed to create brokers: %w", err)
 		}
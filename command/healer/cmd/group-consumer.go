@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/childe/healer"
@@ -34,8 +35,12 @@ var groupConsumerCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		if topic == "" || err != nil {
-			return errors.New("topic must be specified")
+		pattern, err := cmd.Flags().GetString("pattern")
+		if err != nil {
+			return err
+		}
+		if (topic == "") == (pattern == "") {
+			return errors.New("one of topic and pattern must be specified")
 		}
 		group, err := cmd.Flags().GetString("group")
 		if err != nil {
@@ -58,12 +63,26 @@ var groupConsumerCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if err := setBrokerConfig(cmd, consumerConfig); err != nil {
+			return err
+		}
+		if err := setFromTime(cmd, consumerConfig); err != nil {
+			return err
+		}
 		json.Unmarshal([]byte(config), &consumerConfig)
 
-		consumer, err := healer.NewGroupConsumer(topic, consumerConfig)
+		var consumer *healer.GroupConsumer
+		if pattern != "" {
+			consumer, err = healer.NewGroupConsumerWithPattern(pattern, consumerConfig)
+		} else {
+			consumer, err = healer.NewGroupConsumerWithTopics(strings.Split(topic, ","), consumerConfig)
+		}
 		if err != nil {
 			return err
 		}
+		stopped := newStoppedPartitions()
+		consumer.SetRebalanceListener(stopped)
+
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGINT)
 
@@ -80,6 +99,13 @@ var groupConsumerCmd = &cobra.Command{
 			case <-sigChan:
 				return nil
 			case message := <-messages:
+				if message.Error != nil {
+					fmt.Fprintf(os.Stderr, "%s:%d: %s\n", message.TopicName, message.PartitionID, message.Error)
+					if stopped.stop(message.Error) {
+						return nil
+					}
+					continue
+				}
 				if jsonFormat {
 					b, err := json.Marshal(message)
 					if err != nil {
@@ -90,6 +116,7 @@ var groupConsumerCmd = &cobra.Command{
 				} else {
 					fmt.Printf("%s:%d:%d: %s\n", message.TopicName, message.PartitionID, message.Message.Offset, message.Message.Value)
 				}
+				consumer.Ack(message)
 				i++
 				if maxMessages > 0 && i >= maxMessages {
 					return nil
@@ -100,9 +127,11 @@ var groupConsumerCmd = &cobra.Command{
 }
 
 func init() {
-	groupConsumerCmd.Flags().StringP("topic", "t", "", "topic name")
+	groupConsumerCmd.Flags().StringP("topic", "t", "", "topic name, several topics are separated by comma")
+	groupConsumerCmd.Flags().String("pattern", "", "regular expression of the topics to subscribe, such as 'events\\..*'")
 	groupConsumerCmd.Flags().StringP("group", "g", "", "group id")
 	groupConsumerCmd.Flags().String("config", "", `{"xx"="yy","aa"="zz"} refer to https://github.com/childe/healer/blob/master/config.go`)
-	groupConsumerCmd.Flags().Int("max-messages", math.MaxInt, "the number of messages to output")
+	groupConsumerCmd.Flags().Int("max-messages", math.MaxInt, "the number of messages to output. it also exits when all the assigned partitions reach stop.at.offset or stop.at.timestamp in --config")
 	groupConsumerCmd.Flags().Bool("json", false, "print message in json format")
+	groupConsumerCmd.Flags().String("from-time", "", "datetime in RFC3339 to start from if the group has no committed offset, like 2026-10-01T00:00:00Z")
 }
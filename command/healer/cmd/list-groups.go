@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/childe/healer"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +20,7 @@ var listGroupsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		client, err := healer.NewClient(bs, clientID)
+		client, err := newClient(cmd, bs, clientID)
 		if err != nil {
 			return err
 		}
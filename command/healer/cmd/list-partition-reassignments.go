@@ -31,7 +31,10 @@ var listPartitionReassignmentsCmd = &cobra.Command{
 			return err
 		}
 
-		config := healer.DefaultBrokerConfig()
+		config, err := getBrokerConfig(cmd)
+		if err != nil {
+			return err
+		}
 		config.Net.TimeoutMSForEachAPI = make([]int, 68)
 		config.Net.TimeoutMSForEachAPI[healer.API_ListPartitionReassignments] = int(timeoutMS)
 		bs, err := healer.NewBrokersWithConfig(brokers, config)
@@ -1,77 +1,425 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/childe/healer"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 )
 
+// only one of these flags could be set
+var resetOffsetModes = []string{"timestamp", "to-datetime", "by-duration", "shift-by", "to-offset", "to-current", "from-file"}
+
+// topic -> partition -> offset
+type topicOffsets map[string]map[int32]int64
+
+func (o topicOffsets) set(topic string, partition int32, offset int64) {
+	if _, ok := o[topic]; !ok {
+		o[topic] = make(map[int32]int64)
+	}
+	o[topic][partition] = offset
+}
+
+// get returns -1 if the offset of the partition is not set
+func (o topicOffsets) get(topic string, partition int32) int64 {
+	if offset, ok := o[topic][partition]; ok {
+		return offset
+	}
+	return -1
+}
+
+// readOffsetsFile reads offsets from a csv file, each line is topic,partition,offset
+func readOffsetsFile(path string) (topicOffsets, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	offsets := make(topicOffsets)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s error: %w", path, err)
+		}
+		partition, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition in %s: %v", path, record)
+		}
+		offset, err := strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset in %s: %v", path, record)
+		}
+		offsets.set(strings.TrimSpace(record[0]), int32(partition), offset)
+	}
+	return offsets, nil
+}
+
+// listOffsets returns the offsets of all partitions of the topic by timestamp, -1 for latest and -2 for earliest
+func listOffsets(brokers *healer.Brokers, client, topic string, timestamp int64) (map[int32]int64, error) {
+	offsetsResponses, err := brokers.RequestOffsets(client, topic, -1, timestamp, 1)
+	if err != nil {
+		return nil, fmt.Errorf("request offsets error: %w. topic: %s, timestamp: %d", err, topic, timestamp)
+	}
+
+	offsets := make(map[int32]int64)
+	for _, offsetsResponse := range offsetsResponses {
+		if err := offsetsResponse.Error(); err != nil {
+			return nil, fmt.Errorf("request offsets error: %w. topic: %s, timestamp: %d", err, topic, timestamp)
+		}
+		for _, partitionOffsets := range offsetsResponse.TopicPartitionOffsets {
+			for _, partitionOffset := range partitionOffsets {
+				offsets[partitionOffset.Partition] = partitionOffset.GetOffset()
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// topicPartitions returns the partition ids of the topic from metadata
+func topicPartitions(brokers *healer.Brokers, client, topic string) ([]int32, error) {
+	metadataResponse, err := brokers.RequestMetaData(client, []string{topic})
+	if err == nil {
+		err = metadataResponse.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get metadata of %s error: %w", topic, err)
+	}
+
+	partitions := make([]int32, 0)
+	for _, topicMetadata := range metadataResponse.TopicMetadatas {
+		for _, partitionMetadata := range topicMetadata.PartitionMetadatas {
+			partitions = append(partitions, partitionMetadata.PartitionID)
+		}
+	}
+	return partitions, nil
+}
+
+// fetchCommittedOffsets returns the committed offsets of the group. offsets of all topics are returned if partitions is nil
+func fetchCommittedOffsets(coordinator *healer.Broker, client, group string, partitions map[string][]int32) (topicOffsets, error) {
+	r := healer.NewOffsetFetchRequest(2, client, group)
+	if partitions == nil {
+		r.FetchAllTopics()
+	}
+	for topic, pids := range partitions {
+		for _, pid := range pids {
+			r.AddPartiton(topic, pid)
+		}
+	}
+
+	resp, err := coordinator.RequestAndGet(r)
+	if err != nil {
+		return nil, fmt.Errorf("fetch committed offsets of %s error: %w", group, err)
+	}
+
+	offsets := make(topicOffsets)
+	for _, t := range resp.(healer.OffsetFetchResponse).Topics {
+		for _, p := range t.Partitions {
+			if partitions == nil && p.Offset < 0 {
+				continue
+			}
+			offsets.set(t.Topic, p.PartitionID, p.Offset)
+		}
+	}
+	return offsets, nil
+}
+
+// checkGroupInactive returns error if the group has active members, offsets committed by them would overwrite the reset ones
+func checkGroupInactive(coordinator *healer.Broker, client, group string) error {
+	resp, err := coordinator.RequestAndGet(healer.NewDescribeGroupsRequest(client, []string{group}))
+	if err != nil {
+		return fmt.Errorf("describe group %s error: %w", group, err)
+	}
+	for _, g := range resp.(healer.DescribeGroupsResponse).Groups {
+		if len(g.Members) > 0 {
+			return fmt.Errorf("group %s is %s with %d active members, stop them before resetting offsets", group, g.State, len(g.Members))
+		}
+	}
+	return nil
+}
+
 var resetOffsetCmd = &cobra.Command{
 	Use:   "reset-offset",
 	Short: "reset offset of a group-topic",
+	Long: `reset offsets of a group. one of these modes must be set:
+  --timestamp       offsets at the timestamp in milliseconds, -2 to start offset, -1 to end offset
+  --to-datetime     offsets at the datetime, like 2026-10-01T00:00:00Z
+  --by-duration     offsets at the duration before now, like 2h
+  --shift-by        shift the committed offsets by n, could be negative
+  --to-offset       the offset for all the partitions
+  --to-current      the committed offsets, end offset if not committed
+  --from-file       offsets from a csv file, each line is topic,partition,offset
+new offsets are limited between start offset and end offset.
+the group must not have active members, or else the command refuses to commit.`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		bs, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
 		client, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
 		topic, err := cmd.Flags().GetString("topic")
+		if err != nil {
+			return err
+		}
 		partitions, err := cmd.Flags().GetInt32Slice("partitions")
+		if err != nil {
+			return err
+		}
 		group, err := cmd.Flags().GetString("group")
-		offsetsStorage, err := cmd.Flags().GetString("offset.storage")
-		timestamp, err := cmd.Flags().GetInt64("timestamp")
+		if err != nil {
+			return err
+		}
+		offsetsStorage, err := cmd.Flags().GetString("offsets.storage")
+		if err != nil {
+			return err
+		}
+		allTopics, err := cmd.Flags().GetBool("all-topics")
+		if err != nil {
+			return err
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
+		mode := ""
+		for _, m := range resetOffsetModes {
+			if cmd.Flags().Changed(m) {
+				if mode != "" {
+					return fmt.Errorf("--%s and --%s could not be set together", mode, m)
+				}
+				mode = m
+			}
+		}
+		if mode == "" {
+			return fmt.Errorf("one of --%s must be set", strings.Join(resetOffsetModes, ", --"))
+		}
+		if mode == "from-file" && (allTopics || topic != "") {
+			return errors.New("--from-file could not be set with --topic or --all-topics")
+		}
+		if mode != "from-file" && allTopics == (topic != "") {
+			return errors.New("one of --topic and --all-topics must be set")
+		}
+
+		// timestamp in milliseconds for the modes by time
+		var timestamp int64
+		switch mode {
+		case "timestamp":
+			if timestamp, err = cmd.Flags().GetInt64("timestamp"); err != nil {
+				return err
+			}
+		case "to-datetime":
+			datetime, err := cmd.Flags().GetString("to-datetime")
+			if err != nil {
+				return err
+			}
+			t, err := time.Parse(time.RFC3339, datetime)
+			if err != nil {
+				return fmt.Errorf("invalid datetime %s, it should be like 2006-01-02T15:04:05Z07:00: %w", datetime, err)
+			}
+			timestamp = t.UnixMilli()
+		case "by-duration":
+			duration, err := cmd.Flags().GetDuration("by-duration")
+			if err != nil {
+				return err
+			}
+			timestamp = time.Now().Add(-duration).UnixMilli()
+		}
 
 		userCustomPartitions := make(map[int32]struct{})
 		for _, partition := range partitions {
 			userCustomPartitions[partition] = struct{}{}
 		}
 
-		brokers, err := healer.NewBrokers(bs)
-
+		brokers, err := newBrokers(cmd, bs)
 		if err != nil {
 			return err
 		}
 
-		metaDataResponse, err := brokers.RequestMetaData(client, []string{topic})
-		if err == nil {
-			err = metaDataResponse.Error()
+		coordinatorResponse, err := brokers.FindCoordinator(client, group)
+		if err != nil {
+			return err
 		}
+		coordinator, err := brokers.GetBroker(coordinatorResponse.Coordinator.NodeID)
 		if err != nil {
-			return fmt.Errorf("could not get metadata: %w", err)
+			return fmt.Errorf("could not get broker[%d]:%s", coordinatorResponse.Coordinator.NodeID, err)
 		}
+		klog.Infof("coordinator for group[%s]:%s", group, coordinator)
 
-		offsetsResponses, err := brokers.RequestOffsets(client, topic, -1, timestamp, 1)
-		if err != nil {
-			return fmt.Errorf("request offsets error: %w. topic: %s, timestmap: %d", err, topic, timestamp)
+		// 1. partitions to reset and their committed offsets
+		var (
+			fileOffsets topicOffsets
+			committed   topicOffsets
+			toReset     = make(map[string][]int32)
+		)
+		switch {
+		case mode == "from-file":
+			path, err := cmd.Flags().GetString("from-file")
+			if err != nil {
+				return err
+			}
+			if fileOffsets, err = readOffsetsFile(path); err != nil {
+				return err
+			}
+			for t, offsets := range fileOffsets {
+				for pid := range offsets {
+					toReset[t] = append(toReset[t], pid)
+				}
+			}
+		case allTopics:
+			all, err := fetchCommittedOffsets(coordinator, client, group, nil)
+			if err != nil {
+				return err
+			}
+			for t, offsets := range all {
+				for pid := range offsets {
+					toReset[t] = append(toReset[t], pid)
+				}
+			}
+		default:
+			metaDataResponse, err := brokers.RequestMetaData(client, []string{topic})
+			if err == nil {
+				err = metaDataResponse.Error()
+			}
+			if err != nil {
+				return fmt.Errorf("could not get metadata: %w", err)
+			}
+			for _, topicMetadata := range metaDataResponse.TopicMetadatas {
+				for _, partitionMetadata := range topicMetadata.PartitionMetadatas {
+					toReset[topic] = append(toReset[topic], partitionMetadata.PartitionID)
+				}
+			}
+		}
+		if len(userCustomPartitions) > 0 {
+			for t, pids := range toReset {
+				filtered := make([]int32, 0, len(pids))
+				for _, pid := range pids {
+					if _, ok := userCustomPartitions[pid]; ok {
+						filtered = append(filtered, pid)
+					}
+				}
+				toReset[t] = filtered
+			}
+		}
+		if committed, err = fetchCommittedOffsets(coordinator, client, group, toReset); err != nil {
+			return err
 		}
 
-		offsets := make(map[int32]int64)
-		for _, offsetsResponse := range offsetsResponses {
-			if err := offsetsResponse.Error(); err != nil {
-				return fmt.Errorf("request offsets error: %w. topic: %s, timestmap: %d", err, topic, timestamp)
+		// 2. compute new offsets, they are limited between start offset and end offset
+		newOffsets := make(topicOffsets)
+		for t, pids := range toReset {
+			earliest, err := listOffsets(brokers, client, t, -2)
+			if err != nil {
+				return err
+			}
+			latest, err := listOffsets(brokers, client, t, -1)
+			if err != nil {
+				return err
+			}
+			var byTime map[int32]int64
+			if mode == "timestamp" || mode == "to-datetime" || mode == "by-duration" {
+				if byTime, err = listOffsets(brokers, client, t, timestamp); err != nil {
+					return err
+				}
 			}
-			for _, partitionOffsets := range offsetsResponse.TopicPartitionOffsets {
-				for _, partitionOffset := range partitionOffsets {
-					partition := partitionOffset.Partition
-					offsets[partition] = partitionOffset.GetOffset()
+
+			for _, pid := range pids {
+				current := committed.get(t, pid)
+				var offset int64
+				switch mode {
+				case "timestamp", "to-datetime", "by-duration":
+					offset = byTime[pid]
+					if offset < 0 {
+						// no message after the timestamp
+						offset = latest[pid]
+					}
+				case "shift-by":
+					if current < 0 {
+						return fmt.Errorf("could not shift %s-%d, group %s has no committed offset", t, pid, group)
+					}
+					shift, err := cmd.Flags().GetInt64("shift-by")
+					if err != nil {
+						return err
+					}
+					offset = current + shift
+				case "to-offset":
+					if offset, err = cmd.Flags().GetInt64("to-offset"); err != nil {
+						return err
+					}
+				case "to-current":
+					offset = current
+					if offset < 0 {
+						offset = latest[pid]
+					}
+				case "from-file":
+					offset = fileOffsets[t][pid]
+				}
+
+				if offset < earliest[pid] {
+					offset = earliest[pid]
 				}
+				if offset > latest[pid] {
+					offset = latest[pid]
+				}
+				newOffsets.set(t, pid, offset)
 			}
 		}
 
-		// 1. get coordinator
-		var coordinator *healer.Broker
-		coordinatorResponse, err := brokers.FindCoordinator(client, group)
-		if err != nil {
-			return err
+		// 3. print the before/after table
+		topics := make([]string, 0, len(newOffsets))
+		for t := range newOffsets {
+			topics = append(topics, t)
+		}
+		sort.Strings(topics)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOPIC\tPARTITION\tCURRENT-OFFSET\tNEW-OFFSET")
+		for _, t := range topics {
+			pids := make([]int32, 0, len(newOffsets[t]))
+			for pid := range newOffsets[t] {
+				pids = append(pids, pid)
+			}
+			sort.Sort(By(pids))
+			for _, pid := range pids {
+				current := "-"
+				if offset := committed.get(t, pid); offset >= 0 {
+					current = strconv.FormatInt(offset, 10)
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%d\n", t, pid, current, newOffsets[t][pid])
+			}
 		}
+		w.Flush()
 
-		coordinator, err = brokers.GetBroker(coordinatorResponse.Coordinator.NodeID)
-		if err != nil {
-			return fmt.Errorf("could not get broker[%d]:%s", coordinatorResponse.Coordinator.NodeID, err)
+		if dryRun {
+			return nil
 		}
-		klog.Infof("coordinator for group[%s]:%s", group, coordinator)
 
 		// 4. commit
+		if err := checkGroupInactive(coordinator, client, group); err != nil {
+			return err
+		}
+
 		var (
 			apiVersion uint16
 		)
@@ -84,14 +432,11 @@ var resetOffsetCmd = &cobra.Command{
 		offsetComimtReq.SetMemberID("")
 		offsetComimtReq.SetGenerationID(-1)
 		offsetComimtReq.SetRetentionTime(-1)
-		for partitionID, offset := range offsets {
-			if len(userCustomPartitions) > 0 {
-				if _, ok := userCustomPartitions[partitionID]; !ok {
-					continue
-				}
+		for t, offsets := range newOffsets {
+			for partitionID, offset := range offsets {
+				offsetComimtReq.AddPartiton(t, partitionID, offset, "")
+				klog.Infof("commit offset [%s][%d]:%d", t, partitionID, offset)
 			}
-			offsetComimtReq.AddPartiton(topic, partitionID, offset, "")
-			klog.Infof("commit offset [%s][%d]:%d", topic, partitionID, offset)
 		}
 
 		_, err = coordinator.RequestAndGet(offsetComimtReq)
@@ -104,11 +449,17 @@ var resetOffsetCmd = &cobra.Command{
 
 func init() {
 	resetOffsetCmd.Flags().StringP("topic", "t", "", "topic name")
+	resetOffsetCmd.Flags().Bool("all-topics", false, "reset all the topics that the group has committed")
 	resetOffsetCmd.Flags().Int32SliceP("partitions", "p", nil, "partitions. all partitions if not set")
 	resetOffsetCmd.Flags().StringP("group", "g", "", "group name")
-	resetOffsetCmd.Flags().Int64("timestamp", 0, "-2 to start offset, -1 to end offset")
+	resetOffsetCmd.Flags().Int64("timestamp", 0, "timestamp in milliseconds, -2 to start offset, -1 to end offset")
+	resetOffsetCmd.Flags().String("to-datetime", "", "datetime in RFC3339, like 2026-10-01T00:00:00Z")
+	resetOffsetCmd.Flags().Duration("by-duration", 0, "duration before now, like 2h")
+	resetOffsetCmd.Flags().Int64("shift-by", 0, "shift the committed offsets by n, could be negative")
+	resetOffsetCmd.Flags().Int64("to-offset", 0, "reset to the offset")
+	resetOffsetCmd.Flags().Bool("to-current", false, "reset to the committed offsets")
+	resetOffsetCmd.Flags().String("from-file", "", "csv file, each line is topic,partition,offset")
+	resetOffsetCmd.Flags().Bool("dry-run", false, "only print the current and new offsets, do not commit")
 	resetOffsetCmd.Flags().String("offsets.storage", "kafka", "kafka or zookeeper")
-	resetOffsetCmd.MarkFlagRequired("topic")
 	resetOffsetCmd.MarkFlagRequired("group")
-	resetOffsetCmd.MarkFlagRequired("timestamp")
 }
@@ -11,6 +11,9 @@ var rootCmd = &cobra.Command{
 	Use:   "healer",
 	Short: "kafka tool. you can use it to consume and produce message, and do kafka admin job",
 
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadClusterProfile(cmd)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 	},
 }
@@ -25,8 +28,15 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringP("brokers", "b", "", "(required) broker list, seperated by comma")
+	rootCmd.PersistentFlags().StringP("brokers", "b", "", "(required if --cluster is not set) broker list, seperated by comma")
+	rootCmd.PersistentFlags().String("cluster", "", "cluster name in the cluster config file, its bootstrap.servers, tls, sasl and net settings are used")
+	rootCmd.PersistentFlags().String("cluster-config", defaultClusterConfigFile, "cluster config file")
 	rootCmd.PersistentFlags().StringP("client", "c", "healer", "client name")
+	rootCmd.PersistentFlags().String("sasl.mechanism", "", "sasl mechanism: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or OAUTHBEARER")
+	rootCmd.PersistentFlags().String("sasl.user", "", "sasl user")
+	rootCmd.PersistentFlags().String("sasl.password", "", "sasl password")
+	rootCmd.PersistentFlags().String("sasl.token.file", "", "file of the jwt for OAUTHBEARER")
+	rootCmd.PersistentFlags().String("sasl.token.env", "", "environment variable of the jwt for OAUTHBEARER")
 
 	rootCmd.AddCommand(getMetadataCmd)
 
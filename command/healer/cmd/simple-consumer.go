@@ -63,6 +63,9 @@ var simpleConsumerCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if err := setBrokerConfig(cmd, consumerConfig); err != nil {
+			return err
+		}
 		json.Unmarshal([]byte(config), &consumerConfig)
 
 		simpleConsumer, err := healer.NewSimpleConsumer(topic, partition, consumerConfig)
@@ -45,6 +45,9 @@ var simpleProducerCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if err := setBrokerConfig(cmd, producerConfig); err != nil {
+			return err
+		}
 		json.Unmarshal([]byte(config), &producerConfig)
 
 		simpleProducer, err := healer.NewSimpleProducer(context.Background(), topic, partition, producerConfig)
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/childe/healer"
+	"github.com/spf13/cobra"
+)
+
+var upsertUserCmd = &cobra.Command{
+	Use:   "upsert-user",
+	Short: "create a scram user or update the password of it",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		brokers, err := cmd.Flags().GetString("brokers")
+		if err != nil {
+			return err
+		}
+		clientID, err := cmd.Flags().GetString("client")
+		if err != nil {
+			return err
+		}
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			return err
+		}
+		password, err := cmd.Flags().GetString("password")
+		if err != nil {
+			return err
+		}
+		mechanism, err := cmd.Flags().GetString("mechanism")
+		if err != nil {
+			return err
+		}
+		iterations, err := cmd.Flags().GetInt32("iterations")
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			return errors.New("password must not be empty")
+		}
+
+		var mechanismType healer.ScramMechanismType
+		if err := mechanismType.UnmarshalText([]byte(mechanism)); err != nil {
+			return err
+		}
+		upsertion, err := healer.NewScramCredentialUpsertion(user, mechanismType, password, iterations)
+		if err != nil {
+			return err
+		}
+
+		admin, err := newClient(cmd, brokers, clientID)
+		if err != nil {
+			return err
+		}
+		defer admin.Close()
+
+		resp, err := admin.AlterUserScramCredentials(nil, []healer.ScramCredentialUpsertion{upsertion})
+		if err != nil {
+			return err
+		}
+
+		s, err := json.MarshalIndent(resp.Results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(s))
+
+		return nil
+	},
+}
+
+func init() {
+	upsertUserCmd.Flags().String("user", "", "user name")
+	upsertUserCmd.Flags().String("password", "", "password of the user")
+	upsertUserCmd.Flags().String("mechanism", healer.ScramSHA512.Name, "SCRAM-SHA-256 or SCRAM-SHA-512")
+	upsertUserCmd.Flags().Int32("iterations", healer.DefaultScramIterations, "iterations to salt the password")
+	upsertUserCmd.MarkFlagRequired("user")
+	upsertUserCmd.MarkFlagRequired("password")
+
+	rootCmd.AddCommand(upsertUserCmd)
+}
@@ -4,16 +4,46 @@ type Compressor interface {
 	Compress([]byte) ([]byte, error)
 }
 
+// NewCompressor returns the compressor of cType with the default compression level
 func NewCompressor(cType string) Compressor {
+	return NewCompressorWithLevel(cType, 0)
+}
+
+// NewCompressorWithLevel returns the compressor of cType with the given compression level.
+// level 0 means the default level of the codec, otherwise:
+//   - gzip: 1(BestSpeed) - 9(BestCompression)
+//   - lz4: 1 - 17, higher level uses HC compression
+//   - zstd: 1 - 22, it is mapped to the nearest level that klauspost/compress supports
+//   - snappy/none: level is ignored
+func NewCompressorWithLevel(cType string, level int) Compressor {
 	switch cType {
 	case "gzip":
-		return &GzipCompressor{}
+		return &GzipCompressor{level: level}
 	case "lz4":
-		return &LZ4Compressor{}
+		return &LZ4Compressor{level: level}
 	case "snappy":
 		return &SnappyCompressor{}
+	case "zstd":
+		return newZstdCompressor(level)
 	case "none":
 		return &NoneCompressor{}
 	}
 	return nil
 }
+
+// newCompressorFromCodec returns the compressor of the codec in the attributes of Message or RecordBatch
+func newCompressorFromCodec(codec int8) Compressor {
+	switch codec {
+	case COMPRESSION_NONE:
+		return NewCompressor("none")
+	case COMPRESSION_GZIP:
+		return NewCompressor("gzip")
+	case COMPRESSION_SNAPPY:
+		return NewCompressor("snappy")
+	case COMPRESSION_LZ4:
+		return NewCompressor("lz4")
+	case COMPRESSION_ZSTD:
+		return NewCompressor("zstd")
+	}
+	return nil
+}
@@ -15,17 +15,39 @@ type NetConfig struct {
 }
 
 type TLSConfig struct {
-	Cert               string `json:"cert" mapstructure:"cert"`
-	Key                string `json:"key" mapstructure:"key"`
-	CA                 string `json:"ca" mapstructure:"ca"`
+	// Cert and Key are the files of the client certificate, it is optional for one-way tls.
+	// the files are reloaded if they are modified, so new connections use the rotated certificate
+	Cert string `json:"cert" mapstructure:"cert"`
+	Key  string `json:"key" mapstructure:"key"`
+	// CA is the file of CA certificates, the system roots are used if neither CA nor CAPEM is set
+	CA string `json:"ca" mapstructure:"ca"`
+
+	// CertPEM, KeyPEM and CAPEM are the PEM contents, they take precedence over the files
+	CertPEM string `json:"cert.pem" mapstructure:"cert.pem"`
+	KeyPEM  string `json:"key.pem" mapstructure:"key.pem"`
+	CAPEM   string `json:"ca.pem" mapstructure:"ca.pem"`
+	// SystemRoots adds the system roots to the CA if CA or CAPEM is set
+	SystemRoots bool `json:"system.roots,string" mapstructure:"system.roots"`
+
 	InsecureSkipVerify bool   `json:"insecure.skip.verify,string" mapstructure:"insecure.skip.verify"`
 	ServerName         string `json:"servername" mapstructure:"servername"`
+
+	// MinVersion is the minimum tls version: 1.0, 1.1, 1.2 or 1.3. default of Go is used if empty
+	MinVersion string `json:"min.version" mapstructure:"min.version"`
+	// CipherSuites are the names of cipher suites for tls 1.2 and below, such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	CipherSuites []string `json:"cipher.suites" mapstructure:"cipher.suites"`
 }
 
 type SaslConfig struct {
 	Mechanism string `json:"mechanism" mapstructure:"mechanism"`
 	User      string `json:"user" mapstructure:"user"`
 	Password  string `json:"password" mapstructure:"password"`
+
+	// TokenFile and TokenEnv are the file and the environment variable of the JWT for OAUTHBEARER, the token is cached by each broker and read again before it expires.
+	// TokenProvider supplied by the application takes precedence over them
+	TokenFile     string        `json:"token.file" mapstructure:"token.file"`
+	TokenEnv      string        `json:"token.env" mapstructure:"token.env"`
+	TokenProvider TokenProvider `json:"-" mapstructure:"-"`
 }
 
 type BrokerConfig struct {
@@ -34,6 +56,9 @@ type BrokerConfig struct {
 	MetadataRefreshIntervalMS int        `json:"metadata.refresh.interval.ms,string" mapstructure:"metadata.refresh.interval.ms"`
 	TLSEnabled                bool       `json:"tls.enabled,string" mapstructure:"tls.enabled"`
 	TLS                       *TLSConfig `json:"tls" mapstructure:"tls"`
+
+	// MaxInFlightRequestsPerConnection is the max number of requests waiting for responses on one connection
+	MaxInFlightRequestsPerConnection int `json:"max.in.flight.requests.per.connection,string" mapstructure:"max.in.flight.requests.per.connection"`
 }
 
 func DefaultBrokerConfig() *BrokerConfig {
@@ -44,8 +69,9 @@ func DefaultBrokerConfig() *BrokerConfig {
 			TimeoutMSForEachAPI: make([]int, 0),
 			KeepAliveMS:         7200000,
 		},
-		MetadataRefreshIntervalMS: 300 * 1000,
-		TLSEnabled:                false,
+		MetadataRefreshIntervalMS:        300 * 1000,
+		TLSEnabled:                       false,
+		MaxInFlightRequestsPerConnection: defaultMaxInFlightRequestsPerConnection,
 	}
 }
 
@@ -59,6 +85,9 @@ func getBrokerConfigFromConsumerConfig(c ConsumerConfig) *BrokerConfig {
 	if c.MetadataRefreshIntervalMS > 0 {
 		b.MetadataRefreshIntervalMS = c.MetadataRefreshIntervalMS
 	}
+	if c.MaxInFlightRequestsPerConnection > 0 {
+		b.MaxInFlightRequestsPerConnection = c.MaxInFlightRequestsPerConnection
+	}
 	return b
 }
 
@@ -71,6 +100,9 @@ func getBrokerConfigFromProducerConfig(p *ProducerConfig) *BrokerConfig {
 	if p.MetadataRefreshIntervalMS > 0 {
 		b.MetadataRefreshIntervalMS = p.MetadataRefreshIntervalMS
 	}
+	if p.MaxInFlightRequestsPerConnection > 0 {
+		b.MaxInFlightRequestsPerConnection = p.MaxInFlightRequestsPerConnection
+	}
 	return b
 }
 
@@ -88,6 +120,8 @@ type ConsumerConfig struct {
 	BootstrapServers     string     `json:"bootstrap.servers" mapstructure:"bootstrap.servers"`
 	ClientID             string     `json:"client.id" mapstructure:"client.id"`
 	GroupID              string     `json:"group.id" mapstructure:"group.id"`
+	GroupInstanceID      string     `json:"group.instance.id" mapstructure:"group.instance.id"` // static member does not leave group when closed, so it keeps its partitions if it comes back within session.timeout.ms. needs Kafka 2.3+
+	ClientRack           string     `json:"client.rack" mapstructure:"client.rack"`             // rack of the consumer, sent in fetch request so that the broker could return a preferred read replica in the same rack. needs Kafka 2.4+
 	RetryBackOffMS       int        `json:"retry.backoff.ms,string" mapstructure:"retry.backoff.ms"`
 	MetadataMaxAgeMS     int        `json:"metadata.max.age.ms,string" mapstructure:"metadata.max.age.ms"`
 	SessionTimeoutMS     int32      `json:"session.timeout.ms,string" mapstructure:"session.timeout.ms"`
@@ -95,14 +129,29 @@ type ConsumerConfig struct {
 	FetchMaxBytes        int32      `json:"fetch.max.bytes,string" mapstructure:"fetch.max.bytes"` // if this is too small, healer will double it automatically
 	FetchMinBytes        int32      `json:"fetch.min.bytes,string" mapstructure:"fetch.min.bytes"`
 	FromBeginning        bool       `json:"from.beginning,string" mapstructure:"from.beginning"`
+	StartFromTimestamp   int64      `json:"start.from.timestamp,string" mapstructure:"start.from.timestamp"` // timestamp(ms) to start from if there is no committed offset, it overrides from.beginning. needs Kafka 0.10.1+
+	StopAtTimestamp      int64      `json:"stop.at.timestamp,string" mapstructure:"stop.at.timestamp"`       // partition stops at the first message whose timestamp(ms) is not less than it, 0 means no limit
+	StopAtOffset         int64      `json:"stop.at.offset,string" mapstructure:"stop.at.offset"`             // partition stops at this offset, 0 means no limit
 	AutoCommit           bool       `json:"auto.commit,string" mapstructure:"auto.commit"`
 	AutoCommitIntervalMS int        `json:"auto.commit.interval.ms,string" mapstructure:"auto.commit.interval.ms"`
+	AckRequired          bool       `json:"ack.required,string" mapstructure:"ack.required"` // only the offsets of acknowledged contiguous messages are committed if true, messages should be acknowledged by Ack after they are processed
 	OffsetsStorage       int        `json:"offsets.storage,string" mapstructure:"offsets.storage"`
+	// IsolationLevel is read_uncommitted or read_committed. read_committed consumer only returns messages of committed transactions
+	IsolationLevel string `json:"isolation.level" mapstructure:"isolation.level"`
+	// LogTruncationReset decides what to do if the log is truncated after leader changes, which is detected by OffsetForLeaderEpoch.
+	// divergence rewinds to the offset where the log diverged, earliest and latest reset the offset, none stops consuming the partition and sends a LogTruncationError
+	LogTruncationReset string `json:"log.truncation.reset" mapstructure:"log.truncation.reset"`
+	// PartitionAssignmentStrategy is a comma separated list of strategy names in preference order, the coordinator chooses the one all members support.
+	// range, roundrobin, sticky and cooperative-sticky are built in, and others could be registered by RegisterAssignmentStrategy.
+	// with cooperative-sticky, group consumer keeps consuming during rebalance and only stops the partitions which move to other members
+	PartitionAssignmentStrategy string `json:"partition.assignment.strategy" mapstructure:"partition.assignment.strategy"`
 
 	MetadataRefreshIntervalMS int `json:"metadata.refresh.interval.ms,string" mapstructure:"metadata.refresh.interval.ms"`
 
 	TLSEnabled bool       `json:"tls.enabled,string" mapstructure:"tls.enabled"`
 	TLS        *TLSConfig `json:"tls" mapstructure:"tls"`
+
+	MaxInFlightRequestsPerConnection int `json:"max.in.flight.requests.per.connection,string" mapstructure:"max.in.flight.requests.per.connection"`
 }
 
 func DefaultConsumerConfig() ConsumerConfig {
@@ -125,6 +174,10 @@ func DefaultConsumerConfig() ConsumerConfig {
 		AutoCommit:           true,
 		AutoCommitIntervalMS: 5000,
 		OffsetsStorage:       1,
+		IsolationLevel:       "read_uncommitted",
+		LogTruncationReset:   LogTruncationResetDivergence,
+
+		PartitionAssignmentStrategy: AssignmentStrategyRange,
 	}
 
 	if len(c.Net.TimeoutMSForEachAPI) == 0 {
@@ -164,6 +217,25 @@ func createConsumerConfig(config interface{}) (c ConsumerConfig, err error) {
 var (
 	errEmptyGroupID                 = errors.New("group.id is empty")
 	errInvallidOffsetsStorageConfig = errors.New("offsets.storage must be 0 or 1")
+	errInvalidIsolationLevel        = errors.New("isolation.level must be read_uncommitted or read_committed")
+	errInvalidLogTruncationReset    = errors.New("log.truncation.reset must be divergence, earliest, latest or none")
+	errInvalidStartOrStopPosition   = errors.New("start.from.timestamp, stop.at.timestamp and stop.at.offset must not be negative")
+)
+
+// values of log.truncation.reset
+const (
+	LogTruncationResetDivergence = "divergence"
+	LogTruncationResetEarliest   = "earliest"
+	LogTruncationResetLatest     = "latest"
+	LogTruncationResetNone       = "none"
+)
+
+// values of partition.assignment.strategy
+const (
+	AssignmentStrategyRange             = "range"
+	AssignmentStrategyRoundRobin        = "roundrobin"
+	AssignmentStrategySticky            = "sticky"
+	AssignmentStrategyCooperativeSticky = "cooperative-sticky"
 )
 
 func (config *ConsumerConfig) checkValid() error {
@@ -176,9 +248,31 @@ func (config *ConsumerConfig) checkValid() error {
 	if config.OffsetsStorage != 0 && config.OffsetsStorage != 1 {
 		return errInvallidOffsetsStorageConfig
 	}
+	if config.IsolationLevel != "" && config.IsolationLevel != "read_uncommitted" && config.IsolationLevel != "read_committed" {
+		return errInvalidIsolationLevel
+	}
+	switch config.LogTruncationReset {
+	case "", LogTruncationResetDivergence, LogTruncationResetEarliest, LogTruncationResetLatest, LogTruncationResetNone:
+	default:
+		return errInvalidLogTruncationReset
+	}
+	if _, err := getAssignmentStrategies(config.PartitionAssignmentStrategy); err != nil {
+		return err
+	}
+	if config.StartFromTimestamp < 0 || config.StopAtTimestamp < 0 || config.StopAtOffset < 0 {
+		return errInvalidStartOrStopPosition
+	}
 	return nil
 }
 
+// isolationLevel returns the isolation_level used in fetch request, read_uncommitted if not set
+func (config *ConsumerConfig) isolationLevel() int8 {
+	if config.IsolationLevel == "read_committed" {
+		return IsolationLevelReadCommitted
+	}
+	return IsolationLevelReadUncommitted
+}
+
 // ProducerConfig is the config for producer
 type ProducerConfig struct {
 	Net                      NetConfig  `json:"net" mapstructure:"net"`
@@ -187,6 +281,7 @@ type ProducerConfig struct {
 	ClientID                 string     `json:"client.id" mapstructure:"client.id"`
 	Acks                     int16      `json:"acks,string" mapstructure:"acks"`
 	CompressionType          string     `json:"compress.type" mapstructure:"compress.type"`
+	CompressionLevel         int        `json:"compression.level,string" mapstructure:"compression.level"` // 0 means the default level of compress.type
 	BatchSize                int        `json:"batch.size,string" mapstructure:"batch.size"`
 	MessageMaxCount          int        `json:"message.max.count,string" mapstructure:"message.max.count"`
 	FlushIntervalMS          int        `json:"flush.interval.ms,string" mapstructure:"flush.interval.ms,string"`
@@ -200,13 +295,25 @@ type ProducerConfig struct {
 	TLSEnabled bool       `json:"tls.enabled,string" mapstructure:"tls.enabled"`
 	TLS        *TLSConfig `json:"tls" mapstructure:"tls"`
 
-	// TODO
+	MaxInFlightRequestsPerConnection int `json:"max.in.flight.requests.per.connection,string" mapstructure:"max.in.flight.requests.per.connection"`
+
+	// Retries is the max retry times of a failed produce request, only retriable errors are retried.
+	// Messages may be duplicated by retrying unless EnableIdempotence is true, it is 5 by default if EnableIdempotence is true
 	Retries          int   `json:"retries,string" mapstructure:"retries"`
 	RequestTimeoutMS int32 `json:"request.timeout.ms,string" mapstructure:"request.timeout.ms"`
 
-	// producer.AddMessage will use this config to assemble Message
-	// only 0 and 1 is implemented for now
+	// producer.AddMessage will use this config to assemble legacy MessageSet if the broker does not support Produce v3.
+	// only 0 and 1 is valid for MessageSet, 2 falls back to 1. RecordBatch (magic 2) is always used if the broker supports Produce v3+
 	HealerMagicByte int `json:"healer.magicbyte,string" mapstructure:"healer.magicbyte"`
+
+	// EnableIdempotence makes producer get a producer id by InitProducerId and send sequence number with each RecordBatch,
+	// so that broker drops the duplicate batches. Acks must be -1 (it is -1 by default then) and the broker must support Produce v3+
+	EnableIdempotence bool `json:"enable.idempotence,string" mapstructure:"enable.idempotence"`
+
+	// TransactionalID makes Producer transactional, messages must be added between BeginTransaction and CommitTransaction/AbortTransaction.
+	// EnableIdempotence is always true if TransactionalID is set
+	TransactionalID      string `json:"transactional.id" mapstructure:"transactional.id"`
+	TransactionTimeoutMS int32  `json:"transaction.timeout.ms,string" mapstructure:"transaction.timeout.ms"`
 }
 
 // DefaultProducerConfig returns a default ProducerConfig
@@ -233,6 +340,8 @@ func DefaultProducerConfig() ProducerConfig {
 
 		Retries:          0,
 		RequestTimeoutMS: 30000,
+
+		TransactionTimeoutMS: 60000,
 	}
 }
 
@@ -241,10 +350,12 @@ var defaultProducerConfig = DefaultProducerConfig()
 // create ProducerConfig from map or return directly if config is ProducerConfig
 // return defaultProducerConfig if config is nil
 func createProducerConfig(config interface{}) (c ProducerConfig, err error) {
+	var m map[string]interface{}
 	switch v := config.(type) {
 	case nil:
 		return defaultProducerConfig, nil
 	case map[string]interface{}:
+		m = v
 		c = defaultProducerConfig
 		if err := mapstructure.WeakDecode(config, &c); err != nil {
 			return defaultProducerConfig, fmt.Errorf("decode producer config error: %w", err)
@@ -254,15 +365,45 @@ func createProducerConfig(config interface{}) (c ProducerConfig, err error) {
 	default:
 		return c, fmt.Errorf("producer only accept config from map[string]interface{} or ProducerConfig")
 	}
+	if c.TransactionalID != "" {
+		c.EnableIdempotence = true
+	}
+	if c.EnableIdempotence {
+		c.setIdempotenceDefaults(m)
+	}
 	err = c.checkValid()
 	return c, err
 }
 
+// retries of idempotent producer if retries is not set, so that timeouts are retried
+const idempotenceRetries = 5
+
+// setIdempotenceDefaults changes the defaults which do not fit idempotence. options set in the map config are kept,
+// and fields of ProducerConfig are kept if they differ from DefaultProducerConfig (m is nil then)
+func (c *ProducerConfig) setIdempotenceDefaults(m map[string]interface{}) {
+	notSet := func(key string, isDefault bool) bool {
+		if m == nil {
+			return isDefault
+		}
+		_, ok := m[key]
+		return !ok
+	}
+	if notSet("retries", c.Retries == defaultProducerConfig.Retries) {
+		c.Retries = idempotenceRetries
+	}
+	if notSet("acks", c.Acks == defaultProducerConfig.Acks) {
+		c.Acks = -1
+	}
+}
+
 var (
-	errMessageMaxCount        = errors.New("message.max.count must > 0")
-	errFlushIntervalMS        = errors.New("flush.interval.ms must > 0")
-	errUnknownCompressionType = errors.New("unknown compression type")
-	errBootstrapServersNotSet = errors.New("bootstrap servers not set")
+	errMessageMaxCount         = errors.New("message.max.count must > 0")
+	errFlushIntervalMS         = errors.New("flush.interval.ms must > 0")
+	errUnknownCompressionType  = errors.New("unknown compression type")
+	errCompressionLevel        = errors.New("invalid compression.level for compress.type")
+	errBootstrapServersNotSet  = errors.New("bootstrap servers not set")
+	errIdempotenceNeedsAcksAll = errors.New("acks must be -1 if enable.idempotence is true")
+	errTransactionTimeoutMS    = errors.New("transaction.timeout.ms must > 0")
 )
 
 func (config *ProducerConfig) checkValid() error {
@@ -275,12 +416,28 @@ func (config *ProducerConfig) checkValid() error {
 	if config.FlushIntervalMS <= 0 {
 		return errFlushIntervalMS
 	}
+	if config.EnableIdempotence && config.Acks != -1 {
+		return errIdempotenceNeedsAcksAll
+	}
+	if config.TransactionalID != "" && config.TransactionTimeoutMS <= 0 {
+		return errTransactionTimeoutMS
+	}
 
 	switch config.CompressionType {
 	case "none":
 	case "gzip":
+		if config.CompressionLevel < 0 || config.CompressionLevel > 9 {
+			return errCompressionLevel
+		}
 	case "snappy":
 	case "lz4":
+		if config.CompressionLevel < 0 || config.CompressionLevel > 17 {
+			return errCompressionLevel
+		}
+	case "zstd":
+		if config.CompressionLevel < 0 || config.CompressionLevel > 22 {
+			return errCompressionLevel
+		}
 	default:
 		return errUnknownCompressionType
 	}
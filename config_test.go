@@ -55,3 +55,122 @@ func TestCreateConsumerConfig(t *testing.T) {
 		convey.So(defaultConsumerConfig.BootstrapServers, convey.ShouldEqual, "")
 	})
 }
+
+func TestProducerConfigCompression(t *testing.T) {
+	convey.Convey("compress.type and compression.level", t, func() {
+		for _, c := range []struct {
+			compressionType  string
+			compressionLevel int
+			err              error
+		}{
+			{"none", 0, nil},
+			{"gzip", 9, nil},
+			{"gzip", 10, errCompressionLevel},
+			{"lz4", 17, nil},
+			{"zstd", 0, nil},
+			{"zstd", 22, nil},
+			{"zstd", 23, errCompressionLevel},
+			{"zstd", -1, errCompressionLevel},
+			{"brotli", 0, errUnknownCompressionType},
+		} {
+			config := DefaultProducerConfig()
+			config.BootstrapServers = "localhost:9092"
+			config.CompressionType = c.compressionType
+			config.CompressionLevel = c.compressionLevel
+			convey.So(config.checkValid(), convey.ShouldEqual, c.err)
+		}
+	})
+}
+
+func TestConsumerConfigIsolationLevel(t *testing.T) {
+	convey.Convey("isolation.level", t, func() {
+		for _, c := range []struct {
+			isolationLevel string
+			value          int8
+			err            error
+		}{
+			{"", IsolationLevelReadUncommitted, nil},
+			{"read_uncommitted", IsolationLevelReadUncommitted, nil},
+			{"read_committed", IsolationLevelReadCommitted, nil},
+			{"committed", IsolationLevelReadUncommitted, errInvalidIsolationLevel},
+		} {
+			config, err := createConsumerConfig(map[string]interface{}{
+				"bootstrap.servers": "localhost:9092",
+				"group.id":          "test",
+				"isolation.level":   c.isolationLevel,
+			})
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(config.checkValid(), convey.ShouldEqual, c.err)
+			convey.So(config.isolationLevel(), convey.ShouldEqual, c.value)
+		}
+	})
+}
+
+func TestConsumerConfigStartAndStopPosition(t *testing.T) {
+	convey.Convey("start.from.timestamp, stop.at.timestamp and stop.at.offset", t, func() {
+		for _, c := range []struct {
+			config map[string]interface{}
+			err    error
+		}{
+			{map[string]interface{}{}, nil},
+			{map[string]interface{}{"start.from.timestamp": "1791936000000", "stop.at.timestamp": 1791939600000, "stop.at.offset": "100"}, nil},
+			{map[string]interface{}{"start.from.timestamp": -1}, errInvalidStartOrStopPosition},
+			{map[string]interface{}{"stop.at.offset": -1}, errInvalidStartOrStopPosition},
+		} {
+			c.config["bootstrap.servers"] = "localhost:9092"
+			c.config["group.id"] = "test"
+			config, err := createConsumerConfig(c.config)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(config.checkValid(), convey.ShouldEqual, c.err)
+		}
+
+		config, _ := createConsumerConfig(map[string]interface{}{"start.from.timestamp": "1791936000000", "stop.at.offset": "100"})
+		convey.So(config.StartFromTimestamp, convey.ShouldEqual, 1791936000000)
+		convey.So(config.StopAtOffset, convey.ShouldEqual, 100)
+		convey.So(config.StopAtTimestamp, convey.ShouldEqual, 0)
+	})
+}
+
+func TestProducerConfigIdempotenceDefaults(t *testing.T) {
+	convey.Convey("retries is positive by default if idempotence is enabled", t, func() {
+		c, err := createProducerConfig(map[string]interface{}{"bootstrap.servers": "localhost:9092", "acks": -1, "enable.idempotence": true})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.Retries, convey.ShouldEqual, idempotenceRetries)
+
+		c, err = createProducerConfig(map[string]interface{}{"bootstrap.servers": "localhost:9092", "acks": -1, "enable.idempotence": true, "retries": 0})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.Retries, convey.ShouldEqual, 0)
+
+		c, err = createProducerConfig(map[string]interface{}{"bootstrap.servers": "localhost:9092"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.Retries, convey.ShouldEqual, 0)
+	})
+}
+
+func TestProducerConfigTransactionalAcks(t *testing.T) {
+	convey.Convey("acks is -1 by default if transactional.id is set", t, func() {
+		c, err := createProducerConfig(map[string]interface{}{"bootstrap.servers": "localhost:9092", "transactional.id": "txn"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.EnableIdempotence, convey.ShouldBeTrue)
+		convey.So(c.Acks, convey.ShouldEqual, -1)
+
+		config := DefaultProducerConfig()
+		config.BootstrapServers = "localhost:9092"
+		config.TransactionalID = "txn"
+		c, err = createProducerConfig(config)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.Acks, convey.ShouldEqual, -1)
+	})
+
+	convey.Convey("explicit acks conflicting with idempotence is rejected", t, func() {
+		_, err := createProducerConfig(map[string]interface{}{"bootstrap.servers": "localhost:9092", "transactional.id": "txn", "acks": 1})
+		convey.So(err, convey.ShouldEqual, errIdempotenceNeedsAcksAll)
+
+		config := DefaultProducerConfig()
+		config.BootstrapServers = "localhost:9092"
+		config.EnableIdempotence = true
+		config.Acks = 0
+		_, err = createProducerConfig(config)
+		convey.So(err, convey.ShouldEqual, errIdempotenceNeedsAcksAll)
+	})
+}
@@ -1,6 +1,8 @@
 package healer
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -137,6 +139,52 @@ func (c *Consumer) Consume(messageChan chan *FullMessage) (<-chan *FullMessage,
 	return messages, nil
 }
 
+// Commit commits offsets synchronously, see SimpleConsumer.Commit. group.id must be set
+func (c *Consumer) Commit(ctx context.Context, offsets map[string]map[int32]int64) error {
+	return commitSimpleConsumers(ctx, c.simpleConsumers, offsets)
+}
+
+// Ack acknowledges that the message is processed, see SimpleConsumer.Ack
+func (c *Consumer) Ack(msg *FullMessage) {
+	if s := findSimpleConsumer(c.simpleConsumers, msg.TopicName, msg.PartitionID); s != nil {
+		s.Ack(msg)
+	}
+}
+
+// Pause stops fetching the partitions until they are resumed, see SimpleConsumer.Pause
+func (c *Consumer) Pause(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.simpleConsumers, partitions, (*SimpleConsumer).Pause)
+}
+
+// Resume continues fetching the paused partitions
+func (c *Consumer) Resume(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.simpleConsumers, partitions, (*SimpleConsumer).Resume)
+}
+
+// SeekToOffset moves the position of the partition to offset, see SimpleConsumer.SeekToOffset
+func (c *Consumer) SeekToOffset(topic string, partitionID int32, offset int64) error {
+	s := findSimpleConsumer(c.simpleConsumers, topic, partitionID)
+	if s == nil {
+		return fmt.Errorf("%w: %s-%d", errPartitionNotConsumed, topic, partitionID)
+	}
+	return s.SeekToOffset(offset)
+}
+
+// SeekToBeginning moves the positions of the partitions to the earliest offsets
+func (c *Consumer) SeekToBeginning(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.simpleConsumers, partitions, (*SimpleConsumer).SeekToBeginning)
+}
+
+// SeekToEnd moves the positions of the partitions to the latest offsets
+func (c *Consumer) SeekToEnd(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.simpleConsumers, partitions, (*SimpleConsumer).SeekToEnd)
+}
+
+// SeekToTimestamp moves the positions of the partitions to the offsets of the timestamp(ms), see SimpleConsumer.SeekToTimestamp
+func (c *Consumer) SeekToTimestamp(partitions []*PartitionAssignment, timestamp int64) error {
+	return forEachPartition(c.simpleConsumers, partitions, func(s *SimpleConsumer) { s.SeekToTimestamp(timestamp) })
+}
+
 func (c *Consumer) stop() {
 	c.closed = true
 	if c.simpleConsumers != nil {
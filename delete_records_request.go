@@ -0,0 +1,124 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+DeleteRecords Request (Version: 1) => [topics] timeout_ms
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index offset
+      partition_index => INT32
+      offset => INT64
+  timeout_ms => INT32
+
+v1 is the same as v0
+*/
+
+// DeleteRecordsRequest deletes the records before the offsets, it must be sent to the leaders of the partitions
+type DeleteRecordsRequest struct {
+	*RequestHeader
+	Topics    []DeleteRecordsTopic
+	TimeoutMS int32
+}
+
+// DeleteRecordsTopic is the partitions of one topic in DeleteRecordsRequest
+type DeleteRecordsTopic struct {
+	Name       string
+	Partitions []DeleteRecordsPartition
+}
+
+// DeleteRecordsPartition is one partition in DeleteRecordsRequest.
+// records before Offset are deleted, -1 means the high watermark
+type DeleteRecordsPartition struct {
+	PartitionIndex int32
+	Offset         int64
+}
+
+// NewDeleteRecordsRequest creates a new DeleteRecordsRequest without partitions
+func NewDeleteRecordsRequest(clientID string, timeoutMS int32) *DeleteRecordsRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_DeleteRecords,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &DeleteRecordsRequest{
+		RequestHeader: requestHeader,
+		Topics:        make([]DeleteRecordsTopic, 0),
+		TimeoutMS:     timeoutMS,
+	}
+}
+
+// AddPartition adds a partition to the request
+func (r *DeleteRecordsRequest) AddPartition(topic string, partition int32, offset int64) {
+	p := DeleteRecordsPartition{PartitionIndex: partition, Offset: offset}
+	for i := range r.Topics {
+		if r.Topics[i].Name == topic {
+			r.Topics[i].Partitions = append(r.Topics[i].Partitions, p)
+			return
+		}
+	}
+	r.Topics = append(r.Topics, DeleteRecordsTopic{Name: topic, Partitions: []DeleteRecordsPartition{p}})
+}
+
+// Encode encodes DeleteRecordsRequest to []byte
+func (r *DeleteRecordsRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.PartitionIndex)
+			binary.Write(buf, binary.BigEndian, partition.Offset)
+		}
+	}
+	binary.Write(buf, binary.BigEndian, r.TimeoutMS)
+
+	return buf.Bytes()
+}
+
+// DecodeDeleteRecordsRequest decodes []byte to DeleteRecordsRequest, just for test
+func DecodeDeleteRecordsRequest(payload []byte, version uint16) (r DeleteRecordsRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]DeleteRecordsTopic, topicCount)
+	for i := range r.Topics {
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]DeleteRecordsPartition, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			p := &r.Topics[i].Partitions[j]
+			p.PartitionIndex = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			p.Offset = int64(binary.BigEndian.Uint64(payload[offset:]))
+			offset += 8
+		}
+	}
+	r.TimeoutMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+
+	return r, nil
+}
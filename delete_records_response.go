@@ -0,0 +1,119 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+DeleteRecords Response (Version: 1) => throttle_time_ms [topics]
+  throttle_time_ms => INT32
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index low_watermark error_code
+      partition_index => INT32
+      low_watermark => INT64
+      error_code => INT16
+
+v1 is the same as v0
+*/
+
+// DeleteRecordsResponse is the response of DeleteRecordsRequest
+type DeleteRecordsResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	Topics         []DeleteRecordsTopicResult
+}
+
+// DeleteRecordsTopicResult is the result of one topic in DeleteRecordsResponse
+type DeleteRecordsTopicResult struct {
+	Name       string
+	Partitions []DeleteRecordsPartitionResult
+}
+
+// DeleteRecordsPartitionResult is the result of one partition, LowWatermark is the new log start offset
+type DeleteRecordsPartitionResult struct {
+	PartitionIndex int32
+	LowWatermark   int64
+	ErrorCode      int16
+}
+
+// Error returns the first error in the partitions
+func (r DeleteRecordsResponse) Error() error {
+	for _, topic := range r.Topics {
+		for _, partition := range topic.Partitions {
+			if partition.ErrorCode != 0 {
+				return fmt.Errorf("delete records of %s-%d error: %w", topic.Name, partition.PartitionIndex, KafkaError(partition.ErrorCode))
+			}
+		}
+	}
+	return nil
+}
+
+// NewDeleteRecordsResponse creates a new DeleteRecordsResponse from []byte
+func NewDeleteRecordsResponse(payload []byte, version uint16) (r DeleteRecordsResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("DeleteRecords response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]DeleteRecordsTopicResult, topicCount)
+	for i := range r.Topics {
+		var o int
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]DeleteRecordsPartitionResult, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			p := &r.Topics[i].Partitions[j]
+			p.PartitionIndex = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			p.LowWatermark = int64(binary.BigEndian.Uint64(payload[offset:]))
+			offset += 8
+			p.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+		}
+	}
+
+	return r, nil
+}
+
+// Encode encodes DeleteRecordsResponse to []byte, just for test
+func (r DeleteRecordsResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.PartitionIndex)
+			binary.Write(buf, binary.BigEndian, partition.LowWatermark)
+			binary.Write(buf, binary.BigEndian, partition.ErrorCode)
+		}
+	}
+
+	return buf.Bytes()
+}
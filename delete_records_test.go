@@ -0,0 +1,45 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeleteRecordsEncodeDecode(t *testing.T) {
+	convey.Convey("Test DeleteRecords Request and Response Encode and Decode", t, func() {
+		for _, version := range availableVersions[API_DeleteRecords] {
+			t.Logf("version: %v", version)
+
+			request := NewDeleteRecordsRequest("healer", 30000)
+			request.APIVersion = version
+			request.AddPartition("topic-1", 0, 100)
+			request.AddPartition("topic-1", 1, -1)
+			request.AddPartition("topic-2", 0, 5)
+			convey.So(len(request.Topics), convey.ShouldEqual, 2)
+
+			decodedRequest, err := DecodeDeleteRecordsRequest(request.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+			response := DeleteRecordsResponse{
+				CorrelationID:  1,
+				ThrottleTimeMS: 10,
+				Topics: []DeleteRecordsTopicResult{
+					{
+						Name: "topic-1",
+						Partitions: []DeleteRecordsPartitionResult{
+							{PartitionIndex: 0, LowWatermark: 100, ErrorCode: 0},
+							{PartitionIndex: 1, LowWatermark: -1, ErrorCode: 1},
+						},
+					},
+				},
+			}
+			decodedResponse, err := NewDeleteRecordsResponse(response.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(decodedResponse, convey.ShouldResemble, response)
+			convey.So(errors.Is(decodedResponse.Error(), KafkaError(1)), convey.ShouldBeTrue)
+		}
+	})
+}
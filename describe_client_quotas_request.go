@@ -0,0 +1,91 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+DescribeClientQuotas Request (Version: 0) => [components] strict
+  components => entity_type match_type match
+    entity_type => STRING
+    match_type => INT8
+    match => NULLABLE_STRING
+  strict => BOOLEAN
+*/
+
+// DescribeClientQuotasRequest describes the quotas of the entities matching all the components.
+// if Strict is true, entities with other entity types than the components are not returned
+type DescribeClientQuotasRequest struct {
+	*RequestHeader
+	Components []QuotaFilterComponent
+	Strict     bool
+}
+
+// NewDescribeClientQuotasRequest creates a new DescribeClientQuotasRequest
+func NewDescribeClientQuotasRequest(clientID string, components []QuotaFilterComponent, strict bool) *DescribeClientQuotasRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_DescribeClientQuotas,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	if components == nil {
+		components = make([]QuotaFilterComponent, 0)
+	}
+	return &DescribeClientQuotasRequest{
+		RequestHeader: requestHeader,
+		Components:    components,
+		Strict:        strict,
+	}
+}
+
+// Encode encodes DescribeClientQuotasRequest to []byte
+func (r *DescribeClientQuotasRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Components)))
+	for _, c := range r.Components {
+		writeString(buf, c.EntityType)
+		binary.Write(buf, binary.BigEndian, c.MatchType)
+		writeNullableString(buf, c.Match)
+	}
+	binary.Write(buf, binary.BigEndian, r.Strict)
+
+	return buf.Bytes()
+}
+
+// DecodeDescribeClientQuotasRequest decodes []byte to DescribeClientQuotasRequest, just for test
+func DecodeDescribeClientQuotasRequest(payload []byte, version uint16) (r DescribeClientQuotasRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	componentCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Components = make([]QuotaFilterComponent, componentCount)
+	for i := range r.Components {
+		c := &r.Components[i]
+		c.EntityType, o = nonnullableString(payload[offset:])
+		offset += o
+		c.MatchType = QuotaMatchType(payload[offset])
+		offset++
+		c.Match, o = nullableString(payload[offset:])
+		offset += o
+	}
+	r.Strict = payload[offset] != 0
+
+	return r, nil
+}
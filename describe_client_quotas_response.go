@@ -0,0 +1,135 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+DescribeClientQuotas Response (Version: 0) => throttle_time_ms error_code error_message [entries]
+  throttle_time_ms => INT32
+  error_code => INT16
+  error_message => NULLABLE_STRING
+  entries => [entity] [values]
+    entity => entity_type entity_name
+      entity_type => STRING
+      entity_name => NULLABLE_STRING
+    values => key value
+      key => STRING
+      value => FLOAT64
+
+entries is nullable, it is null if the request failed
+*/
+
+// DescribeClientQuotasResponse is the response of DescribeClientQuotasRequest
+type DescribeClientQuotasResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	ErrorCode      int16
+	ErrorMessage   *string
+	Entries        []DescribeClientQuotasEntry
+}
+
+// DescribeClientQuotasEntry is the quotas of one entity, Values is quota key -> value
+type DescribeClientQuotasEntry struct {
+	Entity []QuotaEntity
+	Values map[string]float64
+}
+
+// Error returns the error of the response
+func (r DescribeClientQuotasResponse) Error() error {
+	if r.ErrorCode == 0 {
+		return nil
+	}
+	if r.ErrorMessage != nil {
+		return fmt.Errorf("describe client quotas error: %w: %s", KafkaError(r.ErrorCode), *r.ErrorMessage)
+	}
+	return fmt.Errorf("describe client quotas error: %w", KafkaError(r.ErrorCode))
+}
+
+// NewDescribeClientQuotasResponse creates a new DescribeClientQuotasResponse from []byte
+func NewDescribeClientQuotasResponse(payload []byte, version uint16) (r DescribeClientQuotasResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("DescribeClientQuotas response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	var o int
+	r.ErrorMessage, o = nullableString(payload[offset:])
+	offset += o
+
+	entryCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	if entryCount < 0 {
+		return r, nil
+	}
+	r.Entries = make([]DescribeClientQuotasEntry, entryCount)
+	for i := range r.Entries {
+		r.Entries[i].Entity, o = decodeQuotaEntity(payload[offset:])
+		offset += o
+
+		valueCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Entries[i].Values = make(map[string]float64, valueCount)
+		for j := int32(0); j < valueCount; j++ {
+			key, o := nonnullableString(payload[offset:])
+			offset += o
+			r.Entries[i].Values[key] = math.Float64frombits(binary.BigEndian.Uint64(payload[offset:]))
+			offset += 8
+		}
+	}
+
+	return r, nil
+}
+
+// Encode encodes DescribeClientQuotasResponse to []byte, just for test
+func (r DescribeClientQuotasResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+	binary.Write(buf, binary.BigEndian, r.ErrorCode)
+	writeNullableString(buf, r.ErrorMessage)
+
+	if r.Entries == nil {
+		binary.Write(buf, binary.BigEndian, int32(-1))
+		return buf.Bytes()
+	}
+	binary.Write(buf, binary.BigEndian, int32(len(r.Entries)))
+	for _, entry := range r.Entries {
+		writeQuotaEntity(buf, entry.Entity)
+
+		keys := make([]string, 0, len(entry.Values))
+		for k := range entry.Values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		binary.Write(buf, binary.BigEndian, int32(len(keys)))
+		for _, k := range keys {
+			writeString(buf, k)
+			binary.Write(buf, binary.BigEndian, entry.Values[k])
+		}
+	}
+
+	return buf.Bytes()
+}
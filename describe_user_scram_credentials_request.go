@@ -0,0 +1,135 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+/*
+DescribeUserScramCredentials Request (Version: 0) => [users] TAG_BUFFER
+  users => name TAG_BUFFER
+    name => COMPACT_STRING
+
+users is nullable, credentials of all users are described if it is null
+*/
+
+// ScramMechanismType is the SCRAM mechanism in DescribeUserScramCredentials and AlterUserScramCredentials
+type ScramMechanismType int8
+
+const (
+	ScramMechanismTypeUnknown ScramMechanismType = 0
+	ScramMechanismTypeSHA256  ScramMechanismType = 1
+	ScramMechanismTypeSHA512  ScramMechanismType = 2
+)
+
+func (t ScramMechanismType) String() string {
+	switch t {
+	case ScramMechanismTypeSHA256:
+		return ScramSHA256.Name
+	case ScramMechanismTypeSHA512:
+		return ScramSHA512.Name
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func (t ScramMechanismType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *ScramMechanismType) UnmarshalText(text []byte) error {
+	switch strings.ToUpper(string(text)) {
+	case ScramSHA256.Name, "1":
+		*t = ScramMechanismTypeSHA256
+	case ScramSHA512.Name, "2":
+		*t = ScramMechanismTypeSHA512
+	default:
+		return fmt.Errorf("unknown ScramMechanismType: %s", text)
+	}
+	return nil
+}
+
+// Mechanism returns the ScramMechanism to salt the password
+func (t ScramMechanismType) Mechanism() (ScramMechanism, error) {
+	switch t {
+	case ScramMechanismTypeSHA256:
+		return ScramSHA256, nil
+	case ScramMechanismTypeSHA512:
+		return ScramSHA512, nil
+	}
+	return ScramMechanism{}, fmt.Errorf("unknown scram mechanism type: %d", t)
+}
+
+// DescribeUserScramCredentialsRequest describes the SCRAM credentials of the users
+type DescribeUserScramCredentialsRequest struct {
+	*RequestHeader
+	Users []string
+}
+
+// NewDescribeUserScramCredentialsRequest creates a new DescribeUserScramCredentialsRequest, all users are described if users is nil
+func NewDescribeUserScramCredentialsRequest(clientID string, users []string) *DescribeUserScramCredentialsRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_DescribeUserScramCredentials,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &DescribeUserScramCredentialsRequest{
+		RequestHeader: requestHeader,
+		Users:         users,
+	}
+}
+
+// Encode encodes DescribeUserScramCredentialsRequest to []byte
+func (r *DescribeUserScramCredentialsRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	if r.Users == nil {
+		buf.Write(encodeCompactArrayLength(-1))
+	} else {
+		buf.Write(encodeCompactArrayLength(len(r.Users)))
+	}
+	for _, user := range r.Users {
+		writeCompactString(buf, user)
+		buf.Write(TaggedFields(nil).Encode())
+	}
+	buf.Write(TaggedFields(nil).Encode())
+
+	return buf.Bytes()
+}
+
+// DecodeDescribeUserScramCredentialsRequest decodes []byte to DescribeUserScramCredentialsRequest, just for test
+func DecodeDescribeUserScramCredentialsRequest(payload []byte, version uint16) (r DescribeUserScramCredentialsRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	userCount, o := compactArrayLength(payload[offset:])
+	offset += o
+	if userCount < 0 {
+		return r, nil
+	}
+	r.Users = make([]string, userCount)
+	for i := range r.Users {
+		r.Users[i], o = compactString(payload[offset:])
+		offset += o
+		_, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	return r, nil
+}
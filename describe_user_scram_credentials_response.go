@@ -0,0 +1,166 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+DescribeUserScramCredentials Response (Version: 0) => throttle_time_ms error_code error_message [results] TAG_BUFFER
+  throttle_time_ms => INT32
+  error_code => INT16
+  error_message => COMPACT_NULLABLE_STRING
+  results => user error_code error_message [credential_infos] TAG_BUFFER
+    user => COMPACT_STRING
+    error_code => INT16
+    error_message => COMPACT_NULLABLE_STRING
+    credential_infos => mechanism iterations TAG_BUFFER
+      mechanism => INT8
+      iterations => INT32
+*/
+
+// DescribeUserScramCredentialsResponse is the response of DescribeUserScramCredentialsRequest
+type DescribeUserScramCredentialsResponse struct {
+	ResponseHeader
+	ThrottleTimeMS int32                                `json:"throttle_time_ms"`
+	ErrorCode      int16                                `json:"error_code"`
+	ErrorMessage   *string                              `json:"error_message"`
+	Results        []DescribeUserScramCredentialsResult `json:"results"`
+
+	TaggedFields TaggedFields `json:"tagged_fields"`
+}
+
+// DescribeUserScramCredentialsResult is the credentials of one user
+type DescribeUserScramCredentialsResult struct {
+	User            string                `json:"user"`
+	ErrorCode       int16                 `json:"error_code"`
+	ErrorMessage    *string               `json:"error_message"`
+	CredentialInfos []ScramCredentialInfo `json:"credential_infos"`
+
+	TaggedFields TaggedFields `json:"tagged_fields"`
+}
+
+// ScramCredentialInfo is the mechanism and iterations of one credential, the salt and password are never returned
+type ScramCredentialInfo struct {
+	Mechanism  ScramMechanismType `json:"mechanism"`
+	Iterations int32              `json:"iterations"`
+
+	TaggedFields TaggedFields `json:"tagged_fields"`
+}
+
+// Error returns the error of the response, or the first error of the users
+func (r DescribeUserScramCredentialsResponse) Error() error {
+	if r.ErrorCode != 0 {
+		return fmt.Errorf("describe user scram credentials error: %w: %s", KafkaError(r.ErrorCode), stringOrEmpty(r.ErrorMessage))
+	}
+	for _, result := range r.Results {
+		if result.ErrorCode != 0 {
+			return fmt.Errorf("describe scram credentials of %s error: %w: %s", result.User, KafkaError(result.ErrorCode), stringOrEmpty(result.ErrorMessage))
+		}
+	}
+	return nil
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// NewDescribeUserScramCredentialsResponse creates a new DescribeUserScramCredentialsResponse from []byte
+func NewDescribeUserScramCredentialsResponse(payload []byte, version uint16) (r DescribeUserScramCredentialsResponse, err error) {
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("DescribeUserScramCredentials response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset := 4
+
+	header, o := DecodeResponseHeader(payload[offset:], API_DescribeUserScramCredentials, version)
+	offset += o
+	r.ResponseHeader = header
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	r.ErrorMessage, o = compactNullableString(payload[offset:])
+	offset += o
+
+	resultCount, o := compactArrayLength(payload[offset:])
+	offset += o
+	if resultCount >= 0 {
+		r.Results = make([]DescribeUserScramCredentialsResult, resultCount)
+	}
+	for i := range r.Results {
+		result := &r.Results[i]
+		result.User, o = compactString(payload[offset:])
+		offset += o
+
+		result.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+		offset += 2
+
+		result.ErrorMessage, o = compactNullableString(payload[offset:])
+		offset += o
+
+		infoCount, o := compactArrayLength(payload[offset:])
+		offset += o
+		if infoCount >= 0 {
+			result.CredentialInfos = make([]ScramCredentialInfo, infoCount)
+		}
+		for j := range result.CredentialInfos {
+			info := &result.CredentialInfos[j]
+			info.Mechanism = ScramMechanismType(payload[offset])
+			offset++
+			info.Iterations = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			info.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+			offset += o
+		}
+
+		result.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	r.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+	offset += o
+
+	return r, nil
+}
+
+// Encode encodes DescribeUserScramCredentialsResponse to []byte, just for test
+func (r DescribeUserScramCredentialsResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	buf.Write(r.ResponseHeader.Encode())
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+	binary.Write(buf, binary.BigEndian, r.ErrorCode)
+	writeCompactNullableString(buf, r.ErrorMessage)
+
+	buf.Write(encodeCompactArrayLength(len(r.Results)))
+	for _, result := range r.Results {
+		writeCompactString(buf, result.User)
+		binary.Write(buf, binary.BigEndian, result.ErrorCode)
+		writeCompactNullableString(buf, result.ErrorMessage)
+
+		buf.Write(encodeCompactArrayLength(len(result.CredentialInfos)))
+		for _, info := range result.CredentialInfos {
+			binary.Write(buf, binary.BigEndian, info.Mechanism)
+			binary.Write(buf, binary.BigEndian, info.Iterations)
+			buf.Write(info.TaggedFields.Encode())
+		}
+		buf.Write(result.TaggedFields.Encode())
+	}
+	buf.Write(r.TaggedFields.Encode())
+
+	return buf.Bytes()
+}
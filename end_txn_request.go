@@ -0,0 +1,87 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+EndTxn Request (Version: 0) => transactional_id producer_id producer_epoch committed
+  transactional_id => STRING
+  producer_id => INT64
+  producer_epoch => INT16
+  committed => BOOLEAN
+
+version 1 is the same as version 0
+*/
+
+// EndTxnRequest commits or aborts the transaction
+type EndTxnRequest struct {
+	*RequestHeader
+	TransactionalID string
+	ProducerID      int64
+	ProducerEpoch   int16
+	Committed       bool
+}
+
+// NewEndTxnRequest creates a new EndTxnRequest, committed is false to abort the transaction
+func NewEndTxnRequest(clientID string, transactionalID string, producerID int64, producerEpoch int16, committed bool) *EndTxnRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_EndTxn,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &EndTxnRequest{
+		RequestHeader:   requestHeader,
+		TransactionalID: transactionalID,
+		ProducerID:      producerID,
+		ProducerEpoch:   producerEpoch,
+		Committed:       committed,
+	}
+}
+
+// Encode encodes EndTxnRequest to []byte
+func (r *EndTxnRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	writeString(buf, r.TransactionalID)
+	binary.Write(buf, binary.BigEndian, r.ProducerID)
+	binary.Write(buf, binary.BigEndian, r.ProducerEpoch)
+	binary.Write(buf, binary.BigEndian, r.Committed)
+
+	return buf.Bytes()
+}
+
+// DecodeEndTxnRequest decodes []byte to EndTxnRequest, just for test
+func DecodeEndTxnRequest(payload []byte) (r EndTxnRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	r.TransactionalID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	r.ProducerID = int64(binary.BigEndian.Uint64(payload[offset:]))
+	offset += 8
+
+	r.ProducerEpoch = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	r.Committed = payload[offset] != 0
+	offset++
+
+	return r, nil
+}
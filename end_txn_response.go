@@ -0,0 +1,61 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+EndTxn Response (Version: 0) => throttle_time_ms error_code
+  throttle_time_ms => INT32
+  error_code => INT16
+*/
+
+// EndTxnResponse is the response of EndTxnRequest
+type EndTxnResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	ErrorCode      int16
+}
+
+func (r EndTxnResponse) Error() error {
+	return getErrorFromErrorCode(r.ErrorCode)
+}
+
+// NewEndTxnResponse creates a new EndTxnResponse from []byte
+func NewEndTxnResponse(payload []byte, version uint16) (r EndTxnResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("EndTxn response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+
+	return r, nil
+}
+
+// Encode encodes EndTxnResponse to []byte, just for test
+func (r EndTxnResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+	binary.Write(buf, binary.BigEndian, r.ErrorCode)
+
+	return buf.Bytes()
+}
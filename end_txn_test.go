@@ -0,0 +1,34 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestEndTxnEncodeDecode(t *testing.T) {
+	convey.Convey("Test EndTxn Request and Response Encode and Decode", t, func() {
+		for _, version := range availableVersions[API_EndTxn] {
+			t.Logf("version: %v", version)
+
+			for _, committed := range []bool{true, false} {
+				request := NewEndTxnRequest("healer", "txn-1", 100, 2, committed)
+				request.APIVersion = version
+
+				decodedRequest, err := DecodeEndTxnRequest(request.Encode(version))
+				convey.So(err, convey.ShouldBeNil)
+				convey.So(&decodedRequest, convey.ShouldResemble, request)
+			}
+
+			response := EndTxnResponse{
+				CorrelationID:  1,
+				ThrottleTimeMS: 10,
+				ErrorCode:      48,
+			}
+			decodedResponse, err := NewEndTxnResponse(response.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(decodedResponse, convey.ShouldResemble, response)
+			convey.So(decodedResponse.Error(), convey.ShouldEqual, KafkaError(48))
+		}
+	})
+}
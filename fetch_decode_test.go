@@ -3,6 +3,8 @@ package healer
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"reflect"
 	"testing"
 )
 
@@ -65,7 +67,7 @@ var resp FetchResponse = FetchResponse{
 
 // complete 2 records, no partial data
 func TestFetchResponseDecodeComplete(t *testing.T) {
-	for _, version := range []uint16{7, 10} {
+	for _, version := range []uint16{7, 10, 11} {
 		payload, err := resp.Encode(version)
 		if err != nil {
 			t.Error(err)
@@ -106,7 +108,7 @@ func TestFetchResponseDecodeComplete(t *testing.T) {
 
 // append some bytes to the end of the payload
 func TestFetchResponseDecodeWithPartialRecords1(t *testing.T) {
-	for _, version := range []uint16{7, 10} {
+	for _, version := range []uint16{7, 10, 11} {
 		payload, err := resp.Encode(version)
 		if err != nil {
 			t.Error(err)
@@ -148,7 +150,7 @@ func TestFetchResponseDecodeWithPartialRecords1(t *testing.T) {
 
 // encode 2 records and trancate the last some bytes
 func TestFetchResponseDecodeWithPartialRecords2(t *testing.T) {
-	for _, version := range []uint16{7, 10} {
+	for _, version := range []uint16{7, 10, 11} {
 		payload, err := resp.Encode(version)
 		if err != nil {
 			t.Error(err)
@@ -187,3 +189,141 @@ func TestFetchResponseDecodeWithPartialRecords2(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchResponseDecodePreferredReadReplica(t *testing.T) {
+	for version, expected := range map[uint16]int32{10: -1, 11: 2} {
+		r := resp
+		r.Responses = map[string][]PartitionResponse{"test-topic": {resp.Responses["test-topic"][0]}}
+		r.Responses["test-topic"][0].PreferredReadReplica = 2
+		payload, err := r.Encode(version)
+		if err != nil {
+			t.Error(err)
+		}
+
+		messages := make(chan *FullMessage, 10)
+		decoder := fetchResponseStreamDecoder{
+			ctx:         context.Background(),
+			buffers:     bytes.NewReader(payload),
+			messages:    messages,
+			totalLength: len(payload) + 4,
+			version:     version,
+		}
+		if err := decoder.streamDecode(context.Background(), 0); err != nil {
+			t.Error(err)
+		}
+		close(messages)
+
+		i := 0
+		for msg := range messages {
+			if msg.Error != nil {
+				t.Error(msg.Error)
+			}
+			i++
+		}
+		if i != 2 {
+			t.Errorf("expect 2 message, but got %d", i)
+		}
+		if decoder.preferredReadReplica != expected {
+			t.Errorf("version %d: expect preferred read replica %d, but got %d", version, expected, decoder.preferredReadReplica)
+		}
+	}
+}
+
+// aborted transaction of producer 1, [0,1] records, 2 abort marker
+// committed transaction of producer 2, [3,4] records, 5 commit marker
+func TestFetchResponseDecodeReadCommitted(t *testing.T) {
+	controlRecord := func(controlType byte) []Record {
+		return []Record{
+			{
+				key:     []byte{0, 0, 0, controlType},
+				value:   []byte{0, 0, 0, 0, 0, 0},
+				Headers: []RecordHeader{},
+			},
+		}
+	}
+	dataRecords := func(prefix string) []Record {
+		return []Record{
+			{offsetDelta: 0, key: []byte(prefix + "-1"), value: []byte(prefix + "-1"), Headers: []RecordHeader{}},
+			{offsetDelta: 1, key: []byte(prefix + "-2"), value: []byte(prefix + "-2"), Headers: []RecordHeader{}},
+		}
+	}
+	batches := []RecordBatch{
+		{BaseOffset: 0, Magic: 2, Attributes: batchAttributeTransactional, LastOffsetDelta: 1, ProducerID: 1, Records: dataRecords("aborted")},
+		{BaseOffset: 2, Magic: 2, Attributes: batchAttributeTransactional | batchAttributeControl, ProducerID: 1, Records: controlRecord(0)},
+		{BaseOffset: 3, Magic: 2, Attributes: batchAttributeTransactional, LastOffsetDelta: 1, ProducerID: 2, Records: dataRecords("committed")},
+		{BaseOffset: 5, Magic: 2, Attributes: batchAttributeTransactional | batchAttributeControl, ProducerID: 2, Records: controlRecord(1)},
+	}
+
+	fetchResponse := FetchResponse{
+		Responses: map[string][]PartitionResponse{
+			"test-topic": {
+				{
+					PartitionID:      0,
+					HighWatermark:    6,
+					LastStableOffset: 6,
+					AbortedTransactions: []struct {
+						ProducerID  int64
+						FirstOffset int64
+					}{
+						{ProducerID: 1, FirstOffset: 0},
+					},
+					RecordBatch: batches[0],
+				},
+			},
+		},
+	}
+	payload, err := fetchResponse.Encode(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FetchResponse.Encode supports only one batch, append others and fix the records length
+	first, _ := batches[0].Encode(10)
+	recordsLengthOffset := len(payload) - len(first) - 4
+	recordsLength := len(first)
+	for _, batch := range batches[1:] {
+		b, err := batch.Encode(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload = append(payload, b...)
+		recordsLength += len(b)
+	}
+	binary.BigEndian.PutUint32(payload[recordsLengthOffset:], uint32(recordsLength))
+
+	for _, c := range []struct {
+		isolationLevel int8
+		offsets        []int64
+		skippedOffset  int64
+	}{
+		{IsolationLevelReadUncommitted, []int64{0, 1, 3, 4}, 6},
+		{IsolationLevelReadCommitted, []int64{3, 4}, 6},
+	} {
+		messages := make(chan *FullMessage, 10)
+		decoder := fetchResponseStreamDecoder{
+			ctx:            context.Background(),
+			buffers:        bytes.NewReader(payload),
+			messages:       messages,
+			totalLength:    len(payload) + 4,
+			version:        10,
+			isolationLevel: c.isolationLevel,
+		}
+		if err := decoder.streamDecode(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+		close(messages)
+
+		offsets := make([]int64, 0)
+		for msg := range messages {
+			if msg.Error != nil {
+				t.Fatal(msg.Error)
+			}
+			offsets = append(offsets, msg.Message.Offset)
+		}
+		if !reflect.DeepEqual(offsets, c.offsets) {
+			t.Errorf("isolation level %d: expect offsets %v, but got %v", c.isolationLevel, c.offsets, offsets)
+		}
+		if decoder.skippedOffset != c.skippedOffset {
+			t.Errorf("isolation level %d: expect skipped offset %d, but got %d", c.isolationLevel, c.skippedOffset, decoder.skippedOffset)
+		}
+	}
+}
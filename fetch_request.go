@@ -4,6 +4,12 @@ import (
 	"encoding/binary"
 )
 
+// isolation_level in fetch request
+const (
+	IsolationLevelReadUncommitted int8 = 0
+	IsolationLevelReadCommitted   int8 = 1
+)
+
 // PartitionBlock is the partition to fetch.
 type PartitionBlock struct {
 	Partition          int32
@@ -25,13 +31,15 @@ type FetchRequest struct {
 	SessionEpoch         int32
 	Topics               map[string][]*PartitionBlock
 	ForgottenTopicsDatas map[string][]int32
+	// RackID is the rack of the consumer, the broker may choose a replica in the same rack as the preferred read replica. v11+
+	RackID string
 }
 
 // NewFetchRequest creates a new FetchRequest
 func NewFetchRequest(clientID string, maxWaitTime int32, minBytes int32) *FetchRequest {
 	requestHeader := &RequestHeader{
 		APIKey:     API_FetchRequest,
-		APIVersion: 10,
+		APIVersion: 11,
 		ClientID:   &clientID,
 	}
 
@@ -73,6 +81,7 @@ func (fetchRequest *FetchRequest) length(version uint16) int {
 	for topicname, partitionIDs := range fetchRequest.ForgottenTopicsDatas {
 		length += 2 + len(topicname) + 4 + len(partitionIDs)*4
 	}
+	length += 2 + len(fetchRequest.RackID)
 
 	return length * 2
 }
@@ -153,6 +162,12 @@ func (fetchRequest *FetchRequest) Encode(version uint16) []byte {
 		}
 	}
 
+	if version >= 11 {
+		binary.BigEndian.PutUint16(payload[offset:], uint16(len(fetchRequest.RackID)))
+		offset += 2
+		offset += copy(payload[offset:], fetchRequest.RackID)
+	}
+
 	binary.BigEndian.PutUint32(payload, uint32(offset-4))
 	return payload[:offset]
 }
@@ -0,0 +1,27 @@
+package healer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFetchRequestRackID(t *testing.T) {
+	r := NewFetchRequest("healer", 500, 1)
+	r.RackID = "rack-a"
+	r.addPartition("test-topic", 0, 100, 1024, 3)
+
+	rackID := []byte{0, 6, 'r', 'a', 'c', 'k', '-', 'a'}
+
+	payload := r.Encode(11)
+	if !bytes.HasSuffix(payload, rackID) {
+		t.Errorf("expect rack id at the end of v11 request, got %v", payload[len(payload)-8:])
+	}
+	if len(payload) > r.length(11) {
+		t.Errorf("request length %d exceeds the allocated %d", len(payload), r.length(11))
+	}
+
+	payload10 := r.Encode(10)
+	if len(payload)-len(payload10) != len(rackID) {
+		t.Errorf("expect v11 request %d bytes longer than v10, got %d", len(rackID), len(payload)-len(payload10))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	snappy "github.com/eapache/go-xerial-snappy"
 	"github.com/pierrec/lz4"
@@ -37,8 +38,10 @@ type PartitionResponse struct {
 		ProducerID  int64
 		FirstOffset int64
 	}
-	RecordBatchLength int32
-	RecordBatch       RecordBatch
+	// PreferredReadReplica is the replica the consumer should fetch from, -1 if it should fetch from the leader. v11+
+	PreferredReadReplica int32
+	RecordBatchLength    int32
+	RecordBatch          RecordBatch
 }
 
 type FetchResponse struct {
@@ -69,8 +72,33 @@ type fetchResponseStreamDecoder struct {
 	startOffset int64
 
 	hasOneMessage bool
+
+	// isolationLevel is the isolation level in the fetch request. In read_committed, records of aborted transactions are skipped
+	isolationLevel int8
+	// abortedTransactions of the partition being decoded, sorted by FirstOffset. they are moved to abortedProducerIDs when the decoder reaches FirstOffset
+	abortedTransactions []struct {
+		ProducerID  int64
+		FirstOffset int64
+	}
+	abortedProducerIDs map[int64]struct{}
+	lastStableOffset   int64
+
+	// skippedOffset is the offset next to the last batch skipped by the decoder (control batch or aborted batch),
+	// consumer moves to it if no message after it, or else it would fetch the skipped batches again and again
+	skippedOffset int64
+
+	// preferredReadReplica is the preferred_read_replica of the partition in the response, -1 if the broker does not set it
+	preferredReadReplica int32
 }
 
+const (
+	batchAttributeTransactional = 0x10
+	batchAttributeControl       = 0x20
+
+	// the type in the key of the control record, version(int16) + type(int16)
+	controlRecordTypeAbort = 0
+)
+
 func (streamDecoder *fetchResponseStreamDecoder) readAll() (length int, err error) {
 	defer func() {
 		streamDecoder.offset += length
@@ -133,6 +161,17 @@ func uncompress(compress int8, reader io.Reader) (uncompressedBytes []byte, err
 		if err != nil {
 			return nil, fmt.Errorf("uncompress lz4 records error: %w", err)
 		}
+	case COMPRESSION_ZSTD:
+		buf, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read streamDecoder error: %w", err)
+		}
+		uncompressedBytes, err = zstdDecompress(buf)
+		if err != nil {
+			return nil, fmt.Errorf("uncompress zstd records error: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown compression codec %d", compress)
 	}
 	return uncompressedBytes, nil
 }
@@ -228,15 +267,15 @@ func (streamDecoder *fetchResponseStreamDecoder) decodeRecordsMagic2(topicName s
 
 	baseOffset := int64(binary.BigEndian.Uint64(buf))
 	batchLength := binary.BigEndian.Uint32(buf[8:])
-	// partitionLeaderEpoch := binary.BigEndian.Uint32(buf[12:])
+	partitionLeaderEpoch := int32(binary.BigEndian.Uint32(buf[12:]))
 	// magic := buf[16]
 	// crc := binary.BigEndian.Uint32(buf[17:])
 	attributes := binary.BigEndian.Uint16(buf[21:])
-	compress := attributes & 0b11
-	// lastOffsetDelta := binary.BigEndian.Uint32(buf[23:])
+	compress := attributes & 0b111
+	lastOffsetDelta := binary.BigEndian.Uint32(buf[23:])
 	baseTimestamp := binary.BigEndian.Uint64(buf[27:])
 	// maxTimestamp := binary.BigEndian.Uint64(buf[35:])
-	// producerID := binary.BigEndian.Uint64(buf[43:])
+	producerID := int64(binary.BigEndian.Uint64(buf[43:]))
 	// producerEpoch := binary.BigEndian.Uint16(buf[51:])
 	// baseSequence := binary.BigEndian.Uint32(buf[53:])
 
@@ -248,6 +287,43 @@ func (streamDecoder *fetchResponseStreamDecoder) decodeRecordsMagic2(topicName s
 	}
 
 	r := io.LimitReader(streamDecoder, int64(batchLength)-49)
+
+	lastOffset := baseOffset + int64(lastOffsetDelta)
+	if streamDecoder.isolationLevel == IsolationLevelReadCommitted && attributes&batchAttributeTransactional != 0 {
+		streamDecoder.addAbortedProducers(lastOffset)
+	}
+
+	// control batches are never returned to the user, the abort marker ends the aborted transaction of the producer
+	if attributes&batchAttributeControl != 0 {
+		uncompressedBytes, err := uncompress(int8(compress), r)
+		if err != nil {
+			return offset, fmt.Errorf("uncompress control records bytes error: %w", err)
+		}
+		offset += int(batchLength) - 49
+		if record, _, err := DecodeToRecord(uncompressedBytes); err == nil && len(record.key) >= 4 {
+			if int16(binary.BigEndian.Uint16(record.key[2:])) == controlRecordTypeAbort {
+				delete(streamDecoder.abortedProducerIDs, producerID)
+			}
+		}
+		streamDecoder.skipBatch(lastOffset)
+		return offset, nil
+	}
+
+	if streamDecoder.isolationLevel == IsolationLevelReadCommitted && attributes&batchAttributeTransactional != 0 {
+		_, aborted := streamDecoder.abortedProducerIDs[producerID]
+		if aborted || (streamDecoder.lastStableOffset >= 0 && baseOffset >= streamDecoder.lastStableOffset) {
+			n, err := io.Copy(io.Discard, r)
+			offset += int(n)
+			if err != nil {
+				return offset, err
+			}
+			if n == int64(batchLength)-49 {
+				streamDecoder.skipBatch(lastOffset)
+			}
+			return offset, nil
+		}
+	}
+
 	uncompressedBytes, err := uncompress(int8(compress), r)
 	if err != nil {
 		return offset, fmt.Errorf("uncompress records bytes error: %w", err)
@@ -265,13 +341,14 @@ func (streamDecoder *fetchResponseStreamDecoder) decodeRecordsMagic2(topicName s
 		}
 		uncompressedBytesOffset += o
 		message := &Message{
-			Offset:     int64(record.offsetDelta) + baseOffset,
-			Timestamp:  uint64(record.timestampDelta) + baseTimestamp,
-			Attributes: record.attributes,
-			MagicByte:  2,
-			Key:        record.key,
-			Value:      record.value,
-			Headers:    record.Headers,
+			Offset:      int64(record.offsetDelta) + baseOffset,
+			Timestamp:   uint64(record.timestampDelta) + baseTimestamp,
+			Attributes:  record.attributes,
+			MagicByte:   2,
+			Key:         record.key,
+			Value:       record.value,
+			Headers:     record.Headers,
+			LeaderEpoch: partitionLeaderEpoch,
 		}
 		if err = streamDecoder.filterAndPutMessage(message, topicName, partitionID); err != nil {
 			return offset, err
@@ -281,6 +358,22 @@ func (streamDecoder *fetchResponseStreamDecoder) decodeRecordsMagic2(topicName s
 	return offset, nil
 }
 
+// addAbortedProducers marks the producers as aborted if their aborted transactions start before lastOffset
+func (streamDecoder *fetchResponseStreamDecoder) addAbortedProducers(lastOffset int64) {
+	for len(streamDecoder.abortedTransactions) > 0 && streamDecoder.abortedTransactions[0].FirstOffset <= lastOffset {
+		streamDecoder.abortedProducerIDs[streamDecoder.abortedTransactions[0].ProducerID] = struct{}{}
+		streamDecoder.abortedTransactions = streamDecoder.abortedTransactions[1:]
+	}
+}
+
+// skipBatch records the batch which is complete but not returned to the user
+func (streamDecoder *fetchResponseStreamDecoder) skipBatch(lastOffset int64) {
+	if lastOffset+1 > streamDecoder.skippedOffset {
+		streamDecoder.skippedOffset = lastOffset + 1
+	}
+	streamDecoder.hasOneMessage = true
+}
+
 func (streamDecoder *fetchResponseStreamDecoder) filterAndPutMessage(message *Message, topicName string, partitionID int32) (err error) {
 	if streamDecoder.filterMessage(message) {
 		msg := &FullMessage{
@@ -393,11 +486,11 @@ func (streamDecoder *fetchResponseStreamDecoder) decodePartitionResponse(topicNa
 	// p.HighWatermark = int64(binary.BigEndian.Uint64(buf))
 
 	switch version {
-	case 7, 10:
+	case 7, 10, 11:
 		if _, err = streamDecoder.Read(buf[:8]); err != nil {
 			return err
 		}
-		// p.LastStableOffset = int64(binary.BigEndian.Uint64(buf))
+		p.LastStableOffset = int64(binary.BigEndian.Uint64(buf))
 
 		if _, err = streamDecoder.Read(buf[:8]); err != nil {
 			return err
@@ -427,6 +520,25 @@ func (streamDecoder *fetchResponseStreamDecoder) decodePartitionResponse(topicNa
 		}
 	}
 
+	sort.Slice(p.AbortedTransactions, func(i, j int) bool {
+		return p.AbortedTransactions[i].FirstOffset < p.AbortedTransactions[j].FirstOffset
+	})
+	streamDecoder.abortedTransactions = p.AbortedTransactions
+	streamDecoder.abortedProducerIDs = make(map[int64]struct{})
+	streamDecoder.lastStableOffset = -1
+	if version >= 4 {
+		streamDecoder.lastStableOffset = p.LastStableOffset
+	}
+
+	p.PreferredReadReplica = -1
+	if version >= 11 {
+		if _, err = streamDecoder.Read(buf[:4]); err != nil {
+			return err
+		}
+		p.PreferredReadReplica = int32(binary.BigEndian.Uint32(buf))
+	}
+	streamDecoder.preferredReadReplica = p.PreferredReadReplica
+
 	if _, err = streamDecoder.Read(buf[:4]); err != nil {
 		return err
 	}
@@ -491,7 +603,7 @@ func (streamDecoder *fetchResponseStreamDecoder) decodeHeader(version uint16) er
 	case 0:
 		headerLength = 8
 		countOffset = 4
-	case 7, 10:
+	case 7, 10, 11:
 		headerLength = 18
 		countOffset = 14
 	}
@@ -512,6 +624,8 @@ func (streamDecoder *fetchResponseStreamDecoder) streamDecode(ctx context.Contex
 	streamDecoder.offset = 0
 	streamDecoder.startOffset = startOffset
 	streamDecoder.hasOneMessage = false
+	streamDecoder.skippedOffset = 0
+	streamDecoder.preferredReadReplica = -1
 
 	if err := streamDecoder.decodeHeader(streamDecoder.version); err != nil {
 		return err
@@ -20,9 +20,16 @@ coordinator_key	Id to use for finding the coordinator (for groups, this is the g
 coordinator_type	The type of coordinator to find (0 = group, 1 = transaction)
 */
 
+// coordinator types of FindCoordinator request (version 1+)
+const (
+	CoordinatorTypeGroup       int8 = 0
+	CoordinatorTypeTransaction int8 = 1
+)
+
 type FindCoordinatorRequest struct {
 	*RequestHeader
-	GroupID string
+	GroupID string // coordinator_key in version 1+, it is transactional id if KeyType is CoordinatorTypeTransaction
+	KeyType int8   // version 1+
 }
 
 func NewFindCoordinatorRequest(clientID, groupID string) *FindCoordinatorRequest {
@@ -38,8 +45,18 @@ func NewFindCoordinatorRequest(clientID, groupID string) *FindCoordinatorRequest
 	}
 }
 
+// NewFindTransactionCoordinatorRequest creates a FindCoordinatorRequest to find the transaction coordinator of transactionalID
+func NewFindTransactionCoordinatorRequest(clientID, transactionalID string) *FindCoordinatorRequest {
+	r := NewFindCoordinatorRequest(clientID, transactionalID)
+	r.KeyType = CoordinatorTypeTransaction
+	return r
+}
+
 func (findCoordinatorR *FindCoordinatorRequest) Encode(version uint16) []byte {
 	requestLength := findCoordinatorR.RequestHeader.length() + 2 + len(findCoordinatorR.GroupID)
+	if version >= 1 {
+		requestLength++
+	}
 
 	payload := make([]byte, requestLength+4)
 	offset := 0
@@ -52,7 +69,11 @@ func (findCoordinatorR *FindCoordinatorRequest) Encode(version uint16) []byte {
 	binary.BigEndian.PutUint16(payload[offset:], uint16(len(findCoordinatorR.GroupID)))
 	offset += 2
 
-	copy(payload[offset:], findCoordinatorR.GroupID)
+	offset += copy(payload[offset:], findCoordinatorR.GroupID)
+
+	if version >= 1 {
+		payload[offset] = byte(findCoordinatorR.KeyType)
+	}
 
 	return payload
 }
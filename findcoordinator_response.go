@@ -14,9 +14,11 @@ type Coordinator struct {
 
 // FindCoordinatorResponse is the response of findcoordinator request, including correlationID, errorCode, coordinator
 type FindCoordinatorResponse struct {
-	CorrelationID uint32
-	ErrorCode     int16
-	Coordinator   Coordinator
+	CorrelationID  uint32
+	ThrottleTimeMS int32 // version 1+
+	ErrorCode      int16
+	ErrorMessage   *string // version 1+
+	Coordinator    Coordinator
 }
 
 func (r FindCoordinatorResponse) Error() error {
@@ -35,9 +37,20 @@ func NewFindCoordinatorResponse(payload []byte, version uint16) (r FindCoordinat
 	r.CorrelationID = uint32(binary.BigEndian.Uint32(payload[offset:]))
 	offset += 4
 
+	if version >= 1 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
 	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
 	offset += 2
 
+	if version >= 1 {
+		var o int
+		r.ErrorMessage, o = nullableString(payload[offset:])
+		offset += o
+	}
+
 	r.Coordinator.NodeID = int32(binary.BigEndian.Uint32(payload[offset:]))
 	offset += 4
 
@@ -71,3 +71,28 @@ func TestFindcoordinatorResponseDecode(t *testing.T) {
 		t.Errorf("FindCoordinatorResponse encode failed. %v!=%v", r, nr)
 	}
 }
+
+func TestFindcoordinatorResponseDecodeV1(t *testing.T) {
+	payload := []byte{
+		0, 0, 0, 0, // length
+		0, 0, 0, 1, // correlation id
+		0, 0, 0, 10, // throttle time
+		0, 15, // error code
+		0, 4, 'n', 'o', 'n', 'e', // error message
+		0, 0, 0, 4, // node id
+		0, 9, '1', '2', '7', '.', '0', '.', '0', '.', '1', // host
+		0, 0, 0x23, 0x84, // port
+	}
+	binary.BigEndian.PutUint32(payload, uint32(len(payload)-4))
+
+	r, err := NewFindCoordinatorResponse(payload, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ThrottleTimeMS != 10 || r.ErrorCode != 15 || *r.ErrorMessage != "none" {
+		t.Errorf("decode v1 fields error: %+v", r)
+	}
+	if r.Coordinator.NodeID != 4 || r.Coordinator.Host != "127.0.0.1" || r.Coordinator.Port != 9092 {
+		t.Errorf("decode coordinator error: %+v", r.Coordinator)
+	}
+}
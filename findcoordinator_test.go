@@ -16,3 +16,15 @@ func TestFindCoordinator(t *testing.T) {
 		t.Error("offsets request payload length should be 38")
 	}
 }
+
+func TestFindTransactionCoordinator(t *testing.T) {
+	request := NewFindTransactionCoordinatorRequest("healer", "healer.txn")
+
+	payload := request.Encode(1)
+	if len(payload) != 33 {
+		t.Errorf("find coordinator request v1 payload length should be 33, got %d", len(payload))
+	}
+	if payload[len(payload)-1] != byte(CoordinatorTypeTransaction) {
+		t.Error("key type should be transaction")
+	}
+}
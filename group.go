@@ -110,6 +110,8 @@ type ProtocolMetadata struct {
 	Version      uint16
 	Subscription []string
 	UserData     []byte
+	// OwnedPartitions are the partitions the member is consuming, since version 1. cooperative rebalance protocol relies on it
+	OwnedPartitions []*PartitionAssignment
 }
 
 func (m *ProtocolMetadata) Length() int {
@@ -119,6 +121,13 @@ func (m *ProtocolMetadata) Length() int {
 		length += len(subscription)
 	}
 	length += 4 + len(m.UserData)
+	if m.Version >= 1 {
+		length += 4
+		for _, p := range m.OwnedPartitions {
+			length += 2 + len(p.Topic)
+			length += 4 + len(p.Partitions)*4
+		}
+	}
 	return length
 }
 
@@ -140,7 +149,24 @@ func (m *ProtocolMetadata) Encode() []byte {
 	}
 	binary.BigEndian.PutUint32(payload[offset:], uint32(len(m.UserData)))
 	offset += 4
-	copy(payload[offset:], m.UserData)
+	offset += copy(payload[offset:], m.UserData)
+
+	if m.Version >= 1 {
+		binary.BigEndian.PutUint32(payload[offset:], uint32(len(m.OwnedPartitions)))
+		offset += 4
+		for _, p := range m.OwnedPartitions {
+			binary.BigEndian.PutUint16(payload[offset:], uint16(len(p.Topic)))
+			offset += 2
+			offset += copy(payload[offset:], p.Topic)
+
+			binary.BigEndian.PutUint32(payload[offset:], uint32(len(p.Partitions)))
+			offset += 4
+			for _, partitionID := range p.Partitions {
+				binary.BigEndian.PutUint32(payload[offset:], uint32(partitionID))
+				offset += 4
+			}
+		}
+	}
 
 	return payload
 }
@@ -167,6 +193,29 @@ func NewProtocolMetadata(payload []byte) *ProtocolMetadata {
 	if l != -1 {
 		p.UserData = make([]byte, int(l))
 		copy(p.UserData, payload[offset:offset+int(l)])
+		offset += int(l)
+	}
+
+	// fields added in later versions, such as generation_id and rack_id, are ignored
+	if p.Version >= 1 && offset+4 <= len(payload) {
+		count := int(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		p.OwnedPartitions = make([]*PartitionAssignment, count)
+		for i := range p.OwnedPartitions {
+			l := int(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+			ownedPartition := &PartitionAssignment{Topic: string(payload[offset : offset+l])}
+			offset += l
+
+			partitionCount := int(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			ownedPartition.Partitions = make([]int32, partitionCount)
+			for j := range ownedPartition.Partitions {
+				ownedPartition.Partitions[j] = int32(binary.BigEndian.Uint32(payload[offset:]))
+				offset += 4
+			}
+			p.OwnedPartitions[i] = ownedPartition
+		}
 	}
 
 	return p
@@ -1,23 +1,47 @@
 package healer
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+var (
+	errNoTopicToSubscribe = errors.New("no topic to subscribe")
+	errNotJoined          = errors.New("group consumer has not joined the group")
+)
+
+// RebalanceListener is notified when the partitions of GroupConsumer change.
+// The callbacks are called synchronously in the rebalance, the consumer does not rejoin the group or start fetching until they return
+type RebalanceListener interface {
+	// OnPartitionsRevoked is called after the simple consumers of the partitions stop and before they are given up.
+	// it is the last chance to flush in-flight state and commit offsets, offsets are also committed after it returns if auto.commit is true
+	OnPartitionsRevoked(partitions []*PartitionAssignment)
+	// OnPartitionsAssigned is called after the assignment is received and before the simple consumers of the partitions start.
+	// in cooperative protocol, partitions only contain the newly assigned ones
+	OnPartitionsAssigned(partitions []*PartitionAssignment)
+	// OnPartitionsLost is called instead of OnPartitionsRevoked if the member is fenced out of the group.
+	// the partitions may be owned by other members already, so offsets could not be committed
+	OnPartitionsLost(partitions []*PartitionAssignment)
+}
+
 // GroupConsumer can join one group with other GroupConsumers with the same groupID
 // and they consume messages from Kafka
 // they will rebalance when new GroupConsumer joins or one leaves
 type GroupConsumer struct {
 	// TODO refresh metainfo in ticker
 	brokers       *Brokers
-	topic         string
+	subscription  []string       // topics subscribed by this consumer
+	pattern       *regexp.Regexp // subscription is updated from metadata if pattern is set
 	correlationID uint32
 
 	config ConsumerConfig
@@ -35,18 +59,53 @@ type GroupConsumer struct {
 	topics               []string
 	partitionAssignments []*PartitionAssignment
 	simpleConsumers      []*SimpleConsumer
+	revoked              int // count of partitions revoked in the last sync, only used in cooperative protocol
+	rebalanceListener    RebalanceListener
 
 	messages chan *FullMessage
 
-	mutex              sync.Locker
-	wg                 sync.WaitGroup // wg is used to tell if all consumer has already stopped
-	assignmentStrategy AssignmentStrategy
+	mutex                sync.Locker
+	wg                   sync.WaitGroup // wg is used to tell if all consumer has already stopped
+	assignmentStrategies []AssignmentStrategy
+	assignmentStrategy   AssignmentStrategy // the strategy chosen by coordinator
 
 	restartLocker sync.Locker
 }
 
 // NewGroupConsumer cretae a new GroupConsumer
 func NewGroupConsumer(topic string, config interface{}) (*GroupConsumer, error) {
+	return NewGroupConsumerWithTopics([]string{topic}, config)
+}
+
+// NewGroupConsumerWithTopics create a new GroupConsumer which subscribes several topics
+func NewGroupConsumerWithTopics(topics []string, config interface{}) (*GroupConsumer, error) {
+	if len(topics) == 0 {
+		return nil, errNoTopicToSubscribe
+	}
+	c, err := newGroupConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+	c.subscription = topics
+	return c, nil
+}
+
+// NewGroupConsumerWithPattern create a new GroupConsumer which subscribes all the topics matching the regular expression.
+// The pattern is evaluated again when metadata is refreshed, and the consumer rejoins the group if the matched topics change
+func NewGroupConsumerWithPattern(pattern string, config interface{}) (*GroupConsumer, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid topic pattern %s: %w", pattern, err)
+	}
+	c, err := newGroupConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+	c.pattern = re
+	return c, nil
+}
+
+func newGroupConsumer(config interface{}) (*GroupConsumer, error) {
 	cfg, err := createConsumerConfig(config)
 	logger.Info("create group consumer", "origin_config", config, "final_config", cfg)
 	if err != nil {
@@ -66,6 +125,11 @@ func NewGroupConsumer(topic string, config interface{}) (*GroupConsumer, error)
 		}
 	}
 
+	assignmentStrategies, err := getAssignmentStrategies(cfg.PartitionAssignmentStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	brokerConfig := getBrokerConfigFromConsumerConfig(cfg)
 	brokers, err := NewBrokersWithConfig(cfg.BootstrapServers, brokerConfig)
 	if err != nil {
@@ -74,12 +138,12 @@ func NewGroupConsumer(topic string, config interface{}) (*GroupConsumer, error)
 
 	c := &GroupConsumer{
 		brokers:       brokers,
-		topic:         topic,
 		correlationID: 0,
 		config:        cfg,
 
-		mutex:              &sync.Mutex{},
-		assignmentStrategy: &rangeAssignmentStrategy{},
+		mutex:                &sync.Mutex{},
+		assignmentStrategies: assignmentStrategies,
+		assignmentStrategy:   assignmentStrategies[0],
 
 		joined:               false,
 		coordinatorAvailable: false,
@@ -92,6 +156,11 @@ func NewGroupConsumer(topic string, config interface{}) (*GroupConsumer, error)
 	return c, nil
 }
 
+// SetRebalanceListener sets the listener which is notified when partitions are assigned, revoked or lost. It should be called before Consume
+func (c *GroupConsumer) SetRebalanceListener(listener RebalanceListener) {
+	c.rebalanceListener = listener
+}
+
 // request metadata and set partition metadat to group-consumer. only leader should request this
 func (c *GroupConsumer) getTopicPartitionInfo() {
 	// TODO if could not get meta, such as error 5:`There is no leader for this topic-partition as we are in the middle of a leadership election.`
@@ -152,20 +221,158 @@ func (c *GroupConsumer) parseGroupAssignments(memberAssignmentPayload []byte) er
 	b, _ := json.Marshal(memberAssignment)
 	logger.Info("parse memeber assignment", "assignment", b)
 	c.partitionAssignments = memberAssignment.PartitionAssignments
-	c.simpleConsumers = make([]*SimpleConsumer, 0)
+	if isCooperative(c.assignmentStrategy) {
+		c.applyAssignments()
+		return nil
+	}
+	// the simple consumers are kept if the last rebalance is cooperative
+	if len(c.assignedConsumers()) > 0 {
+		c.stop(false)
+	}
 
+	simpleConsumers := make([]*SimpleConsumer, 0)
 	for _, partitionAssignment := range c.partitionAssignments {
 		for _, partitionID := range partitionAssignment.Partitions {
 			simpleConsumer := NewSimpleConsumerWithBrokers(partitionAssignment.Topic, partitionID, c.config, c.brokers)
 			simpleConsumer.belongTO = c
 			simpleConsumer.wg = &c.wg
-			c.simpleConsumers = append(c.simpleConsumers, simpleConsumer)
+			simpleConsumers = append(simpleConsumers, simpleConsumer)
 		}
 	}
+	c.mutex.Lock()
+	c.simpleConsumers = simpleConsumers
+	c.mutex.Unlock()
+	c.partitionsAssigned(c.partitionAssignments)
 
 	return nil
 }
 
+// applyAssignments is used in cooperative protocol. it only stops the simple consumers of revoked partitions,
+// and starts simple consumers for the new assigned partitions. the others keep fetching
+func (c *GroupConsumer) applyAssignments() {
+	assigned := make(map[topicPartition]bool)
+	for _, partitionAssignment := range c.partitionAssignments {
+		for _, partitionID := range partitionAssignment.Partitions {
+			assigned[topicPartition{partitionAssignment.Topic, partitionID}] = true
+		}
+	}
+
+	c.mutex.Lock()
+	owned := make(map[topicPartition]bool)
+	simpleConsumers := make([]*SimpleConsumer, 0, len(assigned))
+	revoked := make([]*SimpleConsumer, 0)
+	for _, simpleConsumer := range c.simpleConsumers {
+		tp := topicPartition{simpleConsumer.topic, simpleConsumer.partitionID}
+		if assigned[tp] {
+			owned[tp] = true
+			simpleConsumers = append(simpleConsumers, simpleConsumer)
+			continue
+		}
+		logger.Info("partition revoked, stop simple consumer", "topic", simpleConsumer.topic, "partitionID", simpleConsumer.partitionID)
+		simpleConsumer.Stop()
+		revoked = append(revoked, simpleConsumer)
+	}
+	c.mutex.Unlock()
+
+	if len(revoked) > 0 {
+		c.partitionsRevoked(revoked, false)
+		c.revoked += len(revoked)
+	}
+
+	var offset int64 = -1
+	if c.config.FromBeginning {
+		offset = -2
+	}
+	added := make([]*SimpleConsumer, 0)
+	for _, partitionAssignment := range c.partitionAssignments {
+		for _, partitionID := range partitionAssignment.Partitions {
+			if owned[topicPartition{partitionAssignment.Topic, partitionID}] {
+				continue
+			}
+			logger.Info("partition assigned, start simple consumer", "topic", partitionAssignment.Topic, "partitionID", partitionID)
+			simpleConsumer := NewSimpleConsumerWithBrokers(partitionAssignment.Topic, partitionID, c.config, c.brokers)
+			simpleConsumer.belongTO = c
+			simpleConsumer.wg = &c.wg
+			added = append(added, simpleConsumer)
+		}
+	}
+
+	c.mutex.Lock()
+	c.simpleConsumers = append(simpleConsumers, added...)
+	c.mutex.Unlock()
+
+	c.partitionsAssigned(groupByTopic(added))
+
+	for _, simpleConsumer := range added {
+		c.wg.Add(1)
+		go simpleConsumer.Consume(offset, c.messages)
+	}
+}
+
+// ownedPartitions returns the partitions which are being consumed, they are sent in JoinGroup request in cooperative protocol
+func (c *GroupConsumer) ownedPartitions() []*PartitionAssignment {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return groupByTopic(c.simpleConsumers)
+}
+
+// groupByTopic returns the partitions of the simple consumers, grouped by topic
+func groupByTopic(simpleConsumers []*SimpleConsumer) []*PartitionAssignment {
+	partitions := make([]*PartitionAssignment, 0)
+	for _, simpleConsumer := range simpleConsumers {
+		var p *PartitionAssignment
+		for _, o := range partitions {
+			if o.Topic == simpleConsumer.topic {
+				p = o
+				break
+			}
+		}
+		if p == nil {
+			p = &PartitionAssignment{Topic: simpleConsumer.topic}
+			partitions = append(partitions, p)
+		}
+		p.Partitions = append(p.Partitions, simpleConsumer.partitionID)
+	}
+	return partitions
+}
+
+// partitionsRevoked notifies the listener that the partitions of the stopped simple consumers are revoked or lost,
+// and then commits their offsets if auto.commit is true and the partitions are not lost
+func (c *GroupConsumer) partitionsRevoked(simpleConsumers []*SimpleConsumer, lost bool) {
+	partitions := groupByTopic(simpleConsumers)
+	if lost {
+		logger.Info("partitions lost", "partitions", partitions)
+		if c.rebalanceListener != nil {
+			c.rebalanceListener.OnPartitionsLost(partitions)
+		}
+		return
+	}
+
+	if c.rebalanceListener != nil {
+		c.rebalanceListener.OnPartitionsRevoked(partitions)
+	}
+	if c.config.AutoCommit {
+		for _, simpleConsumer := range simpleConsumers {
+			simpleConsumer.CommitOffset()
+		}
+	}
+}
+
+func (c *GroupConsumer) partitionsAssigned(partitions []*PartitionAssignment) {
+	if c.rebalanceListener != nil {
+		c.rebalanceListener.OnPartitionsAssigned(partitions)
+	}
+}
+
+// groupInstanceID returns nil if the consumer is not a static member
+func (c *GroupConsumer) groupInstanceID() *string {
+	if c.config.GroupInstanceID == "" {
+		return nil
+	}
+	return &c.config.GroupInstanceID
+}
+
 // join && set generationID&memberID
 func (c *GroupConsumer) join() error {
 	logger.Info("try to join group", "groupId", c.config.GroupID)
@@ -173,15 +380,34 @@ func (c *GroupConsumer) join() error {
 		protocolType = "consumer"
 	)
 
-	protocolMetadata := &ProtocolMetadata{
-		Version:      0,
-		Subscription: []string{c.topic},
-		UserData:     nil,
+	cooperative := false
+	for _, s := range c.assignmentStrategies {
+		cooperative = cooperative || isCooperative(s)
+	}
+	var ownedPartitions []*PartitionAssignment
+	if cooperative {
+		ownedPartitions = c.ownedPartitions()
 	}
 
-	gps := []*GroupProtocol{{"range", protocolMetadata.Encode()}}
+	subscription := c.subscribedTopics()
+	gps := make([]*GroupProtocol, 0, len(c.assignmentStrategies))
+	for _, s := range c.assignmentStrategies {
+		protocolMetadata := &ProtocolMetadata{
+			Version:      0,
+			Subscription: subscription,
+			UserData:     nil,
+		}
+		if cooperative {
+			protocolMetadata.Version = 1
+			protocolMetadata.OwnedPartitions = ownedPartitions
+		}
+		if u, ok := s.(UserDataAssignmentStrategy); ok {
+			protocolMetadata.UserData = u.UserData(c.partitionAssignments, c.generationID)
+		}
+		gps = append(gps, &GroupProtocol{s.Name(), protocolMetadata.Encode()})
+	}
 	joinGroupResponse, err := c.coordinator.requestJoinGroup(
-		c.config.ClientID, c.config.GroupID, int32(c.config.SessionTimeoutMS), c.memberID, protocolType, gps)
+		c.config.ClientID, c.config.GroupID, int32(c.config.SessionTimeoutMS), c.memberID, c.groupInstanceID(), protocolType, gps)
 
 	if err != nil {
 		logger.Error(err, "join group failed", "groupId", c.config.GroupID)
@@ -190,6 +416,11 @@ func (c *GroupConsumer) join() error {
 			c.memberID = ""
 		}
 
+		// MEMBER_ID_REQUIRED, join again with the memberID assigned by the coordinator
+		if err == KafkaError(79) {
+			c.memberID = joinGroupResponse.MemberID
+		}
+
 		if err == io.EOF || err == KafkaError(15) || err == KafkaError(16) {
 			c.coordinatorAvailable = false
 		}
@@ -208,11 +439,23 @@ func (c *GroupConsumer) join() error {
 		return err
 	}
 
+	var strategy AssignmentStrategy
+	for _, s := range c.assignmentStrategies {
+		if s.Name() == joinGroupResponse.GroupProtocol {
+			strategy = s
+		}
+	}
+	if strategy == nil {
+		return fmt.Errorf("%w: coordinator chose %s", errUnknownAssignmentStrategy, joinGroupResponse.GroupProtocol)
+	}
+	c.assignmentStrategy = strategy
+
 	c.generationID = joinGroupResponse.GenerationID
 	c.memberID = joinGroupResponse.MemberID
-	logger.Info("got new memberID after (re)join", "memberId", c.memberID)
+	logger.Info("got new memberID after (re)join", "memberId", c.memberID, "protocol", joinGroupResponse.GroupProtocol)
 
-	if joinGroupResponse.LeaderID == c.memberID {
+	// a static leader which rejoins a stable group gets no members, and the coordinator keeps the current assignment
+	if joinGroupResponse.LeaderID == c.memberID && len(joinGroupResponse.Members) > 0 {
 		c.ifLeader = true
 		c.members = joinGroupResponse.Members
 		logger.Info("I am the leader", "members", c.members)
@@ -234,7 +477,7 @@ func (c *GroupConsumer) sync() error {
 	logger.Info("create group assignment", "assignment", groupAssignment)
 
 	syncGroupResponse, err := c.coordinator.requestSyncGroup(
-		c.config.ClientID, c.config.GroupID, c.generationID, c.memberID, groupAssignment)
+		c.config.ClientID, c.config.GroupID, c.generationID, c.memberID, c.groupInstanceID(), groupAssignment)
 
 	b, _ := json.Marshal(syncGroupResponse)
 	logger.Info("sync returns", "response", b)
@@ -289,7 +532,7 @@ func (c *GroupConsumer) joinAndSync() error {
 			return nil
 		}
 
-		if err == KafkaError(22) || err == KafkaError(25) || err == KafkaError(27) {
+		if err == KafkaError(22) || err == KafkaError(25) || err == KafkaError(27) || err == KafkaError(79) {
 			continue
 		}
 		if _, ok := err.(KafkaError); ok {
@@ -312,25 +555,25 @@ func (c *GroupConsumer) heartbeat() error {
 	}
 
 	logger.V(5).Info("heartbeat", "generationID", c.generationID, "memberID", c.memberID)
-	_, err := c.coordinator.requestHeartbeat(c.config.ClientID, c.config.GroupID, c.generationID, c.memberID)
+	_, err := c.coordinator.requestHeartbeat(c.config.ClientID, c.config.GroupID, c.generationID, c.memberID, c.groupInstanceID())
 	return err
 }
 
 // CommitOffset commit offset to kafka server
 func (c *GroupConsumer) CommitOffset() {
-	for _, s := range c.simpleConsumers {
+	for _, s := range c.assignedConsumers() {
 		s.CommitOffset()
 	}
 }
 
-func (c *GroupConsumer) commitOffset(topic string, partitionID int32, offset int64) bool {
+func (c *GroupConsumer) commitOffset(topic string, partitionID int32, offset int64) error {
 	if c.memberID == "" {
 		logger.V(3).Info("do not commit offset because memberID is empty now", "topic", topic, "partitionID", partitionID, "offset", offset)
-		return false
+		return errNotJoined
 	}
 	if offset < 0 {
 		logger.V(3).Info("invalid commit offset", "offste", offset)
-		return false
+		return fmt.Errorf("invalid commit offset %d", offset)
 	}
 	var apiVersion uint16
 	if c.config.OffsetsStorage == 1 {
@@ -341,6 +584,7 @@ func (c *GroupConsumer) commitOffset(topic string, partitionID int32, offset int
 	offsetComimtReq := NewOffsetCommitRequest(apiVersion, c.config.ClientID, c.config.GroupID)
 	offsetComimtReq.SetMemberID(c.memberID)
 	offsetComimtReq.SetGenerationID(c.generationID)
+	offsetComimtReq.SetGroupInstanceID(c.groupInstanceID())
 	offsetComimtReq.SetRetentionTime(-1)
 	offsetComimtReq.AddPartiton(topic, partitionID, offset, "")
 
@@ -350,38 +594,128 @@ func (c *GroupConsumer) commitOffset(topic string, partitionID int32, offset int
 	}
 	if err == nil {
 		logger.V(3).Info("offset committed", "memberID", c.memberID, "generationID", c.generationID, "topic", topic, "partitionID", partitionID, "offset", offset)
-		return true
+		return nil
 	}
 	logger.Error(err, "commit offset failed", "memberID", c.memberID, "generationID", c.generationID, "topic", topic, "partitionID", partitionID, "offset", offset)
-	return false
+	return err
+}
+
+// Commit commits offsets synchronously and returns when the commit is done or ctx is done.
+// offsets are the next offsets to consume keyed by topic and partition, and they must be assigned to this member.
+// if offsets is nil, offsets next to the acknowledged contiguous messages of all assigned partitions are committed
+func (c *GroupConsumer) Commit(ctx context.Context, offsets map[string]map[int32]int64) error {
+	return commitSimpleConsumers(ctx, c.assignedConsumers(), offsets)
+}
+
+// Ack acknowledges that the message is processed, see SimpleConsumer.Ack. It is ignored if the partition is not assigned to this member any more
+func (c *GroupConsumer) Ack(msg *FullMessage) {
+	if s := findSimpleConsumer(c.assignedConsumers(), msg.TopicName, msg.PartitionID); s != nil {
+		s.Ack(msg)
+		return
+	}
+	logger.V(3).Info("ack message of partition not assigned", "topic", msg.TopicName, "partitionID", msg.PartitionID)
+}
+
+// Pause stops fetching the partitions until they are resumed, see SimpleConsumer.Pause.
+// the partitions must be assigned to this member, and they are not paused any more after they are reassigned in eager rebalance
+func (c *GroupConsumer) Pause(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.assignedConsumers(), partitions, (*SimpleConsumer).Pause)
 }
 
-func (c *GroupConsumer) restart() {
+// Resume continues fetching the paused partitions
+func (c *GroupConsumer) Resume(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.assignedConsumers(), partitions, (*SimpleConsumer).Resume)
+}
+
+// SeekToOffset moves the position of the partition to offset, see SimpleConsumer.SeekToOffset. The partition must be assigned to this member
+func (c *GroupConsumer) SeekToOffset(topic string, partitionID int32, offset int64) error {
+	s := findSimpleConsumer(c.assignedConsumers(), topic, partitionID)
+	if s == nil {
+		return fmt.Errorf("%w: %s-%d", errPartitionNotConsumed, topic, partitionID)
+	}
+	return s.SeekToOffset(offset)
+}
+
+// SeekToBeginning moves the positions of the partitions to the earliest offsets
+func (c *GroupConsumer) SeekToBeginning(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.assignedConsumers(), partitions, (*SimpleConsumer).SeekToBeginning)
+}
+
+// SeekToEnd moves the positions of the partitions to the latest offsets
+func (c *GroupConsumer) SeekToEnd(partitions []*PartitionAssignment) error {
+	return forEachPartition(c.assignedConsumers(), partitions, (*SimpleConsumer).SeekToEnd)
+}
+
+// SeekToTimestamp moves the positions of the partitions to the offsets of the timestamp(ms), see SimpleConsumer.SeekToTimestamp
+func (c *GroupConsumer) SeekToTimestamp(partitions []*PartitionAssignment, timestamp int64) error {
+	return forEachPartition(c.assignedConsumers(), partitions, func(s *SimpleConsumer) { s.SeekToTimestamp(timestamp) })
+}
+
+// assignedConsumers returns the simple consumers of the assigned partitions
+func (c *GroupConsumer) assignedConsumers() []*SimpleConsumer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.simpleConsumers
+}
+
+// restart stops all the simple consumers and rejoins the group. lost is true if the member is fenced out of the group
+func (c *GroupConsumer) restart(lost bool) {
 	// heartbeat and metadata changing could both cause restart. make sure they do not conflict
 	c.restartLocker.Lock()
 	defer c.restartLocker.Unlock()
 
-	c.stop()
+	c.stop(lost)
 	// stop heartbeat
 	c.joined = false
 	c.consumeWithoutHeartBeat(c.config.FromBeginning)
 
 }
 
-func (c *GroupConsumer) stop() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// rebalance rejoins the group without stopping the simple consumers, it is used in cooperative protocol
+func (c *GroupConsumer) rebalance() {
+	c.restartLocker.Lock()
+	defer c.restartLocker.Unlock()
 
-	logger.Info("stop group consumer", "GroupID", c.config.GroupID, "simpleConsumerCount", len(c.simpleConsumers))
-	if c.simpleConsumers != nil {
-		for _, simpleConsumer := range c.simpleConsumers {
-			logger.Info("stop simple consumer", "topic", simpleConsumer.topic, "partitionID", simpleConsumer.partitionID)
-			simpleConsumer.Stop()
-		}
+	c.joined = false
+	c.consumeWithoutHeartBeat(c.config.FromBeginning)
+}
+
+// restartOrRebalance restarts the group consumer in eager protocol.
+// in cooperative protocol, it rebalances and keeps consuming, unless the partitions are lost because the member is fenced out of the group
+func (c *GroupConsumer) restartOrRebalance(err error) {
+	lost := partitionsLost(err)
+	if isCooperative(c.assignmentStrategy) && !lost {
+		c.rebalance()
+	} else {
+		c.restart(lost)
 	}
-	c.simpleConsumers = nil
+}
+
+// partitionsLost returns true if the error means the member is fenced out of the group, and its partitions may be owned by others
+func partitionsLost(err error) bool {
+	return err == KafkaError(22) || err == KafkaError(25) || err == KafkaError(82)
+}
 
+// stop stops all the simple consumers, and then notifies the rebalance listener that their partitions are revoked or lost
+func (c *GroupConsumer) stop(lost bool) {
+	c.mutex.Lock()
+	logger.Info("stop group consumer", "GroupID", c.config.GroupID, "simpleConsumerCount", len(c.simpleConsumers))
+	simpleConsumers := c.simpleConsumers
+	for _, simpleConsumer := range simpleConsumers {
+		logger.Info("stop simple consumer", "topic", simpleConsumer.topic, "partitionID", simpleConsumer.partitionID)
+		simpleConsumer.Stop()
+	}
 	c.wg.Wait()
+	c.mutex.Unlock()
+
+	// simple consumers are kept until the listener returns, so that GroupConsumer.CommitOffset could be called in OnPartitionsRevoked
+	if len(simpleConsumers) > 0 {
+		c.partitionsRevoked(simpleConsumers, lost)
+	}
+
+	c.mutex.Lock()
+	c.simpleConsumers = nil
+	c.mutex.Unlock()
 }
 
 func (c *GroupConsumer) leave() {
@@ -389,6 +723,11 @@ func (c *GroupConsumer) leave() {
 		logger.Info("not joined yet, leave directly")
 		return
 	}
+	// static member keeps its membership until session timeout, so it gets the same partitions if it comes back in time
+	if c.config.GroupInstanceID != "" {
+		logger.Info("static member does not leave group", "memberID", c.memberID, "groupInstanceID", c.config.GroupInstanceID, "GroupID", c.config.GroupID)
+		return
+	}
 	logger.Info("group consumer leaves", "memberID", c.memberID, "GroupID", c.config.GroupID)
 	if _, err := c.coordinator.requestLeaveGroup(c.config.ClientID, c.config.GroupID, c.memberID); err != nil {
 		logger.Error(err, "leave group failed", "groupID", c.config.GroupID, "memberID", c.memberID)
@@ -413,7 +752,7 @@ func (c *GroupConsumer) AwaitClose(timeout time.Duration) {
 	c.closed = true
 	c.closeChan <- true
 
-	c.stop()
+	c.stop(false)
 
 	done := make(chan bool)
 	go func() {
@@ -432,6 +771,42 @@ func (c *GroupConsumer) AwaitClose(timeout time.Duration) {
 	c.leave()
 }
 
+// updateSubscription evaluates the pattern against all topics in the cluster. it returns true if the subscription changes
+func (c *GroupConsumer) updateSubscription() (bool, error) {
+	if c.pattern == nil {
+		return false, nil
+	}
+
+	metaDataResponse, err := c.brokers.RequestMetaData(c.config.ClientID, nil)
+	if err != nil {
+		return false, err
+	}
+	topics := make([]string, 0)
+	for _, topicMetadata := range metaDataResponse.TopicMetadatas {
+		if !topicMetadata.IsInternal && c.pattern.MatchString(topicMetadata.TopicName) {
+			topics = append(topics, topicMetadata.TopicName)
+		}
+	}
+	sort.Strings(topics)
+
+	// it runs in refreshMeta goroutine, and subscription is read by join
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.subscription != nil && strings.Join(topics, ",") == strings.Join(c.subscription, ",") {
+		return false, nil
+	}
+	logger.Info("subscribed topics change", "pattern", c.pattern.String(), "from", c.subscription, "to", topics)
+	c.subscription = topics
+	return true, nil
+}
+
+// subscribedTopics returns the topics subscribed by this consumer
+func (c *GroupConsumer) subscribedTopics() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.subscription
+}
+
 func (c *GroupConsumer) refreshMeta() {
 	var (
 		ticker *time.Ticker = time.NewTicker(time.Millisecond * time.Duration(c.config.MetadataMaxAgeMS))
@@ -440,6 +815,16 @@ func (c *GroupConsumer) refreshMeta() {
 		if c.closed {
 			return
 		}
+
+		changed, err := c.updateSubscription()
+		if err != nil {
+			logger.Error(err, "update subscription (in goroutine) failed", "pattern", c.pattern)
+		}
+		if changed {
+			c.restartOrRebalance(nil)
+			continue
+		}
+
 		if !c.ifLeader {
 			continue
 		}
@@ -455,7 +840,7 @@ func (c *GroupConsumer) refreshMeta() {
 		if !ifTopicMetadatasSame(c.topicMetadatas, metaDataResponse.TopicMetadatas) {
 			logger.Info("metadata changed, restart group consumer")
 			c.topicMetadatas = metaDataResponse.TopicMetadatas
-			c.restart()
+			c.restartOrRebalance(nil)
 		}
 	}
 }
@@ -470,6 +855,10 @@ func (c *GroupConsumer) Consume(messages chan *FullMessage) (<-chan *FullMessage
 	}
 	c.messages = messages
 
+	if _, err := c.updateSubscription(); err != nil {
+		return nil, fmt.Errorf("get topics matching %s error: %w", c.pattern, err)
+	}
+
 	// go heartbeat
 	ticker := time.NewTicker(time.Millisecond * time.Duration(c.config.SessionTimeoutMS) / 10)
 	go func() {
@@ -480,7 +869,7 @@ func (c *GroupConsumer) Consume(messages chan *FullMessage) (<-chan *FullMessage
 			err := c.heartbeat()
 			if err != nil {
 				logger.Error(err, "failed to send heartbeat, restarts")
-				c.restart()
+				c.restartOrRebalance(err)
 			}
 		}
 	}()
@@ -496,14 +885,24 @@ func (c *GroupConsumer) Consume(messages chan *FullMessage) (<-chan *FullMessage
 func (c *GroupConsumer) consumeWithoutHeartBeat(fromBeginning bool) (chan *FullMessage, error) {
 
 	/* if groupconsumer restarts,
-	it should wait all simple consumers stoped */
-	c.wg.Wait()
+	it should wait all simple consumers stoped.
+	cooperative protocol keeps the simple consumers which are not revoked */
+	cooperative := isCooperative(c.assignmentStrategy)
+	if !cooperative {
+		c.wg.Wait()
+	}
 
 	var err error
 	joinedChan := make(chan bool, 1)
 	go func() {
 		for !c.closed {
+			c.revoked = 0
 			err = c.joinAndSync()
+			if err == nil && c.revoked > 0 {
+				// revoked partitions are assigned to other members in the next round
+				logger.Info("partitions revoked, rejoin group", "count", c.revoked)
+				continue
+			}
 			if err == nil {
 				break
 			} else {
@@ -521,8 +920,13 @@ func (c *GroupConsumer) consumeWithoutHeartBeat(fromBeginning bool) (chan *FullM
 
 	c.joined = true
 
+	// simple consumers are started in applyAssignments in cooperative protocol
+	if cooperative {
+		return c.messages, nil
+	}
+
 	// consume
-	for _, simpleConsumer := range c.simpleConsumers {
+	for _, simpleConsumer := range c.assignedConsumers() {
 		var offset int64
 		if fromBeginning {
 			offset = -2
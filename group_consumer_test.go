@@ -2,9 +2,14 @@ package healer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bytedance/mockey"
 	"github.com/smartystreets/goconvey/convey"
@@ -37,9 +42,9 @@ func TestStopGroupConsumer(t *testing.T) {
 			return nil
 		}).Build()
 
-		mockey.Mock((*Broker).requestFetchStreamingly).
+		mockey.Mock((*Broker).requestFetch).
 			To(func(fetchRequest *FetchRequest) (r io.Reader, responseLength uint32, err error) {
-				t.Log("mock requestFetchStreamingly")
+				t.Log("mock requestFetch")
 				return nil, 0, nil
 			}).Build()
 
@@ -85,3 +90,223 @@ func TestStopGroupConsumer(t *testing.T) {
 		t.Log("stopped")
 	})
 }
+
+func TestStaticMember(t *testing.T) {
+	mockey.PatchConvey("static member does not send LeaveGroup", t, func() {
+		leaveCalled := false
+		mockey.Mock((*Broker).requestLeaveGroup).To(func(broker *Broker, clientID, groupID string, memberID string) (LeaveGroupResponse, error) {
+			leaveCalled = true
+			return LeaveGroupResponse{}, nil
+		}).Build()
+
+		c := &GroupConsumer{coordinator: &Broker{}, joined: true, memberID: "member-0"}
+		c.config.GroupInstanceID = "pod-0"
+		c.leave()
+		convey.So(leaveCalled, convey.ShouldBeFalse)
+		convey.So(c.memberID, convey.ShouldEqual, "member-0")
+
+		c.config.GroupInstanceID = ""
+		c.leave()
+		convey.So(leaveCalled, convey.ShouldBeTrue)
+		convey.So(c.memberID, convey.ShouldEqual, "")
+	})
+
+	mockey.PatchConvey("join again with the memberID assigned by coordinator", t, func() {
+		var (
+			memberIDs        []string
+			groupInstanceIDs []*string
+		)
+		mockey.Mock((*Broker).requestJoinGroup).To(func(broker *Broker, clientID, groupID string, sessionTimeoutMS int32, memberID string, groupInstanceID *string, protocolType string, gps []*GroupProtocol) (JoinGroupResponse, error) {
+			memberIDs = append(memberIDs, memberID)
+			groupInstanceIDs = append(groupInstanceIDs, groupInstanceID)
+			if memberID == "" {
+				return JoinGroupResponse{ErrorCode: 79, MemberID: "member-0"}, KafkaError(79)
+			}
+			return JoinGroupResponse{GenerationID: 1, GroupProtocol: "range", LeaderID: "member-1", MemberID: memberID}, nil
+		}).Build()
+		mockey.Mock((*GroupConsumer).sync).Return(nil).Build()
+
+		c := &GroupConsumer{mutex: &sync.Mutex{}, coordinator: &Broker{}, coordinatorAvailable: true, assignmentStrategies: []AssignmentStrategy{&rangeAssignmentStrategy{}}}
+		c.config.GroupInstanceID = "pod-0"
+		err := c.joinAndSync()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(memberIDs, convey.ShouldResemble, []string{"", "member-0"})
+		convey.So(*groupInstanceIDs[1], convey.ShouldEqual, "pod-0")
+		convey.So(c.memberID, convey.ShouldEqual, "member-0")
+		convey.So(c.generationID, convey.ShouldEqual, 1)
+		convey.So(c.ifLeader, convey.ShouldBeFalse)
+	})
+}
+
+func TestCooperativeApplyAssignments(t *testing.T) {
+	mockey.PatchConvey("only revoked partitions stop consuming", t, func() {
+		var (
+			stopped []int32
+			started []int32
+		)
+		mockey.Mock(NewSimpleConsumerWithBrokers).To(func(topic string, partitionID int32, config ConsumerConfig, brokers *Brokers) *SimpleConsumer {
+			return &SimpleConsumer{topic: topic, partitionID: partitionID}
+		}).Build()
+		mockey.Mock((*SimpleConsumer).Consume).To(func(s *SimpleConsumer, offset int64, messageChan chan *FullMessage) (<-chan *FullMessage, error) {
+			started = append(started, s.partitionID)
+			return messageChan, nil
+		}).Build()
+		mockey.Mock((*SimpleConsumer).Stop).To(func(s *SimpleConsumer) {
+			stopped = append(stopped, s.partitionID)
+		}).Build()
+
+		c := &GroupConsumer{mutex: &sync.Mutex{}, assignmentStrategy: &cooperativeStickyAssignmentStrategy{}}
+		kept := &SimpleConsumer{topic: "test", partitionID: 0}
+		c.simpleConsumers = []*SimpleConsumer{kept, {topic: "test", partitionID: 1}}
+		convey.So(c.ownedPartitions(), convey.ShouldResemble, []*PartitionAssignment{{Topic: "test", Partitions: []int32{0, 1}}})
+
+		err := c.parseGroupAssignments((&MemberAssignment{PartitionAssignments: []*PartitionAssignment{{Topic: "test", Partitions: []int32{0, 2}}}}).Encode())
+		convey.So(err, convey.ShouldBeNil)
+		time.Sleep(10 * time.Millisecond)
+
+		convey.So(stopped, convey.ShouldResemble, []int32{1})
+		convey.So(started, convey.ShouldResemble, []int32{2})
+		convey.So(c.revoked, convey.ShouldEqual, 1)
+		convey.So(len(c.simpleConsumers), convey.ShouldEqual, 2)
+		convey.So(c.simpleConsumers[0], convey.ShouldEqual, kept)
+		convey.So(c.simpleConsumers[1].partitionID, convey.ShouldEqual, 2)
+	})
+}
+
+func TestNegotiateAssignmentStrategy(t *testing.T) {
+	mockey.PatchConvey("advertise all strategies and use the one chosen by coordinator", t, func() {
+		var protocols []string
+		mockey.Mock((*Broker).requestJoinGroup).To(func(broker *Broker, clientID, groupID string, sessionTimeoutMS int32, memberID string, groupInstanceID *string, protocolType string, gps []*GroupProtocol) (JoinGroupResponse, error) {
+			protocols = protocols[:0]
+			for _, gp := range gps {
+				protocols = append(protocols, gp.ProtocolName)
+			}
+			return JoinGroupResponse{GenerationID: 1, GroupProtocol: "roundrobin", LeaderID: "member-0", MemberID: "member-0"}, nil
+		}).Build()
+
+		strategies, _ := getAssignmentStrategies("sticky,roundrobin")
+		c := &GroupConsumer{mutex: &sync.Mutex{}, coordinator: &Broker{}, assignmentStrategies: strategies, assignmentStrategy: strategies[0]}
+		err := c.join()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(protocols, convey.ShouldResemble, []string{"sticky", "roundrobin"})
+		convey.So(c.assignmentStrategy.Name(), convey.ShouldEqual, "roundrobin")
+
+		c.assignmentStrategies = strategies[:1]
+		err = c.join()
+		convey.So(errors.Is(err, errUnknownAssignmentStrategy), convey.ShouldBeTrue)
+	})
+}
+
+func TestPatternSubscription(t *testing.T) {
+	mockey.PatchConvey("topics matching the pattern are subscribed", t, func() {
+		topics := []string{"events.click", "__consumer_offsets", "logs", "events.view"}
+		mockey.Mock((*Brokers).RequestMetaData).To(func(brokers *Brokers, clientID string, ts []string) (MetadataResponse, error) {
+			r := MetadataResponse{}
+			for _, topic := range topics {
+				r.TopicMetadatas = append(r.TopicMetadatas, TopicMetadata{TopicName: topic, IsInternal: strings.HasPrefix(topic, "__")})
+			}
+			return r, nil
+		}).Build()
+
+		_, err := NewGroupConsumerWithPattern("events.(", nil)
+		convey.So(err, convey.ShouldNotBeNil)
+		_, err = NewGroupConsumerWithTopics(nil, nil)
+		convey.So(err, convey.ShouldEqual, errNoTopicToSubscribe)
+
+		c := &GroupConsumer{mutex: &sync.Mutex{}, brokers: &Brokers{}, pattern: regexp.MustCompile(`^events\..*|^__.*`)}
+		changed, err := c.updateSubscription()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(changed, convey.ShouldBeTrue)
+		convey.So(c.subscription, convey.ShouldResemble, []string{"events.click", "events.view"})
+
+		changed, _ = c.updateSubscription()
+		convey.So(changed, convey.ShouldBeFalse)
+
+		topics = append(topics, "events.buy")
+		changed, _ = c.updateSubscription()
+		convey.So(changed, convey.ShouldBeTrue)
+		convey.So(c.subscription, convey.ShouldResemble, []string{"events.buy", "events.click", "events.view"})
+	})
+
+	mockey.PatchConvey("all subscribed topics are sent in JoinGroup request", t, func() {
+		var subscription []string
+		mockey.Mock((*Broker).requestJoinGroup).To(func(broker *Broker, clientID, groupID string, sessionTimeoutMS int32, memberID string, groupInstanceID *string, protocolType string, gps []*GroupProtocol) (JoinGroupResponse, error) {
+			subscription = NewProtocolMetadata(gps[0].ProtocolMetadata).Subscription
+			return JoinGroupResponse{GenerationID: 1, GroupProtocol: "range", LeaderID: "member-0", MemberID: "member-1"}, nil
+		}).Build()
+
+		c := &GroupConsumer{mutex: &sync.Mutex{}, coordinator: &Broker{}, subscription: []string{"a", "b"}, assignmentStrategies: []AssignmentStrategy{&rangeAssignmentStrategy{}}}
+		convey.So(c.join(), convey.ShouldBeNil)
+		convey.So(subscription, convey.ShouldResemble, []string{"a", "b"})
+	})
+}
+
+type testRebalanceListener struct {
+	events *[]string
+}
+
+func (l testRebalanceListener) record(event string, partitions []*PartitionAssignment) {
+	for _, p := range partitions {
+		event += fmt.Sprintf(" %s:%v", p.Topic, p.Partitions)
+	}
+	*l.events = append(*l.events, event)
+}
+
+func (l testRebalanceListener) OnPartitionsRevoked(partitions []*PartitionAssignment) {
+	l.record("revoked", partitions)
+}
+
+func (l testRebalanceListener) OnPartitionsAssigned(partitions []*PartitionAssignment) {
+	l.record("assigned", partitions)
+}
+
+func (l testRebalanceListener) OnPartitionsLost(partitions []*PartitionAssignment) {
+	l.record("lost", partitions)
+}
+
+func TestRebalanceListener(t *testing.T) {
+	mockey.PatchConvey("listener is called around stop and assignment", t, func() {
+		var events []string
+		mockey.Mock(NewSimpleConsumerWithBrokers).To(func(topic string, partitionID int32, config ConsumerConfig, brokers *Brokers) *SimpleConsumer {
+			return &SimpleConsumer{topic: topic, partitionID: partitionID}
+		}).Build()
+		mockey.Mock((*SimpleConsumer).Consume).To(func(s *SimpleConsumer, offset int64, messageChan chan *FullMessage) (<-chan *FullMessage, error) {
+			events = append(events, fmt.Sprintf("consume %d", s.partitionID))
+			return messageChan, nil
+		}).Build()
+		mockey.Mock((*SimpleConsumer).Stop).To(func(s *SimpleConsumer) {
+			events = append(events, fmt.Sprintf("stop %d", s.partitionID))
+		}).Build()
+		mockey.Mock((*SimpleConsumer).CommitOffset).To(func(s *SimpleConsumer) {
+			events = append(events, fmt.Sprintf("commit %d", s.partitionID))
+		}).Build()
+
+		// eager
+		c := &GroupConsumer{mutex: &sync.Mutex{}, config: ConsumerConfig{AutoCommit: true}, assignmentStrategy: &rangeAssignmentStrategy{}}
+		c.SetRebalanceListener(testRebalanceListener{&events})
+		c.simpleConsumers = []*SimpleConsumer{{topic: "test", partitionID: 0}, {topic: "test", partitionID: 1}}
+		c.stop(false)
+		convey.So(events, convey.ShouldResemble, []string{"stop 0", "stop 1", "revoked test:[0 1]", "commit 0", "commit 1"})
+		convey.So(c.simpleConsumers, convey.ShouldBeNil)
+
+		events = nil
+		err := c.parseGroupAssignments((&MemberAssignment{PartitionAssignments: []*PartitionAssignment{{Topic: "test", Partitions: []int32{2}}}}).Encode())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(events, convey.ShouldResemble, []string{"assigned test:[2]"})
+
+		// fenced out of the group, offsets are not committed
+		events = nil
+		c.stop(true)
+		convey.So(events, convey.ShouldResemble, []string{"stop 2", "lost test:[2]"})
+
+		// cooperative, only revoked and newly assigned partitions are notified
+		events = nil
+		c = &GroupConsumer{mutex: &sync.Mutex{}, config: ConsumerConfig{AutoCommit: true}, assignmentStrategy: &cooperativeStickyAssignmentStrategy{}}
+		c.SetRebalanceListener(testRebalanceListener{&events})
+		c.simpleConsumers = []*SimpleConsumer{{topic: "test", partitionID: 0}, {topic: "test", partitionID: 1}}
+		err = c.parseGroupAssignments((&MemberAssignment{PartitionAssignments: []*PartitionAssignment{{Topic: "test", Partitions: []int32{0, 2}}}}).Encode())
+		convey.So(err, convey.ShouldBeNil)
+		time.Sleep(10 * time.Millisecond)
+		convey.So(events, convey.ShouldResemble, []string{"stop 1", "revoked test:[1]", "commit 1", "assigned test:[2]", "consume 2"})
+	})
+}
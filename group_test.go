@@ -0,0 +1,30 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestProtocolMetadata(t *testing.T) {
+	convey.Convey("owned partitions are encoded since version 1", t, func() {
+		m := &ProtocolMetadata{
+			Version:      1,
+			Subscription: []string{"test"},
+			UserData:     []byte{},
+			OwnedPartitions: []*PartitionAssignment{
+				{Topic: "test", Partitions: []int32{0, 2}},
+			},
+		}
+		payload := m.Encode()
+		convey.So(len(payload), convey.ShouldEqual, m.Length())
+		convey.So(NewProtocolMetadata(payload), convey.ShouldResemble, m)
+
+		m.Version = 0
+		payload = m.Encode()
+		convey.So(len(payload), convey.ShouldEqual, m.Length())
+		got := NewProtocolMetadata(payload)
+		convey.So(got.OwnedPartitions, convey.ShouldBeNil)
+		convey.So(got.Subscription, convey.ShouldResemble, []string{"test"})
+	})
+}
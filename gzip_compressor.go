@@ -6,6 +6,7 @@ import (
 )
 
 type GzipCompressor struct {
+	level int
 }
 
 func (c *GzipCompressor) Compress(value []byte) ([]byte, error) {
@@ -13,7 +14,14 @@ func (c *GzipCompressor) Compress(value []byte) ([]byte, error) {
 		buf bytes.Buffer
 		err error
 	)
-	writer := gzip.NewWriter(&buf)
+	level := c.level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	writer, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
 	if _, err = writer.Write(value); err != nil {
 		return nil, err
 	}
@@ -14,14 +14,17 @@ FIELD	DESCRIPTION
 group_id	The unique group identifier
 generation_id	The generation of the group.
 member_id	The member id assigned by the group coordinator or null if joining for the first time.
+
+Heartbeat Request (Version: 3) => group_id generation_id member_id group_instance_id
+  group_instance_id => NULLABLE_STRING
 */
 
-// TODO version0
 type HeartbeatRequest struct {
 	*RequestHeader
-	GroupID      string
-	GenerationID int32
-	MemberID     string
+	GroupID         string
+	GenerationID    int32
+	MemberID        string
+	GroupInstanceID *string // static membership, since version 3
 }
 
 func NewHeartbeatRequest(clientID, groupID string, generationID int32, memberID string) *HeartbeatRequest {
@@ -40,6 +43,9 @@ func NewHeartbeatRequest(clientID, groupID string, generationID int32, memberID
 
 func (heartbeatR *HeartbeatRequest) Length() int {
 	requestLength := heartbeatR.RequestHeader.length() + 2 + len(heartbeatR.GroupID) + 4 + 2 + len(heartbeatR.MemberID)
+	if heartbeatR.Version() >= 3 {
+		requestLength += len(encodeNullableString(heartbeatR.GroupInstanceID))
+	}
 	return requestLength
 }
 
@@ -63,7 +69,11 @@ func (heartbeatR *HeartbeatRequest) Encode(version uint16) []byte {
 
 	binary.BigEndian.PutUint16(payload[offset:], uint16(len(heartbeatR.MemberID)))
 	offset += 2
-	copy(payload[offset:], heartbeatR.MemberID)
+	offset += copy(payload[offset:], heartbeatR.MemberID)
+
+	if heartbeatR.Version() >= 3 {
+		copy(payload[offset:], encodeNullableString(heartbeatR.GroupInstanceID))
+	}
 
 	return payload
 }
@@ -6,15 +6,16 @@ import (
 )
 
 type HeartbeatResponse struct {
-	CorrelationID uint32
-	ErrorCode     int16
+	CorrelationID  uint32
+	ThrottleTimeMS int32 // since version 1
+	ErrorCode      int16
 }
 
 func (r HeartbeatResponse) Error() error {
 	return getErrorFromErrorCode(r.ErrorCode)
 }
 
-func NewHeartbeatResponse(payload []byte) (r HeartbeatResponse, err error) {
+func NewHeartbeatResponse(payload []byte, version uint16) (r HeartbeatResponse, err error) {
 	offset := 0
 	responseLength := int(binary.BigEndian.Uint32(payload))
 	if responseLength+4 != len(payload) {
@@ -25,6 +26,11 @@ func NewHeartbeatResponse(payload []byte) (r HeartbeatResponse, err error) {
 	r.CorrelationID = uint32(binary.BigEndian.Uint32(payload[offset:]))
 	offset += 4
 
+	if version >= 1 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
 	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
 
 	if r.ErrorCode != 0 {
@@ -0,0 +1,154 @@
+package healer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+)
+
+/*
+InitProducerId Request (Version: 0) => transactional_id transaction_timeout_ms
+  transactional_id => NULLABLE_STRING
+  transaction_timeout_ms => INT32
+
+InitProducerId Request (Version: 3) => transactional_id transaction_timeout_ms producer_id producer_epoch TAG_BUFFER
+  transactional_id => COMPACT_NULLABLE_STRING
+  transaction_timeout_ms => INT32
+  producer_id => INT64
+  producer_epoch => INT16
+
+version 2+ is flexible. producer_id and producer_epoch (version 3+) are used to bump the epoch of an existing producer,
+they should be -1 if the producer is new.
+*/
+
+// InitProducerIDRequest is used to get a producer id (and epoch) for idempotent or transactional producer
+type InitProducerIDRequest struct {
+	*RequestHeader
+	TransactionalID      *string
+	TransactionTimeoutMS int32
+	ProducerID           int64 `healer:"minVersion:3"`
+	ProducerEpoch        int16 `healer:"minVersion:3"`
+	TaggedFields         TaggedFields
+}
+
+var tagsCacheInitProducerIDRequest atomic.Value
+
+// NewInitProducerIDRequest creates a new InitProducerIDRequest. transactionalID is nil for idempotent producer
+func NewInitProducerIDRequest(clientID string, transactionalID *string, transactionTimeoutMS int32) *InitProducerIDRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_InitProducerId,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &InitProducerIDRequest{
+		RequestHeader:        requestHeader,
+		TransactionalID:      transactionalID,
+		TransactionTimeoutMS: transactionTimeoutMS,
+		ProducerID:           -1,
+		ProducerEpoch:        -1,
+	}
+}
+
+func (r *InitProducerIDRequest) tags() (fieldsVersions map[string]uint16) {
+	if v := tagsCacheInitProducerIDRequest.Load(); v != nil {
+		return v.(map[string]uint16)
+	}
+
+	fieldsVersions = healerTags(*r)
+	tagsCacheInitProducerIDRequest.Store(fieldsVersions)
+	return
+}
+
+func (r *InitProducerIDRequest) length() (n int) {
+	n = 4 + r.RequestHeader.length()
+	n += 2 + 4 // TransactionalID length, TransactionTimeoutMS
+	if r.TransactionalID != nil {
+		n += len(*r.TransactionalID)
+	}
+	n += 8 + 2 // ProducerID, ProducerEpoch
+	n += r.TaggedFields.length()
+	return n
+}
+
+// Encode encodes InitProducerIDRequest to []byte
+func (r *InitProducerIDRequest) Encode(version uint16) (payload []byte) {
+	tags := r.tags()
+
+	payload = make([]byte, r.length())
+	offset := 4 // payload length
+
+	defer func() {
+		binary.BigEndian.PutUint32(payload, uint32(offset-4))
+	}()
+
+	offset += r.RequestHeader.EncodeTo(payload[offset:])
+
+	if r.IsFlexible() {
+		offset += copy(payload[offset:], encodeCompactNullableString(r.TransactionalID))
+	} else {
+		offset += copy(payload[offset:], encodeNullableString(r.TransactionalID))
+	}
+
+	binary.BigEndian.PutUint32(payload[offset:], uint32(r.TransactionTimeoutMS))
+	offset += 4
+
+	if version >= tags["ProducerID"] {
+		binary.BigEndian.PutUint64(payload[offset:], uint64(r.ProducerID))
+		offset += 8
+	}
+	if version >= tags["ProducerEpoch"] {
+		binary.BigEndian.PutUint16(payload[offset:], uint16(r.ProducerEpoch))
+		offset += 2
+	}
+
+	if r.IsFlexible() {
+		offset += r.TaggedFields.EncodeTo(payload[offset:])
+	}
+
+	return payload[:offset]
+}
+
+// DecodeInitProducerIDRequest decodes []byte to InitProducerIDRequest, just used in test
+func DecodeInitProducerIDRequest(payload []byte) (r *InitProducerIDRequest, err error) {
+	r = &InitProducerIDRequest{}
+	offset := 0
+	var o int
+	tags := r.tags()
+
+	requestLength := int(binary.BigEndian.Uint32(payload))
+	offset += 4
+	if requestLength != len(payload)-4 {
+		return nil, fmt.Errorf("request length did not match actual size")
+	}
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+	version := r.APIVersion
+
+	if r.IsFlexible() {
+		r.TransactionalID, o = compactNullableString(payload[offset:])
+	} else {
+		r.TransactionalID, o = nullableString(payload[offset:])
+	}
+	offset += o
+
+	r.TransactionTimeoutMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	if version >= tags["ProducerID"] {
+		r.ProducerID = int64(binary.BigEndian.Uint64(payload[offset:]))
+		offset += 8
+	}
+	if version >= tags["ProducerEpoch"] {
+		r.ProducerEpoch = int16(binary.BigEndian.Uint16(payload[offset:]))
+		offset += 2
+	}
+
+	if r.IsFlexible() {
+		r.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	return r, nil
+}
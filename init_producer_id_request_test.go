@@ -0,0 +1,37 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestInitProducerIDRequestEncodeDecode(t *testing.T) {
+	convey.Convey("Test InitProducerIDRequest Encode and Decode", t, func() {
+		clientID := "testClient"
+		transactionalID := "txn-1"
+
+		for _, version := range availableVersions[API_InitProducerId] {
+			t.Logf("version: %v", version)
+
+			for _, txnID := range []*string{nil, &transactionalID} {
+				request := NewInitProducerIDRequest(clientID, txnID, 60000)
+				request.APIVersion = version
+				request.TaggedFields = mockTaggedFields(request.IsFlexible())
+				request.ProducerID = 100
+				request.ProducerEpoch = 2
+
+				if version < request.tags()["ProducerID"] {
+					request.ProducerID = 0
+					request.ProducerEpoch = 0
+				}
+
+				encoded := request.Encode(version)
+				decoded, err := DecodeInitProducerIDRequest(encoded)
+
+				convey.So(err, convey.ShouldBeNil)
+				convey.So(decoded, convey.ShouldResemble, request)
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package healer
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+InitProducerId Response (Version: 0) => throttle_time_ms error_code producer_id producer_epoch
+  throttle_time_ms => INT32
+  error_code => INT16
+  producer_id => INT64
+  producer_epoch => INT16
+
+version 2+ is flexible, a TAG_BUFFER is appended
+*/
+
+// InitProducerIDResponse is the response of InitProducerIDRequest
+type InitProducerIDResponse struct {
+	ResponseHeader
+	ThrottleTimeMS int32
+	ErrorCode      int16
+	ProducerID     int64
+	ProducerEpoch  int16
+	TaggedFields   TaggedFields
+}
+
+func (r InitProducerIDResponse) Error() error {
+	return getErrorFromErrorCode(r.ErrorCode)
+}
+
+// NewInitProducerIDResponse creates a new InitProducerIDResponse from []byte
+func NewInitProducerIDResponse(payload []byte, version uint16) (r InitProducerIDResponse, err error) {
+	offset := 0
+	o := 0
+
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("InitProducerId response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.ResponseHeader, o = DecodeResponseHeader(payload[offset:], API_InitProducerId, version)
+	offset += o
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	r.ProducerID = int64(binary.BigEndian.Uint64(payload[offset:]))
+	offset += 8
+
+	r.ProducerEpoch = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	if r.IsFlexible() {
+		r.TaggedFields, o = DecodeTaggedFields(payload[offset:])
+		offset += o
+	}
+
+	return r, nil
+}
+
+// Encode encodes InitProducerIDResponse to []byte, just for test
+func (r *InitProducerIDResponse) Encode(version uint16) (payload []byte) {
+	payload = make([]byte, 4+r.ResponseHeader.length()+16+r.TaggedFields.length())
+	offset := 4 // payload length
+
+	defer func() {
+		binary.BigEndian.PutUint32(payload, uint32(offset-4))
+	}()
+
+	offset += r.ResponseHeader.EncodeTo(payload[offset:])
+
+	binary.BigEndian.PutUint32(payload[offset:], uint32(r.ThrottleTimeMS))
+	offset += 4
+
+	binary.BigEndian.PutUint16(payload[offset:], uint16(r.ErrorCode))
+	offset += 2
+
+	binary.BigEndian.PutUint64(payload[offset:], uint64(r.ProducerID))
+	offset += 8
+
+	binary.BigEndian.PutUint16(payload[offset:], uint16(r.ProducerEpoch))
+	offset += 2
+
+	if r.IsFlexible() {
+		offset += r.TaggedFields.EncodeTo(payload[offset:])
+	}
+
+	return payload[:offset]
+}
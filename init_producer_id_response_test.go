@@ -0,0 +1,34 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestInitProducerIDResponseEncodeDecode(t *testing.T) {
+	convey.Convey("Test InitProducerIDResponse Encode and Decode", t, func() {
+		for _, version := range availableVersions[API_InitProducerId] {
+			t.Logf("version: %v", version)
+
+			header := NewResponseHeader(API_InitProducerId, version)
+			header.CorrelationID = 1
+			header.TaggedFields = mockTaggedFields(header.IsFlexible())
+
+			res := &InitProducerIDResponse{
+				ResponseHeader: header,
+				ThrottleTimeMS: 100,
+				ErrorCode:      0,
+				ProducerID:     1000,
+				ProducerEpoch:  3,
+				TaggedFields:   mockTaggedFields(header.IsFlexible()),
+			}
+
+			payload := res.Encode(version)
+			decoded, err := NewInitProducerIDResponse(payload, version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decoded, convey.ShouldResemble, res)
+			convey.So(decoded.Error(), convey.ShouldBeNil)
+		}
+	})
+}
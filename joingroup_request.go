@@ -6,6 +6,17 @@ import (
 
 /*
 https://kafka.apache.org/protocol.html#The_Messages_JoinGroup
+
+JoinGroup Request (Version: 5) => group_id session_timeout_ms rebalance_timeout_ms member_id group_instance_id protocol_type [protocols]
+  group_id => STRING
+  session_timeout_ms => INT32
+  rebalance_timeout_ms => INT32
+  member_id => STRING
+  group_instance_id => NULLABLE_STRING
+  protocol_type => STRING
+  protocols => name metadata
+    name => STRING
+    metadata => BYTES
 */
 
 // JoinGroupRequest struct holds params in JoinGroupRequest
@@ -15,6 +26,7 @@ type JoinGroupRequest struct {
 	SessionTimeout   int32 // ms
 	RebalanceTimeout int32 // ms. this is NOT included in verions 0
 	MemberID         string
+	GroupInstanceID  *string // static membership, since version 5
 	ProtocolType     string
 	GroupProtocols   []*GroupProtocol
 }
@@ -46,9 +58,12 @@ func (r *JoinGroupRequest) AddGroupProtocal(gp *GroupProtocol) {
 
 func (r *JoinGroupRequest) length() int {
 	l := r.RequestHeader.length() + 2 + len(r.GroupID) + 4 + 2 + len(r.MemberID) + 2 + len(r.ProtocolType)
-	if r.RequestHeader.APIVersion == 1 || r.RequestHeader.APIVersion == 2 {
+	if r.Version() >= 1 {
 		l += 4 // RebalanceTimeout
 	}
+	if r.Version() >= 5 {
+		l += len(encodeNullableString(r.GroupInstanceID))
+	}
 	l += 4
 	for _, gp := range r.GroupProtocols {
 		l += 2 + len(gp.ProtocolName)
@@ -76,7 +91,7 @@ func (r *JoinGroupRequest) Encode(version uint16) []byte {
 	binary.BigEndian.PutUint32(payload[offset:], uint32(r.SessionTimeout))
 	offset += 4
 
-	if r.Version() == 1 || r.Version() == 2 {
+	if r.Version() >= 1 {
 		binary.BigEndian.PutUint32(payload[offset:], uint32(r.RebalanceTimeout))
 		offset += 4
 	}
@@ -85,6 +100,10 @@ func (r *JoinGroupRequest) Encode(version uint16) []byte {
 	offset += 2
 	offset += copy(payload[offset:], r.MemberID)
 
+	if r.Version() >= 5 {
+		offset += copy(payload[offset:], encodeNullableString(r.GroupInstanceID))
+	}
+
 	binary.BigEndian.PutUint16(payload[offset:], uint16(len(r.ProtocolType)))
 	offset += 2
 	offset += copy(payload[offset:], r.ProtocolType)
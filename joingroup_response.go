@@ -1,6 +1,7 @@
 package healer
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 )
@@ -25,26 +26,32 @@ import (
 //member_id	The member id assigned by the group coordinator or null if joining for the first time.
 //member_metadata	null
 
-// version 0
+//JoinGroup Response (Version: 5) => throttle_time_ms error_code generation_id protocol_name leader member_id [members]
+//throttle_time_ms => INT32 (since version 2)
+//members => member_id group_instance_id metadata
+//group_instance_id => NULLABLE_STRING (since version 5)
+
 type Member struct {
-	MemberID       string
-	MemberMetadata []byte
+	MemberID        string
+	GroupInstanceID *string // since version 5
+	MemberMetadata  []byte
 }
 type JoinGroupResponse struct {
-	CorrelationID uint32
-	ErrorCode     int16
-	GenerationID  int32
-	GroupProtocol string
-	LeaderID      string
-	MemberID      string
-	Members       []Member
+	CorrelationID  uint32
+	ThrottleTimeMS int32 // since version 2
+	ErrorCode      int16
+	GenerationID   int32
+	GroupProtocol  string
+	LeaderID       string
+	MemberID       string
+	Members        []Member
 }
 
 func (r JoinGroupResponse) Error() error {
 	return getErrorFromErrorCode(r.ErrorCode)
 }
 
-func NewJoinGroupResponse(payload []byte) (r JoinGroupResponse, err error) {
+func NewJoinGroupResponse(payload []byte, version uint16) (r JoinGroupResponse, err error) {
 	offset := 0
 	responseLength := int(binary.BigEndian.Uint32(payload))
 	if responseLength+4 != len(payload) {
@@ -55,6 +62,11 @@ func NewJoinGroupResponse(payload []byte) (r JoinGroupResponse, err error) {
 	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
 	offset += 4
 
+	if version >= 2 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
 	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
 	offset += 2
 	if r.ErrorCode != 0 {
@@ -91,6 +103,11 @@ func NewJoinGroupResponse(payload []byte) (r JoinGroupResponse, err error) {
 		r.Members[i].MemberID = string(payload[offset : offset+l])
 		offset += l
 
+		if version >= 5 {
+			r.Members[i].GroupInstanceID, l = nullableString(payload[offset:])
+			offset += l
+		}
+
 		ll := int(binary.BigEndian.Uint32(payload[offset:]))
 		offset += 4
 		r.Members[i].MemberMetadata = make([]byte, ll)
@@ -100,3 +117,35 @@ func NewJoinGroupResponse(payload []byte) (r JoinGroupResponse, err error) {
 
 	return r, err
 }
+
+// Encode encodes JoinGroupResponse to []byte, just for test
+func (r JoinGroupResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	if version >= 2 {
+		binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+	}
+	binary.Write(buf, binary.BigEndian, r.ErrorCode)
+	binary.Write(buf, binary.BigEndian, r.GenerationID)
+	writeString(buf, r.GroupProtocol)
+	writeString(buf, r.LeaderID)
+	writeString(buf, r.MemberID)
+	binary.Write(buf, binary.BigEndian, int32(len(r.Members)))
+	for _, m := range r.Members {
+		writeString(buf, m.MemberID)
+		if version >= 5 {
+			writeNullableString(buf, m.GroupInstanceID)
+		}
+		binary.Write(buf, binary.BigEndian, int32(len(m.MemberMetadata)))
+		buf.Write(m.MemberMetadata)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,96 @@
+package healer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestJoinGroupRequestGroupInstanceID(t *testing.T) {
+	convey.Convey("group_instance_id is only encoded since version 5", t, func() {
+		instanceID := "pod-0"
+		for _, version := range []uint16{0, 1, 4, 5} {
+			r := NewJoinGroupRequest(version, "healer")
+			r.GroupID = "test-group"
+			r.SessionTimeout = 10000
+			r.RebalanceTimeout = 60000
+			r.ProtocolType = "consumer"
+			r.GroupInstanceID = &instanceID
+			r.AddGroupProtocal(&GroupProtocol{"range", []byte{0, 1}})
+
+			payload := r.Encode(version)
+			convey.So(len(payload), convey.ShouldEqual, r.length()+4)
+			convey.So(bytes.Contains(payload, []byte(instanceID)), convey.ShouldEqual, version >= 5)
+		}
+
+		r := NewJoinGroupRequest(5, "healer")
+		payload := r.Encode(5)
+		convey.So(len(payload), convey.ShouldEqual, r.length()+4)
+	})
+}
+
+func TestJoinGroupResponse(t *testing.T) {
+	convey.Convey("decode join group response", t, func() {
+		instanceID := "pod-0"
+		for _, version := range []uint16{0, 2, 5} {
+			want := JoinGroupResponse{
+				CorrelationID: 1,
+				GenerationID:  3,
+				GroupProtocol: "range",
+				LeaderID:      "member-0",
+				MemberID:      "member-1",
+				Members: []Member{
+					{MemberID: "member-0", MemberMetadata: []byte{0, 1}},
+					{MemberID: "member-1", MemberMetadata: []byte{2, 3}},
+				},
+			}
+			if version >= 2 {
+				want.ThrottleTimeMS = 10
+			}
+			if version >= 5 {
+				want.Members[1].GroupInstanceID = &instanceID
+			}
+
+			got, err := NewJoinGroupResponse(want.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldResemble, want)
+		}
+
+		r := JoinGroupResponse{ErrorCode: 79, MemberID: "member-1", Members: []Member{}}
+		got, err := NewJoinGroupResponse(r.Encode(5), 5)
+		convey.So(err, convey.ShouldEqual, KafkaError(79))
+		convey.So(got.MemberID, convey.ShouldEqual, "member-1")
+	})
+}
+
+func TestSyncGroupAndHeartbeatGroupInstanceID(t *testing.T) {
+	convey.Convey("sync group and heartbeat send group_instance_id since version 3", t, func() {
+		instanceID := "pod-0"
+		for _, version := range []uint16{0, 3} {
+			syncReq := NewSyncGroupRequest("healer", "test-group", 1, "member-0", GroupAssignment{{"member-0", []byte{1}}})
+			syncReq.GroupInstanceID = &instanceID
+			syncReq.SetVersion(version)
+			payload := syncReq.Encode(version)
+			convey.So(len(payload), convey.ShouldEqual, syncReq.Length()+4)
+			convey.So(bytes.Contains(payload, []byte(instanceID)), convey.ShouldEqual, version >= 3)
+
+			heartbeatReq := NewHeartbeatRequest("healer", "test-group", 1, "member-0")
+			heartbeatReq.GroupInstanceID = &instanceID
+			heartbeatReq.SetVersion(version)
+			payload = heartbeatReq.Encode(version)
+			convey.So(len(payload), convey.ShouldEqual, heartbeatReq.Length()+4)
+			convey.So(bytes.HasSuffix(payload, []byte(instanceID)), convey.ShouldEqual, version >= 3)
+		}
+
+		for _, version := range []uint16{0, 1, 3} {
+			want := SyncGroupResponse{CorrelationID: 1, MemberAssignment: []byte{0, 1, 2}}
+			if version >= 1 {
+				want.ThrottleTimeMS = 10
+			}
+			got, err := NewSyncGroupResponse(want.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldResemble, want)
+		}
+	})
+}
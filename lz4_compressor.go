@@ -1,10 +1,30 @@
 package healer
 
-import "github.com/bkaradzic/go-lz4"
+import (
+	"bytes"
 
+	"github.com/pierrec/lz4"
+)
+
+// LZ4Compressor compresses value in lz4 frame format, which is required by kafka
 type LZ4Compressor struct {
+	level int
 }
 
 func (c *LZ4Compressor) Compress(value []byte) ([]byte, error) {
-	return lz4.Encode(nil, value)
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	writer := lz4.NewWriter(&buf)
+	if c.level > 0 {
+		writer.Header.CompressionLevel = c.level
+	}
+	if _, err = writer.Write(value); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
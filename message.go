@@ -9,8 +9,8 @@ import (
 	"hash/crc32"
 	"io"
 
-	lz4 "github.com/bkaradzic/go-lz4"
 	snappy "github.com/eapache/go-xerial-snappy"
+	"github.com/pierrec/lz4"
 )
 
 var errUncompleteRecord = errors.New("uncomplete Record, The last bytes are not enough to decode the record")
@@ -33,6 +33,9 @@ func decodeHeader(payload []byte) (header RecordHeader, offset int) {
 	valueLength, o := binary.Varint(payload[offset:])
 	header.headerValueLength = int32(valueLength)
 	offset += o
+	if valueLength < 0 {
+		return
+	}
 	header.Value = make([]byte, valueLength)
 	offset += copy(header.Value, payload[offset:offset+int(header.headerValueLength)])
 	return
@@ -124,6 +127,8 @@ type Message struct {
 
 	// only for version 2
 	Headers []RecordHeader
+	// LeaderEpoch is the partition leader epoch of the record batch, -1 for version 0 and 1
+	LeaderEpoch int32
 }
 
 // MessageSet is a batch of messages
@@ -134,6 +139,7 @@ const (
 	COMPRESSION_GZIP   int8 = 1
 	COMPRESSION_SNAPPY int8 = 2
 	COMPRESSION_LZ4    int8 = 3
+	COMPRESSION_ZSTD   int8 = 4
 )
 
 func (message *Message) decompress() ([]byte, error) {
@@ -153,7 +159,9 @@ func (message *Message) decompress() ([]byte, error) {
 	case COMPRESSION_SNAPPY:
 		return snappy.Decode(message.Value)
 	case COMPRESSION_LZ4:
-		return lz4.Decode(nil, message.Value)
+		return io.ReadAll(lz4.NewReader(bytes.NewReader(message.Value)))
+	case COMPRESSION_ZSTD:
+		return zstdDecompress(message.Value)
 	}
 	return nil, fmt.Errorf("unknown Compression Code %d", compression)
 }
@@ -231,7 +239,7 @@ func DecodeToMessageSet(payload []byte) (MessageSet, error) {
 			break
 		}
 
-		message := &Message{}
+		message := &Message{LeaderEpoch: -1}
 
 		message.Offset = int64(binary.BigEndian.Uint64(payload[offset:]))
 		offset += 8
@@ -3,8 +3,12 @@ package healer
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"hash/crc32"
 )
 
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
 func (r *FetchResponse) Encode(version uint16) ([]byte, error) {
 	buf := new(bytes.Buffer)
 
@@ -86,6 +90,13 @@ func (r *FetchResponse) Encode(version uint16) ([]byte, error) {
 				}
 			}
 
+			// 编码 PreferredReadReplica
+			if version >= 11 {
+				if err := binary.Write(buf, binary.BigEndian, partition.PreferredReadReplica); err != nil {
+					return nil, err
+				}
+			}
+
 			recordBatchBytes, err := partition.RecordBatch.Encode(version)
 			if err != nil {
 				return nil, err
@@ -104,12 +115,27 @@ func (r *FetchResponse) Encode(version uint16) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Encode encodes RecordBatch to []byte. Records are compressed according to the lowest 3 bits of Attributes,
+// BatchLength and CRC are computed here, the values in the struct are ignored.
 func (r *RecordBatch) Encode(version uint16) (payload []byte, err error) {
+	compressor := newCompressorFromCodec(int8(r.Attributes & 0x07))
+	if compressor == nil {
+		return nil, fmt.Errorf("unknown compression codec %d", r.Attributes&0x07)
+	}
+	return r.encode(version, compressor)
+}
+
+func (r *RecordBatch) encode(version uint16, compressor Compressor) (payload []byte, err error) {
 	buf := new(bytes.Buffer)
 
-	// RecordBatch 的 BatchLength 是变长的，所以先预留空间
+	// BatchLength 和 CRC 需要在所有字段编码完之后才能计算, 先预留空间
 	defer func() {
-		binary.BigEndian.PutUint32(payload[8:], uint32(buf.Len()-8))
+		if err != nil {
+			return
+		}
+		binary.BigEndian.PutUint32(payload[8:], uint32(len(payload)-12))
+		crc := crc32.Checksum(payload[21:], crc32cTable)
+		binary.BigEndian.PutUint32(payload[17:], crc)
 	}()
 
 	// 编码 RecordBatch
@@ -154,20 +180,29 @@ func (r *RecordBatch) Encode(version uint16) (payload []byte, err error) {
 	if err := binary.Write(buf, binary.BigEndian, int32(len(r.Records))); err != nil {
 		return nil, err
 	}
+	records := new(bytes.Buffer)
 	for _, record := range r.Records {
 		recordBytes, err := record.Encode(version)
 		if err != nil {
 			return nil, err
 		}
-		if _, err := buf.Write(recordBytes); err != nil {
+		if _, err := records.Write(recordBytes); err != nil {
 			return nil, err
 		}
 	}
+
+	// 压缩是针对所有 Records 整体进行的
+	compressed, err := compressor.Compress(records.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("compress records error: %w", err)
+	}
+	if _, err := buf.Write(compressed); err != nil {
+		return nil, err
+	}
 	return buf.Bytes(), nil
 }
 
 // Encode encodes a record to a byte slice
-// just for test
 func (r *Record) Encode(version uint16) (payload []byte, err error) {
 	buf := new(bytes.Buffer)
 	defer func() {
@@ -178,16 +213,14 @@ func (r *Record) Encode(version uint16) (payload []byte, err error) {
 
 	// 编码 length
 
-	// 编码 attributes
-	attrBuf := make([]byte, binary.MaxVarintLen64)
-	n := binary.PutVarint(attrBuf, int64(r.attributes))
-	if _, err := buf.Write(attrBuf[:n]); err != nil {
+	// 编码 attributes, 它是 int8, 不是 varint
+	if err := buf.WriteByte(byte(r.attributes)); err != nil {
 		return nil, err
 	}
 
 	// 编码 timestampDelta
 	timeBuf := make([]byte, binary.MaxVarintLen64)
-	n = binary.PutVarint(timeBuf, r.timestampDelta)
+	n := binary.PutVarint(timeBuf, r.timestampDelta)
 	if _, err := buf.Write(timeBuf[:n]); err != nil {
 		return nil, err
 	}
@@ -199,9 +232,13 @@ func (r *Record) Encode(version uint16) (payload []byte, err error) {
 		return nil, err
 	}
 
-	// 编码 key 长度
+	// 编码 key 长度, null 用 -1 表示
 	keyLenBuf := make([]byte, binary.MaxVarintLen64)
-	n = binary.PutVarint(keyLenBuf, int64(len(r.key)))
+	if r.key == nil {
+		n = binary.PutVarint(keyLenBuf, -1)
+	} else {
+		n = binary.PutVarint(keyLenBuf, int64(len(r.key)))
+	}
 	if _, err := buf.Write(keyLenBuf[:n]); err != nil {
 		return nil, err
 	}
@@ -211,9 +248,13 @@ func (r *Record) Encode(version uint16) (payload []byte, err error) {
 		return nil, err
 	}
 
-	// 编码 value 长度
+	// 编码 value 长度, null 用 -1 表示
 	valueLenBuf := make([]byte, binary.MaxVarintLen64)
-	n = binary.PutVarint(valueLenBuf, int64(len(r.value)))
+	if r.value == nil {
+		n = binary.PutVarint(valueLenBuf, -1)
+	} else {
+		n = binary.PutVarint(valueLenBuf, int64(len(r.value)))
+	}
 	if _, err := buf.Write(valueLenBuf[:n]); err != nil {
 		return nil, err
 	}
@@ -244,9 +285,13 @@ func (r *Record) Encode(version uint16) (payload []byte, err error) {
 			return nil, err
 		}
 
-		// 编码 Value 长度
+		// 编码 Value 长度, null 用 -1 表示
 		valueLenBuf := make([]byte, binary.MaxVarintLen64)
-		n = binary.PutVarint(valueLenBuf, int64(len(header.Value)))
+		if header.Value == nil {
+			n = binary.PutVarint(valueLenBuf, -1)
+		} else {
+			n = binary.PutVarint(valueLenBuf, int64(len(header.Value)))
+		}
 		if _, err := buf.Write(valueLenBuf[:n]); err != nil {
 			return nil, err
 		}
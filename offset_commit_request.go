@@ -4,9 +4,28 @@ import (
 	"encoding/binary"
 )
 
+/*
+OffsetCommit Request (Version: 7) => group_id generation_id member_id group_instance_id [topics]
+  group_id => STRING
+  generation_id => INT32 (since version 1)
+  member_id => STRING (since version 1)
+  retention_time_ms => INT64 (version 2 to 4)
+  group_instance_id => NULLABLE_STRING (since version 7)
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index committed_offset committed_leader_epoch committed_metadata
+      partition_index => INT32
+      committed_offset => INT64
+      committed_leader_epoch => INT32 (since version 6)
+      committed_metadata => NULLABLE_STRING
+
+version 1 is not supported
+*/
+
 type OffsetCommitRequestPartition struct {
 	PartitionID int32
 	Offset      int64
+	LeaderEpoch int32 // since version 6, -1 means unknown
 	Metadata    string
 }
 type OffsetCommitRequestTopic struct {
@@ -20,7 +39,9 @@ type OffsetCommitRequest struct {
 	GenerationID  int32
 	MemberID      string
 	RetentionTime int64
-	Topics        []*OffsetCommitRequestTopic
+	// GroupInstanceID is set by static members, since version 7
+	GroupInstanceID *string
+	Topics          []*OffsetCommitRequestTopic
 }
 
 // request only ONE topic
@@ -53,6 +74,10 @@ func (r *OffsetCommitRequest) SetRetentionTime(retentionTime int64) {
 	r.RetentionTime = retentionTime
 }
 
+func (r *OffsetCommitRequest) SetGroupInstanceID(groupInstanceID *string) {
+	r.GroupInstanceID = groupInstanceID
+}
+
 func (r *OffsetCommitRequest) AddPartiton(topic string, partitionID int32, offset int64, metadata string) {
 	if r.Topics == nil {
 		r.Topics = make([]*OffsetCommitRequestTopic, 0)
@@ -83,6 +108,7 @@ func (r *OffsetCommitRequest) AddPartiton(topic string, partitionID int32, offse
 	thePartition := &OffsetCommitRequestPartition{
 		PartitionID: partitionID,
 		Offset:      offset,
+		LeaderEpoch: -1,
 		Metadata:    metadata,
 	}
 
@@ -94,8 +120,14 @@ func (r *OffsetCommitRequest) Length() int {
 	l := r.RequestHeader.length()
 	l += 2 + len(r.GroupID)
 
-	if r.Version() == 2 {
-		l += 4 + 2 + len(r.MemberID) + 8
+	if r.Version() >= 2 {
+		l += 4 + 2 + len(r.MemberID)
+	}
+	if r.Version() >= 2 && r.Version() <= 4 {
+		l += 8
+	}
+	if r.Version() >= 7 {
+		l += len(encodeNullableString(r.GroupInstanceID))
 	}
 
 	l += 4
@@ -104,6 +136,9 @@ func (r *OffsetCommitRequest) Length() int {
 		l += 4
 		for _, p := range t.Partitions {
 			l += 4 + 8 + 2 + len(p.Metadata)
+			if r.Version() >= 6 {
+				l += 4
+			}
 		}
 	}
 	return l
@@ -122,18 +157,24 @@ func (r *OffsetCommitRequest) Encode(version uint16) []byte {
 	offset += 2
 	offset += copy(payload[offset:], r.GroupID)
 
-	if r.Version() == 2 {
+	if r.Version() >= 2 {
 		binary.BigEndian.PutUint32(payload[offset:], uint32(r.GenerationID))
 		offset += 4
 
 		binary.BigEndian.PutUint16(payload[offset:], uint16(len(r.MemberID)))
 		offset += 2
 		offset += copy(payload[offset:], r.MemberID)
+	}
 
+	if r.Version() >= 2 && r.Version() <= 4 {
 		binary.BigEndian.PutUint64(payload[offset:], uint64(r.RetentionTime))
 		offset += 8
 	}
 
+	if r.Version() >= 7 {
+		offset += copy(payload[offset:], encodeNullableString(r.GroupInstanceID))
+	}
+
 	binary.BigEndian.PutUint32(payload[offset:], uint32(len(r.Topics)))
 	offset += 4
 
@@ -151,6 +192,10 @@ func (r *OffsetCommitRequest) Encode(version uint16) []byte {
 
 			binary.BigEndian.PutUint64(payload[offset:], uint64(p.Offset))
 			offset += 8
+			if r.Version() >= 6 {
+				binary.BigEndian.PutUint32(payload[offset:], uint32(p.LeaderEpoch))
+				offset += 4
+			}
 			binary.BigEndian.PutUint16(payload[offset:], uint16(len(p.Metadata)))
 			offset += 2
 			offset += copy(payload[offset:], p.Metadata)
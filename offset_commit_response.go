@@ -6,7 +6,8 @@ import (
 )
 
 /*
-OffsetCommitResponse => [TopicName [Partition ErrorCode]]]
+OffsetCommitResponse => ThrottleTimeMS [TopicName [Partition ErrorCode]]]
+  ThrottleTimeMS => int32 (since version 3)
   TopicName => string
   Partition => int32
   ErrorCode => int16
@@ -23,8 +24,9 @@ type OffsetCommitResponseTopic struct {
 }
 
 type OffsetCommitResponse struct {
-	CorrelationID uint32
-	Topics        []*OffsetCommitResponseTopic
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	Topics         []*OffsetCommitResponseTopic
 }
 
 func (r OffsetCommitResponse) Error() error {
@@ -38,7 +40,7 @@ func (r OffsetCommitResponse) Error() error {
 	return nil
 }
 
-func NewOffsetCommitResponse(payload []byte) (r OffsetCommitResponse, err error) {
+func NewOffsetCommitResponse(payload []byte, version uint16) (r OffsetCommitResponse, err error) {
 	var (
 		offset int = 0
 		l      int = 0
@@ -52,6 +54,11 @@ func NewOffsetCommitResponse(payload []byte) (r OffsetCommitResponse, err error)
 	r.CorrelationID = uint32(binary.BigEndian.Uint32(payload[offset:]))
 	offset += 4
 
+	if version >= 3 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
 	l = int(binary.BigEndian.Uint32(payload[offset:]))
 	offset += 4
 	r.Topics = make([]*OffsetCommitResponseTopic, l)
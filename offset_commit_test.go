@@ -37,3 +37,42 @@ func TestOffsetCommitRequest(t *testing.T) {
 		t.Error("offsetcommit request payload length should be 71")
 	}
 }
+
+func TestOffsetCommitRequestVersions(t *testing.T) {
+	instanceID := "pod-0"
+	// retention_time is removed in version 5, leader epoch is added in version 6 and group_instance_id in version 7
+	for version, want := range map[uint16]int{2: 71, 5: 63, 6: 67, 7: 74} {
+		r := NewOffsetCommitRequest(version, "healer", "hangout")
+		r.SetGenerationID(1)
+		r.SetMemberID("")
+		r.SetRetentionTime(-1)
+		r.SetGroupInstanceID(&instanceID)
+		r.AddPartiton("test", 0, 100, "")
+
+		payload := r.Encode(version)
+		if len(payload) != want {
+			t.Errorf("offsetcommit request v%d payload length should be %d, got %d", version, want, len(payload))
+		}
+		if len(payload) != r.Length()+4 {
+			t.Errorf("offsetcommit request v%d payload length did not match Length()", version)
+		}
+	}
+}
+
+func TestOffsetCommitResponseThrottleTime(t *testing.T) {
+	// correlation_id throttle_time_ms [topic [partition error_code]]
+	payload := []byte{
+		0, 0, 0, 28,
+		0, 0, 0, 1,
+		0, 0, 0, 10,
+		0, 0, 0, 1, 0, 4, 't', 'e', 's', 't',
+		0, 0, 0, 1, 0, 0, 0, 0, 0, 82,
+	}
+	r, err := NewOffsetCommitResponse(payload, 7)
+	if err != KafkaError(82) {
+		t.Errorf("expect FENCED_INSTANCE_ID, got %v", err)
+	}
+	if r.ThrottleTimeMS != 10 || r.Topics[0].Topic != "test" || r.Topics[0].Partitions[0].ErrorCode != 82 {
+		t.Errorf("decode offsetcommit response v7 error: %+v", r)
+	}
+}
@@ -0,0 +1,112 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+OffsetDelete Request (Version: 0) => group_id [topics]
+  group_id => STRING
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index
+      partition_index => INT32
+*/
+
+// OffsetDeleteRequest deletes the committed offsets of the partitions in a group.
+// the group must not be subscribed to the topics if it is active
+type OffsetDeleteRequest struct {
+	*RequestHeader
+	GroupID string
+	Topics  []OffsetDeleteTopic
+}
+
+// OffsetDeleteTopic is the partitions of one topic in OffsetDeleteRequest
+type OffsetDeleteTopic struct {
+	Name       string
+	Partitions []int32
+}
+
+// NewOffsetDeleteRequest creates a new OffsetDeleteRequest without partitions
+func NewOffsetDeleteRequest(clientID, groupID string) *OffsetDeleteRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_OffsetDelete,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &OffsetDeleteRequest{
+		RequestHeader: requestHeader,
+		GroupID:       groupID,
+		Topics:        make([]OffsetDeleteTopic, 0),
+	}
+}
+
+// AddPartitions adds the partitions of the topic to the request
+func (r *OffsetDeleteRequest) AddPartitions(topic string, partitions ...int32) {
+	for i := range r.Topics {
+		if r.Topics[i].Name == topic {
+			r.Topics[i].Partitions = append(r.Topics[i].Partitions, partitions...)
+			return
+		}
+	}
+	r.Topics = append(r.Topics, OffsetDeleteTopic{Name: topic, Partitions: partitions})
+}
+
+// Encode encodes OffsetDeleteRequest to []byte
+func (r *OffsetDeleteRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	writeString(buf, r.GroupID)
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeOffsetDeleteRequest decodes []byte to OffsetDeleteRequest, just for test
+func DecodeOffsetDeleteRequest(payload []byte, version uint16) (r OffsetDeleteRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	r.GroupID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]OffsetDeleteTopic, topicCount)
+	for i := range r.Topics {
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]int32, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			r.Topics[i].Partitions[j] = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,122 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+OffsetDelete Response (Version: 0) => error_code throttle_time_ms [topics]
+  error_code => INT16
+  throttle_time_ms => INT32
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index error_code
+      partition_index => INT32
+      error_code => INT16
+*/
+
+// OffsetDeleteResponse is the response of OffsetDeleteRequest
+type OffsetDeleteResponse struct {
+	CorrelationID  uint32
+	ErrorCode      int16
+	ThrottleTimeMS int32
+	Topics         []OffsetDeleteTopicResult
+}
+
+// OffsetDeleteTopicResult is the result of one topic in OffsetDeleteResponse
+type OffsetDeleteTopicResult struct {
+	Name       string
+	Partitions []OffsetDeletePartitionResult
+}
+
+// OffsetDeletePartitionResult is the result of one partition.
+// ErrorCode is GROUP_SUBSCRIBED_TO_TOPIC if the group is active and still consuming the topic
+type OffsetDeletePartitionResult struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
+
+// Error returns the error of the group, or the first error of the partitions
+func (r OffsetDeleteResponse) Error() error {
+	if r.ErrorCode != 0 {
+		return fmt.Errorf("delete offsets error: %w", KafkaError(r.ErrorCode))
+	}
+	for _, topic := range r.Topics {
+		for _, partition := range topic.Partitions {
+			if partition.ErrorCode != 0 {
+				return fmt.Errorf("delete offsets of %s-%d error: %w", topic.Name, partition.PartitionIndex, KafkaError(partition.ErrorCode))
+			}
+		}
+	}
+	return nil
+}
+
+// NewOffsetDeleteResponse creates a new OffsetDeleteResponse from []byte
+func NewOffsetDeleteResponse(payload []byte, version uint16) (r OffsetDeleteResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("OffsetDelete response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]OffsetDeleteTopicResult, topicCount)
+	for i := range r.Topics {
+		var o int
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]OffsetDeletePartitionResult, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			p := &r.Topics[i].Partitions[j]
+			p.PartitionIndex = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			p.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+		}
+	}
+
+	return r, nil
+}
+
+// Encode encodes OffsetDeleteResponse to []byte, just for test
+func (r OffsetDeleteResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ErrorCode)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.PartitionIndex)
+			binary.Write(buf, binary.BigEndian, partition.ErrorCode)
+		}
+	}
+
+	return buf.Bytes()
+}
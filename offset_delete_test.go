@@ -0,0 +1,43 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestOffsetDeleteEncodeDecode(t *testing.T) {
+	convey.Convey("Test OffsetDelete Request and Response Encode and Decode", t, func() {
+		version := uint16(0)
+
+		request := NewOffsetDeleteRequest("healer", "test-group")
+		request.AddPartitions("topic-1", 0, 1)
+		request.AddPartitions("topic-1", 2)
+		request.AddPartitions("topic-2", 0)
+		convey.So(len(request.Topics), convey.ShouldEqual, 2)
+
+		decodedRequest, err := DecodeOffsetDeleteRequest(request.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+		response := OffsetDeleteResponse{
+			CorrelationID:  1,
+			ThrottleTimeMS: 10,
+			Topics: []OffsetDeleteTopicResult{
+				{
+					Name: "topic-1",
+					Partitions: []OffsetDeletePartitionResult{
+						{PartitionIndex: 0, ErrorCode: 0},
+						{PartitionIndex: 1, ErrorCode: 86},
+					},
+				},
+			},
+		}
+		decodedResponse, err := NewOffsetDeleteResponse(response.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(decodedResponse, convey.ShouldResemble, response)
+		convey.So(errors.Is(decodedResponse.Error(), KafkaError(86)), convey.ShouldBeTrue)
+		convey.So(decodedResponse.Error().Error(), convey.ShouldContainSubstring, "GROUP_SUBSCRIBED_TO_TOPIC")
+	})
+}
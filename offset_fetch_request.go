@@ -15,6 +15,8 @@ topics	Topics to fetch offsets.
 topic	Name of topic
 partitions	Partitions to fetch offsets.
 partition	Topic partition id
+
+v2 is the same as v1, and topics could be null to fetch the committed offsets of all topics.
 */
 
 import (
@@ -78,6 +80,11 @@ func (r *OffsetFetchRequest) AddPartiton(topic string, partitionID int32) {
 	return
 }
 
+// FetchAllTopics makes the request fetch the committed offsets of all topics of the group, it is supported since v2
+func (r *OffsetFetchRequest) FetchAllTopics() {
+	r.Topics = nil
+}
+
 func (r *OffsetFetchRequest) Length() int {
 	l := r.RequestHeader.length()
 	l += 2 + len(r.GroupID)
@@ -104,7 +111,11 @@ func (r *OffsetFetchRequest) Encode(version uint16) []byte {
 	offset += 2
 	offset += copy(payload[offset:], r.GroupID)
 
-	binary.BigEndian.PutUint32(payload[offset:], uint32(len(r.Topics)))
+	if r.Topics == nil {
+		binary.BigEndian.PutUint32(payload[offset:], 0xffffffff)
+	} else {
+		binary.BigEndian.PutUint32(payload[offset:], uint32(len(r.Topics)))
+	}
 	offset += 4
 
 	for _, t := range r.Topics {
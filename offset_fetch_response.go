@@ -20,9 +20,13 @@ type OffsetFetchResponseTopic struct {
 type OffsetFetchResponse struct {
 	CorrelationID uint32
 	Topics        []*OffsetFetchResponseTopic
+	ErrorCode     int16 // v2+, error of the whole request, such as the coordinator is loading
 }
 
 func (r OffsetFetchResponse) Error() error {
+	if r.ErrorCode != 0 {
+		return KafkaError(r.ErrorCode)
+	}
 	for _, topic := range r.Topics {
 		for _, partition := range topic.Partitions {
 			if partition.ErrorCode != 0 {
@@ -34,7 +38,7 @@ func (r OffsetFetchResponse) Error() error {
 }
 
 // NewOffsetFetchResponse decodes the response byte array to a OffsetFetchResponse struct
-func NewOffsetFetchResponse(payload []byte) (r OffsetFetchResponse, err error) {
+func NewOffsetFetchResponse(payload []byte, version uint16) (r OffsetFetchResponse, err error) {
 	var (
 		offset int = 0
 		l      int = 0
@@ -85,5 +89,13 @@ func NewOffsetFetchResponse(payload []byte) (r OffsetFetchResponse, err error) {
 		}
 	}
 
+	if version >= 2 {
+		r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+		offset += 2
+		if r.ErrorCode != 0 {
+			err = KafkaError(r.ErrorCode)
+		}
+	}
+
 	return r, err
 }
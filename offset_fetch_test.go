@@ -1,6 +1,8 @@
 package healer
 
 import (
+	"encoding/binary"
+	"errors"
 	"testing"
 )
 
@@ -39,3 +41,43 @@ func TestOffsetFetchRequest(t *testing.T) {
 		t.Error("offsetcommit request payload length should be 51")
 	}
 }
+
+func TestOffsetFetchAllTopics(t *testing.T) {
+	r := NewOffsetFetchRequest(2, "healer", "hangout")
+	r.FetchAllTopics()
+
+	payload := r.Encode(2)
+	if len(payload) != 4+r.Length() {
+		t.Errorf("offsetfetch request payload length should be %d", 4+r.Length())
+	}
+	if topics := int32(binary.BigEndian.Uint32(payload[len(payload)-4:])); topics != -1 {
+		t.Errorf("topics should be null, got %d", topics)
+	}
+}
+
+func TestOffsetFetchResponseV2(t *testing.T) {
+	payload := []byte{
+		0, 0, 0, 0, // length
+		0, 0, 0, 1, // correlation id
+		0, 0, 0, 1, // topics
+		0, 4, 't', 'e', 's', 't',
+		0, 0, 0, 1, // partitions
+		0, 0, 0, 0, // partition
+		0, 0, 0, 0, 0, 0, 0, 100, // offset
+		0, 0, // metadata
+		0, 0, // error code
+		0, 14, // error code of the response
+	}
+	binary.BigEndian.PutUint32(payload, uint32(len(payload)-4))
+
+	r, err := NewOffsetFetchResponse(payload, 2)
+	if !errors.Is(err, KafkaError(14)) {
+		t.Errorf("expect error 14, got %v", err)
+	}
+	if r.Topics[0].Topic != "test" || r.Topics[0].Partitions[0].Offset != 100 {
+		t.Errorf("unexpected response %+v", r.Topics[0])
+	}
+	if !errors.Is(r.Error(), KafkaError(14)) {
+		t.Errorf("expect error 14, got %v", r.Error())
+	}
+}
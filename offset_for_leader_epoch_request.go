@@ -0,0 +1,141 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+OffsetForLeaderEpoch Request (Version: 3) => replica_id [topics]
+  replica_id => INT32
+  topics => topic [partitions]
+    topic => STRING
+    partitions => partition current_leader_epoch leader_epoch
+      partition => INT32
+      current_leader_epoch => INT32
+      leader_epoch => INT32
+
+replica_id is added in version 3, current_leader_epoch is added in version 2
+*/
+
+// OffsetForLeaderEpochRequest requests the end offset of the leader epoch, it is used to detect log truncation
+type OffsetForLeaderEpochRequest struct {
+	*RequestHeader
+	ReplicaID int32
+	Topics    []OffsetForLeaderEpochTopic
+}
+
+// OffsetForLeaderEpochTopic is the partitions of one topic in OffsetForLeaderEpochRequest
+type OffsetForLeaderEpochTopic struct {
+	Topic      string
+	Partitions []OffsetForLeaderEpochPartition
+}
+
+// OffsetForLeaderEpochPartition is one partition in OffsetForLeaderEpochRequest.
+// CurrentLeaderEpoch is the epoch of the leader known by the client, LeaderEpoch is the epoch to look up the end offset
+type OffsetForLeaderEpochPartition struct {
+	Partition          int32
+	CurrentLeaderEpoch int32
+	LeaderEpoch        int32
+}
+
+// NewOffsetForLeaderEpochRequest creates a new OffsetForLeaderEpochRequest without partitions
+func NewOffsetForLeaderEpochRequest(clientID string) *OffsetForLeaderEpochRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_OffsetForLeaderEpoch,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &OffsetForLeaderEpochRequest{
+		RequestHeader: requestHeader,
+		ReplicaID:     -1,
+		Topics:        make([]OffsetForLeaderEpochTopic, 0),
+	}
+}
+
+// AddPartition adds a partition to the request
+func (r *OffsetForLeaderEpochRequest) AddPartition(topic string, partition int32, currentLeaderEpoch, leaderEpoch int32) {
+	p := OffsetForLeaderEpochPartition{Partition: partition, CurrentLeaderEpoch: currentLeaderEpoch, LeaderEpoch: leaderEpoch}
+	for i := range r.Topics {
+		if r.Topics[i].Topic == topic {
+			r.Topics[i].Partitions = append(r.Topics[i].Partitions, p)
+			return
+		}
+	}
+	r.Topics = append(r.Topics, OffsetForLeaderEpochTopic{Topic: topic, Partitions: []OffsetForLeaderEpochPartition{p}})
+}
+
+// Encode encodes OffsetForLeaderEpochRequest to []byte
+func (r *OffsetForLeaderEpochRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	if version >= 3 {
+		binary.Write(buf, binary.BigEndian, r.ReplicaID)
+	}
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Topic)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.Partition)
+			if version >= 2 {
+				binary.Write(buf, binary.BigEndian, partition.CurrentLeaderEpoch)
+			}
+			binary.Write(buf, binary.BigEndian, partition.LeaderEpoch)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeOffsetForLeaderEpochRequest decodes []byte to OffsetForLeaderEpochRequest, just for test
+func DecodeOffsetForLeaderEpochRequest(payload []byte, version uint16) (r OffsetForLeaderEpochRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+
+	r.ReplicaID = -1
+	if version >= 3 {
+		r.ReplicaID = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]OffsetForLeaderEpochTopic, topicCount)
+	for i := range r.Topics {
+		r.Topics[i].Topic, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]OffsetForLeaderEpochPartition, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			p := &r.Topics[i].Partitions[j]
+			p.Partition = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			if version >= 2 {
+				p.CurrentLeaderEpoch = int32(binary.BigEndian.Uint32(payload[offset:]))
+				offset += 4
+			}
+			p.LeaderEpoch = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+		}
+	}
+
+	return r, nil
+}
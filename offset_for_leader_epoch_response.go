@@ -0,0 +1,134 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+OffsetForLeaderEpoch Response (Version: 3) => throttle_time_ms [topics]
+  throttle_time_ms => INT32
+  topics => topic [partitions]
+    topic => STRING
+    partitions => error_code partition leader_epoch end_offset
+      error_code => INT16
+      partition => INT32
+      leader_epoch => INT32
+      end_offset => INT64
+
+throttle_time_ms is added in version 2, leader_epoch is added in version 1
+*/
+
+// OffsetForLeaderEpochResponse is the response of OffsetForLeaderEpochRequest
+type OffsetForLeaderEpochResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	Topics         []OffsetForLeaderEpochTopicResult
+}
+
+// OffsetForLeaderEpochTopicResult is the result of one topic in OffsetForLeaderEpochResponse
+type OffsetForLeaderEpochTopicResult struct {
+	Topic      string
+	Partitions []OffsetForLeaderEpochPartitionResult
+}
+
+// OffsetForLeaderEpochPartitionResult is the result of one partition.
+// LeaderEpoch is the largest epoch not larger than the requested one, EndOffset is the end offset of it
+type OffsetForLeaderEpochPartitionResult struct {
+	ErrorCode   int16
+	Partition   int32
+	LeaderEpoch int32
+	EndOffset   int64
+}
+
+// Error returns the first error in the partitions
+func (r OffsetForLeaderEpochResponse) Error() error {
+	for _, topic := range r.Topics {
+		for _, partition := range topic.Partitions {
+			if partition.ErrorCode != 0 {
+				return fmt.Errorf("offset for leader epoch of %s-%d error: %w", topic.Topic, partition.Partition, KafkaError(partition.ErrorCode))
+			}
+		}
+	}
+	return nil
+}
+
+// NewOffsetForLeaderEpochResponse creates a new OffsetForLeaderEpochResponse from []byte
+func NewOffsetForLeaderEpochResponse(payload []byte, version uint16) (r OffsetForLeaderEpochResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("OffsetForLeaderEpoch response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	if version >= 2 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]OffsetForLeaderEpochTopicResult, topicCount)
+	for i := range r.Topics {
+		var o int
+		r.Topics[i].Topic, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]OffsetForLeaderEpochPartitionResult, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			p := &r.Topics[i].Partitions[j]
+			p.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+			p.Partition = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			p.LeaderEpoch = -1
+			if version >= 1 {
+				p.LeaderEpoch = int32(binary.BigEndian.Uint32(payload[offset:]))
+				offset += 4
+			}
+			p.EndOffset = int64(binary.BigEndian.Uint64(payload[offset:]))
+			offset += 8
+		}
+	}
+
+	return r, nil
+}
+
+// Encode encodes OffsetForLeaderEpochResponse to []byte, just for test
+func (r OffsetForLeaderEpochResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	if version >= 2 {
+		binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+	}
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Topic)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.ErrorCode)
+			binary.Write(buf, binary.BigEndian, partition.Partition)
+			if version >= 1 {
+				binary.Write(buf, binary.BigEndian, partition.LeaderEpoch)
+			}
+			binary.Write(buf, binary.BigEndian, partition.EndOffset)
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,57 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestOffsetForLeaderEpochEncodeDecode(t *testing.T) {
+	convey.Convey("Test OffsetForLeaderEpoch Request and Response Encode and Decode", t, func() {
+		for _, version := range availableVersions[API_OffsetForLeaderEpoch] {
+			t.Logf("version: %v", version)
+
+			request := NewOffsetForLeaderEpochRequest("healer")
+			request.APIVersion = version
+			request.AddPartition("topic-1", 0, 5, 3)
+			request.AddPartition("topic-1", 1, 5, 4)
+			request.AddPartition("topic-2", 0, 2, 2)
+			convey.So(len(request.Topics), convey.ShouldEqual, 2)
+			if version < 2 {
+				for i := range request.Topics {
+					for j := range request.Topics[i].Partitions {
+						request.Topics[i].Partitions[j].CurrentLeaderEpoch = 0
+					}
+				}
+			}
+
+			decodedRequest, err := DecodeOffsetForLeaderEpochRequest(request.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+			response := OffsetForLeaderEpochResponse{
+				CorrelationID: 1,
+				Topics: []OffsetForLeaderEpochTopicResult{
+					{
+						Topic: "topic-1",
+						Partitions: []OffsetForLeaderEpochPartitionResult{
+							{ErrorCode: 0, Partition: 0, LeaderEpoch: 3, EndOffset: 100},
+							{ErrorCode: 74, Partition: 1, LeaderEpoch: -1, EndOffset: -1},
+						},
+					},
+				},
+			}
+			if version >= 2 {
+				response.ThrottleTimeMS = 10
+			}
+			if version < 1 {
+				response.Topics[0].Partitions[0].LeaderEpoch = -1
+			}
+			decodedResponse, err := NewOffsetForLeaderEpochResponse(response.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(decodedResponse, convey.ShouldResemble, response)
+			convey.So(errors.Is(decodedResponse.Error(), KafkaError(74)), convey.ShouldBeTrue)
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package healer
 
-import "testing"
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
 
 func TestGenOffsetsRequest(t *testing.T) {
 	var (
@@ -19,3 +23,28 @@ func TestGenOffsetsRequest(t *testing.T) {
 		t.Error("offsets request payload length should be 54")
 	}
 }
+
+func TestOffsetsResponseV1(t *testing.T) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint32(0)) // length, filled later
+	binary.Write(buf, binary.BigEndian, uint32(1)) // correlation id
+	binary.Write(buf, binary.BigEndian, uint32(1)) // topics
+	binary.Write(buf, binary.BigEndian, uint16(4))
+	buf.WriteString("test")
+	binary.Write(buf, binary.BigEndian, uint32(1)) // partitions
+	binary.Write(buf, binary.BigEndian, int32(0))
+	binary.Write(buf, binary.BigEndian, int16(0))
+	binary.Write(buf, binary.BigEndian, int64(1791936000000))
+	binary.Write(buf, binary.BigEndian, int64(50))
+	payload := buf.Bytes()
+	binary.BigEndian.PutUint32(payload, uint32(len(payload)-4))
+
+	r, err := NewOffsetsResponse(payload, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := r.TopicPartitionOffsets["test"][0]
+	if p.Timestamp != 1791936000000 || p.GetOffset() != 50 {
+		t.Errorf("unexpected partition offset: %+v", p)
+	}
+}
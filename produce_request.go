@@ -4,26 +4,57 @@ import (
 	"encoding/binary"
 )
 
+/*
+Produce Request (Version: 0) => acks timeout_ms [topic_data]
+  acks => INT16
+  timeout_ms => INT32
+  topic_data => name [partition_data]
+    name => STRING
+    partition_data => index records
+      index => INT32
+      records => RECORDS
+
+Produce Request (Version: 3) => transactional_id acks timeout_ms [topic_data]
+  transactional_id => NULLABLE_STRING
+  ...
+
+records in version 0-2 are MessageSet (magic 0 or 1), and RecordBatch (magic 2) since version 3
+*/
+
 type ProduceRequest struct {
 	*RequestHeader
-	RequiredAcks int16
-	Timeout      int32
-	TopicBlocks  []struct {
+	TransactionalID *string // version 3+
+	RequiredAcks    int16
+	Timeout         int32
+	TopicBlocks     []struct {
 		TopicName      string
 		PartitonBlocks []struct {
 			Partition      int32
 			MessageSetSize int32
 			MessageSet     MessageSet
+
+			// Records is the encoded RecordBatch, it is used instead of MessageSet since version 3
+			Records []byte
 		}
 	}
 }
 
-func (produceRequest *ProduceRequest) Length() int {
+func (produceRequest *ProduceRequest) Length(version uint16) int {
 	requestLength := produceRequest.RequestHeader.length() + 10 //	RequiredAcks(2) + Timeout(4) + TopicBlocks_length(4)
+	if version >= 3 {
+		requestLength += 2
+		if produceRequest.TransactionalID != nil {
+			requestLength += len(*produceRequest.TransactionalID)
+		}
+	}
 	for _, topicBlock := range produceRequest.TopicBlocks {
 		requestLength += 6 + len(topicBlock.TopicName)
 		for _, parttionBlock := range topicBlock.PartitonBlocks {
-			requestLength += 8 + parttionBlock.MessageSet.Length()
+			if version >= 3 {
+				requestLength += 8 + len(parttionBlock.Records)
+			} else {
+				requestLength += 8 + parttionBlock.MessageSet.Length()
+			}
 		}
 	}
 
@@ -31,7 +62,7 @@ func (produceRequest *ProduceRequest) Length() int {
 }
 
 func (produceRequest *ProduceRequest) Encode(version uint16) []byte {
-	requestLength := produceRequest.Length()
+	requestLength := produceRequest.Length(version)
 	payload := make([]byte, requestLength+4)
 	offset := 0
 
@@ -40,6 +71,10 @@ func (produceRequest *ProduceRequest) Encode(version uint16) []byte {
 
 	offset += produceRequest.RequestHeader.EncodeTo(payload[offset:])
 
+	if version >= 3 {
+		offset += copy(payload[offset:], encodeNullableString(produceRequest.TransactionalID))
+	}
+
 	binary.BigEndian.PutUint16(payload[offset:], uint16(produceRequest.RequiredAcks))
 	offset += 2
 	binary.BigEndian.PutUint32(payload[offset:], uint32(produceRequest.Timeout))
@@ -57,6 +92,12 @@ func (produceRequest *ProduceRequest) Encode(version uint16) []byte {
 		for _, parttionBlock := range topicBlock.PartitonBlocks {
 			binary.BigEndian.PutUint32(payload[offset:], uint32(parttionBlock.Partition))
 			offset += 4
+			if version >= 3 {
+				binary.BigEndian.PutUint32(payload[offset:], uint32(len(parttionBlock.Records)))
+				offset += 4
+				offset += copy(payload[offset:], parttionBlock.Records)
+				continue
+			}
 			binary.BigEndian.PutUint32(payload[offset:], uint32(parttionBlock.MessageSet.Length()))
 			offset += 4
 
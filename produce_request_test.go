@@ -0,0 +1,174 @@
+package healer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestProduceRecordBatchWithHeaders(t *testing.T) {
+	convey.Convey("RecordBatch with headers could be decoded by fetch response decoder", t, func() {
+		for _, c := range []struct {
+			compressionType  string
+			compressionValue int8
+			compressionLevel int
+		}{
+			{"none", COMPRESSION_NONE, 0},
+			{"gzip", COMPRESSION_GZIP, 0},
+			{"gzip", COMPRESSION_GZIP, 9},
+			{"snappy", COMPRESSION_SNAPPY, 0},
+			{"lz4", COMPRESSION_LZ4, 0},
+			{"lz4", COMPRESSION_LZ4, 9},
+			{"zstd", COMPRESSION_ZSTD, 0},
+			{"zstd", COMPRESSION_ZSTD, 19},
+		} {
+			config := DefaultProducerConfig()
+			config.CompressionType = c.compressionType
+			config.CompressionLevel = c.compressionLevel
+			p := &SimpleProducer{
+				config:           &config,
+				compressionValue: c.compressionValue,
+				compressor:       NewCompressorWithLevel(c.compressionType, c.compressionLevel),
+			}
+
+			messageSet := MessageSet{
+				{Timestamp: 1630000000000, Key: []byte("key-1"), Value: []byte("value-1")},
+				{Timestamp: 1630000001000, Key: nil, Value: []byte("value-2"), Headers: []RecordHeader{
+					{Key: "h1", Value: []byte("v1")},
+					{Key: "h2", Value: nil},
+				}},
+			}
+			batch := p.buildRecordBatch(messageSet)
+			convey.So(batch.LastOffsetDelta, convey.ShouldEqual, 1)
+			convey.So(batch.MaxTimestamp, convey.ShouldEqual, 1630000001000)
+
+			fetchResponse := FetchResponse{
+				Responses: map[string][]PartitionResponse{
+					"test-topic": {
+						{PartitionID: 0, RecordBatch: *batch},
+					},
+				},
+			}
+			payload, err := fetchResponse.Encode(10)
+			convey.So(err, convey.ShouldBeNil)
+
+			messages := make(chan *FullMessage, 10)
+			decoder := fetchResponseStreamDecoder{
+				ctx:         context.Background(),
+				buffers:     bytes.NewReader(payload),
+				messages:    messages,
+				totalLength: len(payload) + 4,
+				version:     10,
+			}
+			err = decoder.streamDecode(context.Background(), 0)
+			convey.So(err, convey.ShouldBeNil)
+			close(messages)
+
+			decoded := make([]*Message, 0)
+			for msg := range messages {
+				convey.So(msg.Error, convey.ShouldBeNil)
+				decoded = append(decoded, msg.Message)
+			}
+			convey.So(len(decoded), convey.ShouldEqual, 2)
+			for i, msg := range decoded {
+				convey.So(msg.Offset, convey.ShouldEqual, i)
+				convey.So(msg.Timestamp, convey.ShouldEqual, messageSet[i].Timestamp)
+				convey.So(msg.Key, convey.ShouldResemble, messageSet[i].Key)
+				convey.So(msg.Value, convey.ShouldResemble, messageSet[i].Value)
+				convey.So(len(msg.Headers), convey.ShouldEqual, len(messageSet[i].Headers))
+				for j, header := range msg.Headers {
+					convey.So(header.Key, convey.ShouldEqual, messageSet[i].Headers[j].Key)
+					convey.So(header.Value, convey.ShouldResemble, messageSet[i].Headers[j].Value)
+				}
+			}
+		}
+	})
+}
+
+func TestProduceRequestEncodeLength(t *testing.T) {
+	convey.Convey("encoded produce request length should match Length()", t, func() {
+		transactionalID := "txn"
+		for _, version := range []uint16{0, 3} {
+			r := &ProduceRequest{
+				RequestHeader: &RequestHeader{
+					APIKey:     API_ProduceRequest,
+					APIVersion: version,
+				},
+				TransactionalID: &transactionalID,
+				RequiredAcks:    1,
+				Timeout:         1000,
+			}
+			r.TopicBlocks = make([]struct {
+				TopicName      string
+				PartitonBlocks []struct {
+					Partition      int32
+					MessageSetSize int32
+					MessageSet     MessageSet
+					Records        []byte
+				}
+			}, 1)
+			r.TopicBlocks[0].TopicName = "test-topic"
+			r.TopicBlocks[0].PartitonBlocks = make([]struct {
+				Partition      int32
+				MessageSetSize int32
+				MessageSet     MessageSet
+				Records        []byte
+			}, 1)
+			r.TopicBlocks[0].PartitonBlocks[0].MessageSet = MessageSet{{Key: []byte("k"), Value: []byte("v")}}
+			r.TopicBlocks[0].PartitonBlocks[0].Records = []byte("records")
+
+			payload := r.Encode(version)
+			convey.So(len(payload), convey.ShouldEqual, r.Length(version)+4)
+		}
+	})
+}
+
+func TestTransactionalProduceRequest(t *testing.T) {
+	mockey.PatchConvey("transactional id and transactional attribute are set in produce request", t, func() {
+		config := DefaultProducerConfig()
+		config.EnableIdempotence = true
+		config.TransactionalID = "txn-1"
+		p := &SimpleProducer{
+			config:           &config,
+			leader:           &Broker{},
+			topic:            "test-topic",
+			partition:        0,
+			compressionValue: COMPRESSION_NONE,
+			compressor:       NewCompressorWithLevel("none", 0),
+			state:            newProducerState(&Brokers{}, &config),
+		}
+		p.state.producerID = 100
+		p.state.producerEpoch = 1
+
+		var produceRequest *ProduceRequest
+		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(uint16(3)).Build()
+		mockey.Mock((*producerState).addPartitionToTxn).Return(nil).Build()
+		mockey.Mock((*Broker).RequestAndGet).To(func(broker *Broker, r Request) (Response, error) {
+			produceRequest = r.(*ProduceRequest)
+			return ProduceResponse{}, nil
+		}).Build()
+
+		err := p.flush(MessageSet{{Timestamp: 1630000000000, Value: []byte("value-1")}})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(produceRequest, convey.ShouldNotBeNil)
+
+		payload := produceRequest.Encode(3)
+		offset := 4 + produceRequest.RequestHeader.length()
+		transactionalIDLength := int(binary.BigEndian.Uint16(payload[offset:]))
+		offset += 2
+		convey.So(string(payload[offset:offset+transactionalIDLength]), convey.ShouldEqual, "txn-1")
+		offset += transactionalIDLength
+
+		// acks(2) + timeout(4) + topics(4) + topic name + partitions(4) + partition(4) + records size(4)
+		offset += 10 + 2 + len("test-topic") + 12
+		records := payload[offset:]
+		// base offset(8) + batch length(4) + partition leader epoch(4) + magic(1) + crc(4)
+		attributes := int16(binary.BigEndian.Uint16(records[21:]))
+		convey.So(attributes&batchAttributeTransactional, convey.ShouldNotEqual, 0)
+		convey.So(int64(binary.BigEndian.Uint64(records[43:])), convey.ShouldEqual, 100)
+	})
+}
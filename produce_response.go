@@ -6,9 +6,11 @@ import (
 )
 
 type ProduceResponse_PartitionResponse struct {
-	PartitionID int32
-	ErrorCode   int16
-	BaseOffset  int64
+	PartitionID    int32
+	ErrorCode      int16
+	BaseOffset     int64
+	LogAppendTime  int64 // version 2+
+	LogStartOffset int64 // version 5+
 }
 
 type ProduceResponsePiece struct {
@@ -19,6 +21,7 @@ type ProduceResponsePiece struct {
 type ProduceResponse struct {
 	CorrelationID    uint32
 	ProduceResponses []ProduceResponsePiece
+	ThrottleTimeMS   int32 // version 1+
 }
 
 func (r ProduceResponse) Error() error {
@@ -32,7 +35,7 @@ func (r ProduceResponse) Error() error {
 	return nil
 }
 
-func NewProduceResponse(payload []byte) (r ProduceResponse, err error) {
+func NewProduceResponse(payload []byte, version uint16) (r ProduceResponse, err error) {
 	var (
 		offset int = 0
 		l      int = 0
@@ -72,8 +75,20 @@ func NewProduceResponse(payload []byte) (r ProduceResponse, err error) {
 			}
 			p.BaseOffset = int64(binary.BigEndian.Uint64(payload[offset:]))
 			offset += 8
+			if version >= 2 {
+				p.LogAppendTime = int64(binary.BigEndian.Uint64(payload[offset:]))
+				offset += 8
+			}
+			if version >= 5 {
+				p.LogStartOffset = int64(binary.BigEndian.Uint64(payload[offset:]))
+				offset += 8
+			}
 		}
 	}
 
+	if version >= 1 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	}
+
 	return r, err
 }
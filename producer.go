@@ -14,6 +14,7 @@ type ctxKey string
 
 var leaderKey ctxKey = "leader"
 var parentProducerKey ctxKey = "parentProducer"
+var producerStateKey ctxKey = "producerState"
 
 type Producer struct {
 	config ProducerConfig
@@ -27,6 +28,9 @@ type Producer struct {
 	currentProducer      *SimpleProducer
 	lock                 sync.Mutex
 
+	// state is shared by all simple producers, it is not nil if idempotence is enabled
+	state *producerState
+
 	ctx context.Context
 }
 
@@ -54,6 +58,10 @@ func NewProducer(topic string, config interface{}) (*Producer, error) {
 		return nil, err
 	}
 
+	if cfg.EnableIdempotence {
+		p.state = newProducerState(p.brokers, &cfg)
+	}
+
 	for {
 		if err = p.updateCurrentSimpleProducer(); err != nil {
 			logger.Error(err, "update current simple consumer, sleep and retry", "topic", p.topic, "retry_backoff_ms", p.config.RetryBackOffMS)
@@ -63,8 +71,7 @@ func NewProducer(topic string, config interface{}) (*Producer, error) {
 		}
 	}
 
-	ctx := context.Background()
-	p.ctx = context.WithValue(ctx, parentProducerKey, p)
+	p.ctx = context.WithValue(p.stateContext(), parentProducerKey, p)
 
 	go func() {
 		for range time.NewTicker(time.Duration(cfg.MetadataMaxAgeMS) * time.Millisecond).C {
@@ -78,6 +85,15 @@ func NewProducer(topic string, config interface{}) (*Producer, error) {
 	return p, nil
 }
 
+// stateContext returns a context carrying the producer state which is shared by simple producers
+func (p *Producer) stateContext() context.Context {
+	ctx := context.Background()
+	if p.state != nil {
+		ctx = context.WithValue(ctx, producerStateKey, p.state)
+	}
+	return ctx
+}
+
 // update metadata and currentProducer
 func (p *Producer) updateCurrentSimpleProducer() error {
 	metadataResponse, err := p.brokers.RequestMetaData(p.config.ClientID, []string{p.topic})
@@ -96,7 +112,7 @@ func (p *Producer) updateCurrentSimpleProducer() error {
 		}
 	}
 	partitionID := validPartitionID[rand.Int31n(int32(len(validPartitionID)))]
-	sp, err := NewSimpleProducer(context.Background(), p.topic, partitionID, p.config)
+	sp, err := NewSimpleProducer(p.stateContext(), p.topic, partitionID, p.config)
 	if err != nil {
 		return fmt.Errorf("change current simple producer to %s-%d error: %w", p.topic, partitionID, err)
 	}
@@ -129,31 +145,32 @@ func (p *Producer) getLeaderID(pid int32) (int32, error) {
 	return -1, fmt.Errorf("partition %s-%d not found in metadata", p.topic, pid)
 }
 
-// getSimpleProducer return the simple producer. if key is nil, return the current simple producer
+// getSimpleProducer return the simple producer. if key is nil, return the current simple producer.
+// p.lock is not held while connecting to the leader, so that AddMessage of other partitions is not blocked
 func (p *Producer) getSimpleProducer(key []byte) (*SimpleProducer, error) {
+	p.lock.Lock()
 	if key == nil {
-		p.lock.Lock()
 		defer p.lock.Unlock()
 		return p.currentProducer, nil
 	}
 
 	partitionID := int32(murmur.MurmurHash2(key, 0) % uint32(len(p.topicMeta.PartitionMetadatas)))
-
 	if sp, ok := p.pidToSimpleProducers[partitionID]; ok {
+		p.lock.Unlock()
 		return sp, nil
 	}
-
 	leaderID, err := p.getLeaderID(partitionID)
 	if err != nil {
+		p.lock.Unlock()
 		return nil, err
 	}
 	broker, ok := p.leaderBrokersMapping[leaderID]
+	p.lock.Unlock()
+
 	if !ok {
-		broker, err = p.brokers.NewBroker(leaderID)
-		if err != nil {
-			return nil, fmt.Errorf("create broker %d error: %s", leaderID, err)
+		if broker, err = p.getLeaderBroker(leaderID); err != nil {
+			return nil, err
 		}
-		p.leaderBrokersMapping[leaderID] = broker
 	}
 
 	ctx := context.WithValue(p.ctx, leaderKey, broker)
@@ -161,20 +178,47 @@ func (p *Producer) getSimpleProducer(key []byte) (*SimpleProducer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not create simple producer from the %s-%d", p.topic, partitionID)
 	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if existing, ok := p.pidToSimpleProducers[partitionID]; ok {
+		// created by another AddMessage meanwhile
+		sp.Close()
+		return existing, nil
+	}
 	p.pidToSimpleProducers[partitionID] = sp
 	return sp, nil
 }
 
+// getLeaderBroker creates the broker of leaderID without p.lock and puts it into leaderBrokersMapping,
+// the broker in the mapping is returned if another one has been put meanwhile
+func (p *Producer) getLeaderBroker(leaderID int32) (*Broker, error) {
+	broker, err := p.brokers.NewBroker(leaderID)
+	if err != nil {
+		return nil, fmt.Errorf("create broker %d error: %s", leaderID, err)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if existing, ok := p.leaderBrokersMapping[leaderID]; ok {
+		broker.Close()
+		return existing, nil
+	}
+	p.leaderBrokersMapping[leaderID] = broker
+	return broker, nil
+}
+
 // AddMessage add message to the producer, if key is nil, use current simple producer, else use the simple producer of the partition of the key
 // if the simple producer of the partition of the key not exist, create a new one
 // if the simple producer closed, retry 3 times
-func (p *Producer) AddMessage(key []byte, value []byte) error {
+// headers are only sent if the broker supports RecordBatch (Produce v3+)
+func (p *Producer) AddMessage(key []byte, value []byte, headers ...RecordHeader) error {
 	for i := 0; i < 3; i++ {
 		simpleProducer, err := p.getSimpleProducer(key)
 		if err != nil {
 			return err
 		}
-		err = simpleProducer.AddMessage(key, value)
+		err = simpleProducer.AddMessage(key, value, headers...)
 		if err == ErrProducerClosed { // maybe current simple-producer closed in ticker, retry
 			logger.V(1).Info("simple producer closed, retry", "producer", simpleProducer)
 			continue
@@ -184,6 +228,69 @@ func (p *Producer) AddMessage(key []byte, value []byte) error {
 	return nil
 }
 
+// simpleProducers returns all simple producers, including the current one
+func (p *Producer) simpleProducers() []*SimpleProducer {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	sps := make([]*SimpleProducer, 0, len(p.pidToSimpleProducers)+1)
+	if p.currentProducer != nil {
+		sps = append(sps, p.currentProducer)
+	}
+	for _, sp := range p.pidToSimpleProducers {
+		sps = append(sps, sp)
+	}
+	return sps
+}
+
+// BeginTransaction starts a transaction. transactional.id must be set in config, and messages could only be added in a transaction.
+// producer id is got from the transaction coordinator in the first transaction, and the unfinished transaction of the same transactional.id is completed
+func (p *Producer) BeginTransaction() error {
+	if p.state == nil {
+		return errNotTransactional
+	}
+	return p.state.beginTransaction()
+}
+
+// SendOffsetsToTransaction commits offsets of the consumer group in the current transaction,
+// they are committed or aborted together with the messages. offsets are the next offsets to consume, map of topic -> partition -> offset
+func (p *Producer) SendOffsetsToTransaction(offsets map[string]map[int32]int64, groupID string) error {
+	if p.state == nil {
+		return errNotTransactional
+	}
+	return p.state.sendOffsetsToTxn(offsets, groupID)
+}
+
+// CommitTransaction sends all the buffered messages and commits the current transaction.
+// If any message of the transaction failed to be sent, including the ones flushed in background, the transaction is aborted and the send error is returned.
+// If committing itself fails, the transaction should be aborted by the caller
+func (p *Producer) CommitTransaction() error {
+	if p.state == nil {
+		return errNotTransactional
+	}
+	for _, sp := range p.simpleProducers() {
+		sp.Flush() // error is recorded in p.state
+	}
+	if err := p.state.txnError(); err != nil {
+		if abortErr := p.AbortTransaction(); abortErr != nil {
+			logger.Error(abortErr, "abort transaction error")
+		}
+		return fmt.Errorf("transaction aborted because of sending messages error: %w", err)
+	}
+	return p.state.endTxn(true)
+}
+
+// AbortTransaction drops the buffered messages and aborts the current transaction, the messages that have been sent are not visible to read_committed consumers
+func (p *Producer) AbortTransaction() error {
+	if p.state == nil {
+		return errNotTransactional
+	}
+	for _, sp := range p.simpleProducers() {
+		sp.clearMessages()
+	}
+	return p.state.endTxn(false)
+}
+
 // Close close all simple producers in the console producer
 func (p *Producer) Close() {
 	if p.currentProducer != nil {
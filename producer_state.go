@@ -0,0 +1,353 @@
+package healer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// max retry times of the requests to transaction coordinator and group coordinator, such as AddPartitionsToTxn and EndTxn
+const transactionRequestRetries = 10
+
+var (
+	errNotTransactional        = errors.New("transactional.id is not set")
+	errTransactionInProgress   = errors.New("transaction is already in progress")
+	errNoTransactionInProgress = errors.New("no transaction in progress")
+)
+
+type coordinatorKey struct {
+	key     string
+	keyType int8
+}
+
+// producerState holds the producer id, epoch and sequence numbers of each partition for idempotent producer,
+// and the transaction state for transactional producer.
+// It is shared by all SimpleProducers of a Producer, so sequence numbers keep increasing even if SimpleProducer is recreated.
+type producerState struct {
+	lock sync.Mutex
+
+	brokers        *Brokers
+	clientID       string
+	retryBackOffMS int
+
+	transactionalID      *string
+	transactionTimeoutMS int32
+	coordinators         map[coordinatorKey]*Broker
+	inTransaction        bool
+	// partitions and groups added to the current transaction. EndTxn is not needed if nothing is added
+	partitionsInTxn map[string]map[int32]bool
+	groupsInTxn     map[string]bool
+	// txnErr is the first error of sending messages in the current transaction, the transaction could not be committed if it is set
+	txnErr error
+
+	producerID    int64
+	producerEpoch int16
+
+	// next sequence number of each partition, it is increased after the batch is acknowledged
+	sequences map[string]map[int32]int32
+	// sequence numbers must be sent to broker in order, so assigning sequence and sending request are under the lock of the partition
+	partitionLocks map[string]map[int32]*sync.Mutex
+}
+
+func newProducerState(brokers *Brokers, config *ProducerConfig) *producerState {
+	s := &producerState{
+		brokers:        brokers,
+		clientID:       config.ClientID,
+		retryBackOffMS: config.RetryBackOffMS,
+		producerID:     -1,
+		producerEpoch:  -1,
+		sequences:      make(map[string]map[int32]int32),
+		partitionLocks: make(map[string]map[int32]*sync.Mutex),
+		coordinators:   make(map[coordinatorKey]*Broker),
+	}
+	if config.TransactionalID != "" {
+		transactionalID := config.TransactionalID
+		s.transactionalID = &transactionalID
+		s.transactionTimeoutMS = config.TransactionTimeoutMS
+	}
+	return s
+}
+
+func (s *producerState) transactional() bool {
+	return s.transactionalID != nil
+}
+
+// initProducerID gets a producer id from broker if the producer does not have one
+func (s *producerState) initProducerID() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.producerID != -1 {
+		return nil
+	}
+	return s.requestProducerID()
+}
+
+// requestProducerID requests InitProducerId and resets all sequence numbers. s.lock must be held.
+// If the producer already has an id, broker (InitProducerId v3+) bumps the epoch of it, or else a new id is returned.
+func (s *producerState) requestProducerID() error {
+	req := NewInitProducerIDRequest(s.clientID, s.transactionalID, s.transactionTimeoutMS)
+	req.ProducerID = s.producerID
+	req.ProducerEpoch = s.producerEpoch
+
+	var (
+		resp Response
+		err  error
+	)
+	if s.transactional() {
+		// transactional producer gets producer id from the transaction coordinator, and the previous transaction of the same transactional id is completed by it
+		resp, err = s.requestCoordinator(*s.transactionalID, CoordinatorTypeTransaction, req)
+	} else {
+		resp, err = s.brokers.Request(req)
+	}
+	if err != nil {
+		return fmt.Errorf("init producer id error: %w", err)
+	}
+	r := resp.(InitProducerIDResponse)
+
+	logger.Info("init producer id", "oldProducerID", s.producerID, "oldProducerEpoch", s.producerEpoch, "producerID", r.ProducerID, "producerEpoch", r.ProducerEpoch)
+	s.producerID = r.ProducerID
+	s.producerEpoch = r.ProducerEpoch
+	s.sequences = make(map[string]map[int32]int32)
+	return nil
+}
+
+// reset gets a new producer id (or bumps the epoch) after OUT_OF_ORDER_SEQUENCE_NUMBER or UNKNOWN_PRODUCER_ID.
+// producerID and producerEpoch are the ones in the failed request, nothing is done if they have been reset by others.
+func (s *producerState) reset(producerID int64, producerEpoch int16) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.producerID != producerID || s.producerEpoch != producerEpoch {
+		return nil
+	}
+	if err := s.requestProducerID(); err != nil {
+		// the old producer id must not be used anymore, a new one is requested by initProducerID
+		s.producerID, s.producerEpoch = -1, -1
+		return err
+	}
+	return nil
+}
+
+// partitionLock returns the lock of the partition
+func (s *producerState) partitionLock(topic string, partition int32) *sync.Mutex {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.partitionLocks[topic]; !ok {
+		s.partitionLocks[topic] = make(map[int32]*sync.Mutex)
+	}
+	if _, ok := s.partitionLocks[topic][partition]; !ok {
+		s.partitionLocks[topic][partition] = &sync.Mutex{}
+	}
+	return s.partitionLocks[topic][partition]
+}
+
+// nextSequence returns the producer id, epoch and base sequence for the next batch of the partition.
+// the sequence is not increased until the batch is acknowledged by commitSequence, so a failed batch does not leave a gap
+func (s *producerState) nextSequence(topic string, partition int32) (producerID int64, producerEpoch int16, baseSequence int32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.producerID, s.producerEpoch, s.sequences[topic][partition]
+}
+
+// commitSequence increases the sequence of the partition by count after the batch is acknowledged by broker.
+// nothing is done if producer id has been reset since the batch was built, the sequences start from 0 again then.
+// sequence wraps around to 0 after math.MaxInt32
+func (s *producerState) commitSequence(topic string, partition int32, producerID int64, producerEpoch int16, count int32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.producerID != producerID || s.producerEpoch != producerEpoch {
+		return
+	}
+	if _, ok := s.sequences[topic]; !ok {
+		s.sequences[topic] = make(map[int32]int32)
+	}
+
+	next := int64(s.sequences[topic][partition]) + int64(count)
+	if next > math.MaxInt32 {
+		next -= math.MaxInt32 + 1
+	}
+	s.sequences[topic][partition] = int32(next)
+}
+
+// getCoordinator returns the coordinator of the transactional id or group id, it is cached until the coordinator is changed
+func (s *producerState) getCoordinator(key string, keyType int8) (*Broker, error) {
+	k := coordinatorKey{key: key, keyType: keyType}
+	if coordinator, ok := s.coordinators[k]; ok {
+		return coordinator, nil
+	}
+
+	var (
+		r   FindCoordinatorResponse
+		err error
+	)
+	if keyType == CoordinatorTypeTransaction {
+		r, err = s.brokers.FindTransactionCoordinator(s.clientID, key)
+	} else {
+		r, err = s.brokers.FindCoordinator(s.clientID, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	coordinator, err := s.brokers.GetBroker(r.Coordinator.NodeID)
+	if err != nil {
+		return nil, fmt.Errorf("could not create coordinator broker %d: %w", r.Coordinator.NodeID, err)
+	}
+	logger.Info("find coordinator", "key", key, "keyType", keyType, "coordinator", coordinator.GetAddress())
+	s.coordinators[k] = coordinator
+	return coordinator, nil
+}
+
+// requestCoordinator sends req to the coordinator of key, and retries if error is retriable. s.lock must be held.
+func (s *producerState) requestCoordinator(key string, keyType int8, req Request) (resp Response, err error) {
+	for retries := 0; ; retries++ {
+		var coordinator *Broker
+		if coordinator, err = s.getCoordinator(key, keyType); err == nil {
+			resp, err = coordinator.RequestAndGet(req)
+			if err == nil {
+				return resp, nil
+			}
+			// COORDINATOR_NOT_AVAILABLE or NOT_COORDINATOR
+			if errors.Is(err, KafkaError(15)) || errors.Is(err, KafkaError(16)) {
+				delete(s.coordinators, coordinatorKey{key: key, keyType: keyType})
+			}
+			if !isRetriableProduceError(err) {
+				return nil, err
+			}
+		}
+		if retries >= transactionRequestRetries {
+			return nil, err
+		}
+		logger.Info("request coordinator failed, retry", "reason", err, "api", req.API(), "key", key, "retries", retries)
+		time.Sleep(time.Duration(s.retryBackOffMS) * time.Millisecond)
+	}
+}
+
+// beginTransaction starts a new transaction, producer id is requested from the transaction coordinator for the first transaction
+func (s *producerState) beginTransaction() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.transactional() {
+		return errNotTransactional
+	}
+	if s.inTransaction {
+		return errTransactionInProgress
+	}
+	// the epoch is bumped if the previous transaction failed, the sequences of the failed batches could not be used again
+	if s.producerID == -1 || s.txnErr != nil {
+		if err := s.requestProducerID(); err != nil {
+			return err
+		}
+	}
+
+	s.inTransaction = true
+	s.partitionsInTxn = make(map[string]map[int32]bool)
+	s.groupsInTxn = make(map[string]bool)
+	s.txnErr = nil
+	return nil
+}
+
+func (s *producerState) isInTransaction() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.inTransaction
+}
+
+// setTxnError records the first error of sending messages in the current transaction.
+// messages may be sent in background (by the flush ticker), so the error is kept here and checked when committing
+func (s *producerState) setTxnError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.inTransaction && s.txnErr == nil {
+		s.txnErr = err
+	}
+}
+
+func (s *producerState) txnError() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.txnErr
+}
+
+// addPartitionToTxn adds the partition to the current transaction by AddPartitionsToTxn, it must be called before producing to the partition
+func (s *producerState) addPartitionToTxn(topic string, partition int32) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.inTransaction {
+		return errNoTransactionInProgress
+	}
+	if s.partitionsInTxn[topic][partition] {
+		return nil
+	}
+
+	req := NewAddPartitionsToTxnRequest(s.clientID, *s.transactionalID, s.producerID, s.producerEpoch)
+	req.AddPartition(topic, partition)
+	if _, err := s.requestCoordinator(*s.transactionalID, CoordinatorTypeTransaction, req); err != nil {
+		return fmt.Errorf("add partition %s-%d to transaction error: %w", topic, partition, err)
+	}
+
+	if _, ok := s.partitionsInTxn[topic]; !ok {
+		s.partitionsInTxn[topic] = make(map[int32]bool)
+	}
+	s.partitionsInTxn[topic][partition] = true
+	return nil
+}
+
+// sendOffsetsToTxn adds the group to the current transaction by AddOffsetsToTxn, and then commits offsets to the group coordinator by TxnOffsetCommit
+func (s *producerState) sendOffsetsToTxn(offsets map[string]map[int32]int64, groupID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.inTransaction {
+		return errNoTransactionInProgress
+	}
+
+	if !s.groupsInTxn[groupID] {
+		req := NewAddOffsetsToTxnRequest(s.clientID, *s.transactionalID, s.producerID, s.producerEpoch, groupID)
+		if _, err := s.requestCoordinator(*s.transactionalID, CoordinatorTypeTransaction, req); err != nil {
+			return fmt.Errorf("add offsets of group %s to transaction error: %w", groupID, err)
+		}
+		s.groupsInTxn[groupID] = true
+	}
+
+	req := NewTxnOffsetCommitRequest(s.clientID, *s.transactionalID, groupID, s.producerID, s.producerEpoch)
+	for topic, partitions := range offsets {
+		for partition, offset := range partitions {
+			req.AddPartition(topic, partition, offset)
+		}
+	}
+	if _, err := s.requestCoordinator(groupID, CoordinatorTypeGroup, req); err != nil {
+		return fmt.Errorf("commit offsets of group %s in transaction error: %w", groupID, err)
+	}
+	return nil
+}
+
+// endTxn commits or aborts the current transaction by EndTxn
+func (s *producerState) endTxn(commit bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.inTransaction {
+		return errNoTransactionInProgress
+	}
+
+	// nothing is added to the transaction, so the transaction coordinator does not know it
+	if len(s.partitionsInTxn) == 0 && len(s.groupsInTxn) == 0 {
+		s.inTransaction = false
+		return nil
+	}
+
+	req := NewEndTxnRequest(s.clientID, *s.transactionalID, s.producerID, s.producerEpoch, commit)
+	if _, err := s.requestCoordinator(*s.transactionalID, CoordinatorTypeTransaction, req); err != nil {
+		return fmt.Errorf("end transaction (commit: %v) error: %w", commit, err)
+	}
+	s.inTransaction = false
+	return nil
+}
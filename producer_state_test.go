@@ -0,0 +1,221 @@
+package healer
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestProducerStateSequence(t *testing.T) {
+	mockey.PatchConvey("sequence numbers of each partition", t, func() {
+		s := newProducerState(&Brokers{}, &defaultProducerConfig)
+		mockey.Mock((*Brokers).Request).Return(InitProducerIDResponse{ProducerID: 100, ProducerEpoch: 0}, nil).Build()
+
+		convey.So(s.initProducerID(), convey.ShouldBeNil)
+		convey.So(s.producerID, convey.ShouldEqual, 100)
+
+		pid, epoch, seq := s.nextSequence("test", 0)
+		convey.So(pid, convey.ShouldEqual, 100)
+		convey.So(epoch, convey.ShouldEqual, 0)
+		convey.So(seq, convey.ShouldEqual, 0)
+
+		// not increased until committed
+		_, _, seq = s.nextSequence("test", 0)
+		convey.So(seq, convey.ShouldEqual, 0)
+		s.commitSequence("test", 0, 100, 0, 10)
+		_, _, seq = s.nextSequence("test", 0)
+		convey.So(seq, convey.ShouldEqual, 10)
+		_, _, seq = s.nextSequence("test", 1)
+		convey.So(seq, convey.ShouldEqual, 0)
+
+		// producer id has been reset since the batch was built
+		s.commitSequence("test", 0, 100, 1, 10)
+		_, _, seq = s.nextSequence("test", 0)
+		convey.So(seq, convey.ShouldEqual, 10)
+
+		// wraps around to 0
+		s.sequences["test"][0] = math.MaxInt32
+		s.commitSequence("test", 0, 100, 0, 2)
+		_, _, seq = s.nextSequence("test", 0)
+		convey.So(seq, convey.ShouldEqual, 1)
+	})
+
+	mockey.PatchConvey("sequence of a failed batch is not used by other records", t, func() {
+		config := DefaultProducerConfig()
+		config.EnableIdempotence = true
+		config.Retries = 1
+		config.RetryBackOffMS = 0
+		p := &SimpleProducer{
+			config:           &config,
+			leader:           &Broker{},
+			topic:            "test",
+			partition:        0,
+			compressionValue: COMPRESSION_NONE,
+			compressor:       NewCompressorWithLevel("none", 0),
+			state:            newProducerState(&Brokers{}, &config),
+		}
+		p.state.producerID = 100
+		p.state.producerEpoch = 0
+
+		type sent struct {
+			epoch    int16
+			sequence int32
+		}
+		batches := make([]sent, 0)
+		errs := make([]error, 0)
+		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(uint16(3)).Build()
+		mockey.Mock((*Brokers).Request).Return(InitProducerIDResponse{ProducerID: 100, ProducerEpoch: 1}, nil).Build()
+		mockey.Mock((*RecordBatch).encode).To(func(r *RecordBatch, version uint16, compressor Compressor) ([]byte, error) {
+			batches = append(batches, sent{r.ProducerEpoch, r.BaseSequence})
+			return nil, nil
+		}).Build()
+		mockey.Mock((*Broker).RequestAndGet).To(func(broker *Broker, r Request) (Response, error) {
+			err := errs[0]
+			errs = errs[1:]
+			return nil, err
+		}).Build()
+
+		messageSet := MessageSet{{Value: []byte("value-1")}, {Value: []byte("value-2")}}
+
+		// the response of the first request is lost, and the retried one is duplicate
+		errs = []error{io.EOF, KafkaError(46)}
+		convey.So(p.flush(messageSet), convey.ShouldBeNil)
+		_, _, seq := p.state.nextSequence("test", 0)
+		convey.So(seq, convey.ShouldEqual, 2)
+
+		// duplicate sequence of the first request is not success, and producer id is reset
+		batches = batches[:0]
+		errs = []error{KafkaError(46)}
+		convey.So(errors.Is(p.flush(messageSet), KafkaError(46)), convey.ShouldBeTrue)
+		convey.So(p.state.producerEpoch, convey.ShouldEqual, 1)
+
+		// the batch may have been written after timeouts, the next batch is sent with the new epoch
+		errs = []error{io.EOF, io.EOF, nil}
+		convey.So(p.flush(messageSet), convey.ShouldNotBeNil)
+		convey.So(p.flush(messageSet), convey.ShouldBeNil)
+		convey.So(batches, convey.ShouldResemble, []sent{{0, 2}, {1, 0}, {1, 0}})
+	})
+
+	mockey.PatchConvey("reset producer id", t, func() {
+		s := newProducerState(&Brokers{}, &defaultProducerConfig)
+		s.producerID = 100
+		s.producerEpoch = 0
+		s.sequences["test"] = map[int32]int32{0: 10}
+		mockey.Mock((*Brokers).Request).Return(InitProducerIDResponse{ProducerID: 100, ProducerEpoch: 1}, nil).Build()
+
+		// reset by others already
+		convey.So(s.reset(99, 0), convey.ShouldBeNil)
+		convey.So(s.producerEpoch, convey.ShouldEqual, 0)
+		convey.So(s.sequences["test"][0], convey.ShouldEqual, 10)
+
+		convey.So(s.reset(100, 0), convey.ShouldBeNil)
+		convey.So(s.producerEpoch, convey.ShouldEqual, 1)
+		_, _, seq := s.nextSequence("test", 0)
+		convey.So(seq, convey.ShouldEqual, 0)
+	})
+}
+
+func TestProducerStateTransaction(t *testing.T) {
+	mockey.PatchConvey("transaction requests are sent to coordinator in order", t, func() {
+		config := DefaultProducerConfig()
+		config.TransactionalID = "txn-1"
+		s := newProducerState(&Brokers{}, &config)
+
+		apis := make([]uint16, 0)
+		mockey.Mock((*producerState).getCoordinator).Return(&Broker{}, nil).Build()
+		mockey.Mock((*Broker).RequestAndGet).To(func(broker *Broker, r Request) (Response, error) {
+			apis = append(apis, r.API())
+			switch r.API() {
+			case API_InitProducerId:
+				return InitProducerIDResponse{ProducerID: 100, ProducerEpoch: 1}, nil
+			case API_AddPartitionsToTxn:
+				return AddPartitionsToTxnResponse{}, nil
+			case API_AddOffsetsToTxn:
+				return AddOffsetsToTxnResponse{}, nil
+			case API_TxnOffsetCommit:
+				return TxnOffsetCommitResponse{}, nil
+			case API_EndTxn:
+				return EndTxnResponse{}, nil
+			}
+			return nil, nil
+		}).Build()
+
+		convey.So(s.addPartitionToTxn("test", 0), convey.ShouldEqual, errNoTransactionInProgress)
+
+		convey.So(s.beginTransaction(), convey.ShouldBeNil)
+		convey.So(s.beginTransaction(), convey.ShouldEqual, errTransactionInProgress)
+		convey.So(s.producerID, convey.ShouldEqual, 100)
+
+		convey.So(s.addPartitionToTxn("test", 0), convey.ShouldBeNil)
+		convey.So(s.addPartitionToTxn("test", 0), convey.ShouldBeNil) // added already
+		convey.So(s.sendOffsetsToTxn(map[string]map[int32]int64{"input": {0: 10}}, "group"), convey.ShouldBeNil)
+		convey.So(s.endTxn(true), convey.ShouldBeNil)
+		convey.So(s.isInTransaction(), convey.ShouldBeFalse)
+
+		// nothing added, EndTxn is not sent
+		convey.So(s.beginTransaction(), convey.ShouldBeNil)
+		convey.So(s.endTxn(false), convey.ShouldBeNil)
+
+		convey.So(apis, convey.ShouldResemble, []uint16{API_InitProducerId, API_AddPartitionsToTxn, API_AddOffsetsToTxn, API_TxnOffsetCommit, API_EndTxn})
+	})
+
+	mockey.PatchConvey("retriable error is retried", t, func() {
+		config := DefaultProducerConfig()
+		config.TransactionalID = "txn-1"
+		config.RetryBackOffMS = 0
+		s := newProducerState(&Brokers{}, &config)
+		s.inTransaction = true
+		s.partitionsInTxn = map[string]map[int32]bool{"test": {0: true}}
+
+		count := 0
+		mockey.Mock((*producerState).getCoordinator).Return(&Broker{}, nil).Build()
+		mockey.Mock((*Broker).RequestAndGet).To(func(broker *Broker, r Request) (Response, error) {
+			count++
+			if count < 3 {
+				return EndTxnResponse{ErrorCode: 51}, KafkaError(51) // CONCURRENT_TRANSACTIONS
+			}
+			return EndTxnResponse{}, nil
+		}).Build()
+
+		convey.So(s.endTxn(true), convey.ShouldBeNil)
+		convey.So(count, convey.ShouldEqual, 3)
+	})
+	mockey.PatchConvey("transaction is aborted if messages failed to be sent", t, func() {
+		config := DefaultProducerConfig()
+		config.TransactionalID = "txn-1"
+		s := newProducerState(&Brokers{}, &config)
+		s.producerID = 100
+		p := &Producer{state: s, pidToSimpleProducers: make(map[int32]*SimpleProducer)}
+
+		committed := make([]bool, 0)
+		mockey.Mock((*producerState).getCoordinator).Return(&Broker{}, nil).Build()
+		mockey.Mock((*Broker).RequestAndGet).To(func(broker *Broker, r Request) (Response, error) {
+			if r.API() == API_InitProducerId {
+				return InitProducerIDResponse{ProducerID: 100, ProducerEpoch: 1}, nil
+			}
+			committed = append(committed, r.(*EndTxnRequest).Committed)
+			return EndTxnResponse{}, nil
+		}).Build()
+
+		s.setTxnError(KafkaError(7)) // not in transaction, ignored
+		convey.So(p.BeginTransaction(), convey.ShouldBeNil)
+		convey.So(s.txnError(), convey.ShouldBeNil)
+		s.partitionsInTxn["test"] = map[int32]bool{0: true}
+
+		s.setTxnError(KafkaError(7))
+		s.setTxnError(KafkaError(6)) // only the first error is kept
+		err := p.CommitTransaction()
+		convey.So(errors.Is(err, KafkaError(7)), convey.ShouldBeTrue)
+		convey.So(s.isInTransaction(), convey.ShouldBeFalse)
+		convey.So(committed, convey.ShouldResemble, []bool{false})
+
+		// epoch is bumped in the next transaction
+		convey.So(p.BeginTransaction(), convey.ShouldBeNil)
+		convey.So(s.producerEpoch, convey.ShouldEqual, 1)
+		convey.So(s.txnError(), convey.ShouldBeNil)
+	})
+}
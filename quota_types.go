@@ -0,0 +1,115 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// entity types of client quotas
+const (
+	QuotaEntityUser     = "user"
+	QuotaEntityClientID = "client-id"
+	QuotaEntityIP       = "ip"
+)
+
+// QuotaMatchType is how the entity name is matched in DescribeClientQuotasRequest
+type QuotaMatchType int8
+
+const (
+	// QuotaMatchExact matches the entity with the name
+	QuotaMatchExact QuotaMatchType = 0
+	// QuotaMatchDefault matches the default entity
+	QuotaMatchDefault QuotaMatchType = 1
+	// QuotaMatchAny matches any entity, including the default one
+	QuotaMatchAny QuotaMatchType = 2
+)
+
+func (t QuotaMatchType) String() string {
+	switch t {
+	case QuotaMatchExact:
+		return "EXACT"
+	case QuotaMatchDefault:
+		return "DEFAULT"
+	case QuotaMatchAny:
+		return "ANY"
+	default:
+		return "ERROR"
+	}
+}
+
+func (t QuotaMatchType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *QuotaMatchType) UnmarshalText(text []byte) error {
+	switch strings.ToUpper(string(text)) {
+	case "EXACT", "0":
+		*t = QuotaMatchExact
+	case "DEFAULT", "1":
+		*t = QuotaMatchDefault
+	case "ANY", "2":
+		*t = QuotaMatchAny
+	default:
+		return fmt.Errorf("unknown QuotaMatchType: %s", text)
+	}
+	return nil
+}
+
+// QuotaFilterComponent filters the quotas by one entity type. Match is only used by QuotaMatchExact
+type QuotaFilterComponent struct {
+	EntityType string
+	MatchType  QuotaMatchType
+	Match      *string
+}
+
+// QuotaEntity is one part of the entity that quotas are applied to, such as user=alice. nil EntityName is the default entity
+type QuotaEntity struct {
+	EntityType string
+	EntityName *string
+}
+
+// QuotaOp sets the value of the quota key, or removes it if Remove is true
+type QuotaOp struct {
+	Key    string
+	Value  float64
+	Remove bool
+}
+
+// decodeQuotaEntity decodes [entity_type entity_name]
+func decodeQuotaEntity(payload []byte) (entity []QuotaEntity, offset int) {
+	count := int32(binary.BigEndian.Uint32(payload))
+	offset += 4
+	entity = make([]QuotaEntity, count)
+	for i := range entity {
+		var o int
+		entity[i].EntityType, o = nonnullableString(payload[offset:])
+		offset += o
+		entity[i].EntityName, o = nullableString(payload[offset:])
+		offset += o
+	}
+	return entity, offset
+}
+
+// writeQuotaEntity encodes [entity_type entity_name]
+func writeQuotaEntity(buf *bytes.Buffer, entity []QuotaEntity) {
+	binary.Write(buf, binary.BigEndian, int32(len(entity)))
+	for _, e := range entity {
+		writeString(buf, e.EntityType)
+		writeNullableString(buf, e.EntityName)
+	}
+}
+
+// quotaEntityString returns the entity like user=alice,client-id=<default>
+func quotaEntityString(entity []QuotaEntity) string {
+	parts := make([]string, 0, len(entity))
+	for _, e := range entity {
+		name := "<default>"
+		if e.EntityName != nil {
+			name = *e.EntityName
+		}
+		parts = append(parts, e.EntityType+"="+name)
+	}
+	return strings.Join(parts, ",")
+}
@@ -8,52 +8,77 @@ import (
 // TODO type define ApiKey and change api_XXX to ApiKey type
 
 const (
-	API_ProduceRequest              uint16 = 0
-	API_FetchRequest                uint16 = 1
-	API_OffsetRequest               uint16 = 2
-	API_MetadataRequest             uint16 = 3
-	API_OffsetCommitRequest         uint16 = 8
-	API_OffsetFetchRequest          uint16 = 9
-	API_FindCoordinator             uint16 = 10
-	API_JoinGroup                   uint16 = 11
-	API_Heartbeat                   uint16 = 12
-	API_LeaveGroup                  uint16 = 13
-	API_SyncGroup                   uint16 = 14
-	API_DescribeGroups              uint16 = 15
-	API_ListGroups                  uint16 = 16
-	API_SaslHandshake               uint16 = 17
-	API_ApiVersions                 uint16 = 18
-	API_CreateTopics                uint16 = 19
-	API_DeleteTopics                uint16 = 20
-	API_DescribeAcls                uint16 = 29
-	API_CreateAcls                  uint16 = 30
-	API_DeleteAcls                  uint16 = 31
-	API_DescribeConfigs             uint16 = 32
-	API_AlterConfigs                uint16 = 33
-	API_DescribeLogDirs             uint16 = 35
-	API_SaslAuthenticate            uint16 = 36
-	API_CreatePartitions            uint16 = 37
-	API_Delete_Groups               uint16 = 42
-	API_ElectLeaders                uint16 = 43
-	API_IncrementalAlterConfigs     uint16 = 44
-	API_AlterPartitionReassignments uint16 = 45
-	API_ListPartitionReassignments  uint16 = 46
+	API_ProduceRequest               uint16 = 0
+	API_FetchRequest                 uint16 = 1
+	API_OffsetRequest                uint16 = 2
+	API_MetadataRequest              uint16 = 3
+	API_OffsetCommitRequest          uint16 = 8
+	API_OffsetFetchRequest           uint16 = 9
+	API_FindCoordinator              uint16 = 10
+	API_JoinGroup                    uint16 = 11
+	API_Heartbeat                    uint16 = 12
+	API_LeaveGroup                   uint16 = 13
+	API_SyncGroup                    uint16 = 14
+	API_DescribeGroups               uint16 = 15
+	API_ListGroups                   uint16 = 16
+	API_SaslHandshake                uint16 = 17
+	API_ApiVersions                  uint16 = 18
+	API_CreateTopics                 uint16 = 19
+	API_DeleteTopics                 uint16 = 20
+	API_DeleteRecords                uint16 = 21
+	API_InitProducerId               uint16 = 22
+	API_OffsetForLeaderEpoch         uint16 = 23
+	API_AddPartitionsToTxn           uint16 = 24
+	API_AddOffsetsToTxn              uint16 = 25
+	API_EndTxn                       uint16 = 26
+	API_TxnOffsetCommit              uint16 = 28
+	API_DescribeAcls                 uint16 = 29
+	API_CreateAcls                   uint16 = 30
+	API_DeleteAcls                   uint16 = 31
+	API_DescribeConfigs              uint16 = 32
+	API_AlterConfigs                 uint16 = 33
+	API_DescribeLogDirs              uint16 = 35
+	API_SaslAuthenticate             uint16 = 36
+	API_CreatePartitions             uint16 = 37
+	API_Delete_Groups                uint16 = 42
+	API_ElectLeaders                 uint16 = 43
+	API_IncrementalAlterConfigs      uint16 = 44
+	API_AlterPartitionReassignments  uint16 = 45
+	API_ListPartitionReassignments   uint16 = 46
+	API_OffsetDelete                 uint16 = 47
+	API_DescribeClientQuotas         uint16 = 48
+	API_AlterClientQuotas            uint16 = 49
+	API_DescribeUserScramCredentials uint16 = 50
+	API_AlterUserScramCredentials    uint16 = 51
 )
 
 // healer only implements these versions of the protocol, only version 0 is supported if not defined here
 // It must be sorted from high to low
 var availableVersions map[uint16][]uint16 = map[uint16][]uint16{
-	API_MetadataRequest:     {7, 4, 1},
-	API_FetchRequest:        {10, 7, 0},
-	API_OffsetRequest:       {1, 0},
-	API_OffsetCommitRequest: {2, 0},
-	API_OffsetFetchRequest:  {1, 0},
-	API_ListGroups:          {5, 4, 3, 2, 1, 0},
-	API_SaslHandshake:       {1, 0},
-	API_DescribeAcls:        {2, 1, 0},
-	API_CreateAcls:          {3, 2, 1, 0},
-	API_DeleteAcls:          {3, 2, 1, 0},
-	API_CreatePartitions:    {2, 0},
+	API_ProduceRequest:       {7, 3, 0},
+	API_MetadataRequest:      {7, 4, 1},
+	API_FetchRequest:         {11, 10, 7, 0},
+	API_OffsetRequest:        {1, 0},
+	API_OffsetCommitRequest:  {7, 6, 5, 4, 3, 2, 0},
+	API_OffsetFetchRequest:   {2, 1, 0},
+	API_FindCoordinator:      {1, 0},
+	API_JoinGroup:            {5, 4, 3, 2, 1, 0},
+	API_Heartbeat:            {3, 2, 1, 0},
+	API_SyncGroup:            {3, 2, 1, 0},
+	API_ListGroups:           {5, 4, 3, 2, 1, 0},
+	API_SaslHandshake:        {1, 0},
+	API_SaslAuthenticate:     {1, 0},
+	API_DescribeAcls:         {2, 1, 0},
+	API_CreateAcls:           {3, 2, 1, 0},
+	API_DeleteAcls:           {3, 2, 1, 0},
+	API_CreatePartitions:     {2, 0},
+	API_InitProducerId:       {4, 3, 2, 1, 0},
+	API_DeleteRecords:        {1, 0},
+	API_OffsetForLeaderEpoch: {3, 2, 1, 0},
+	API_AddPartitionsToTxn:   {1, 0},
+	API_AddOffsetsToTxn:      {1, 0},
+	API_EndTxn:               {1, 0},
+	API_TxnOffsetCommit:      {2, 1, 0},
 }
 
 // RequestHeader is the request header, which is used in all requests. It contains apiKey, apiVersion, correlationID, clientID
@@ -3,9 +3,7 @@ package healer
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"time"
 )
 
 // Response is the interface of all response. Error() returns the error abstracted from the error code of the response
@@ -25,6 +23,9 @@ type defaultReadParser struct {
 	api     uint16
 	version uint16
 	timeout int
+
+	pipeline *pipeline
+	req      *inFlightRequest
 }
 
 // ReadAndParse read a whole response data from broker and parse it
@@ -41,57 +42,17 @@ func (p defaultReadParser) ReadAndParse() (Response, error) {
 	return resp, nil
 }
 
-// Read read a whole response data from broker. it firstly read length of the response data, then read the whole response data
+// Read waits for the whole response data which is read from broker by the reader goroutine of the pipeline
 func (p defaultReadParser) Read() ([]byte, error) {
-	//TODO use LimitedReader
-	l := 0
-	responseLengthBuf := make([]byte, 4)
-	for {
-		if p.timeout > 0 {
-			p.broker.conn.SetReadDeadline(time.Now().Add(time.Duration(p.timeout) * time.Millisecond))
-		}
-		length, err := p.broker.conn.Read(responseLengthBuf[l:])
-		if err != nil {
-			return nil, err
-		}
-
-		if length+l == 4 {
-			break
-		}
-		l += length
-	}
-	responseLength := int(binary.BigEndian.Uint32(responseLengthBuf))
-	resp := make([]byte, 4+responseLength)
-
-	readLength := 0
-	for {
-		if p.timeout > 0 {
-			p.broker.conn.SetReadDeadline(time.Now().Add(time.Duration(p.timeout) * time.Millisecond))
-		}
-		length, err := p.broker.conn.Read(resp[4+readLength:])
-		if err != nil {
-			return nil, err
-		}
-
-		readLength += length
-		if readLength > responseLength {
-			return nil, errors.New("fetch more data than needed while read response")
-		}
-		if readLength == responseLength {
-			break
-		}
-	}
-	copy(resp[0:4], responseLengthBuf)
-	// logger.V(5).Info("response info", "length", len(resp), "CorrelationID", binary.BigEndian.Uint32(resp[4:]))
-	return resp, nil
+	return p.pipeline.wait(p.req)
 }
 
 func (p defaultReadParser) Parse(data []byte) (Response, error) {
 	switch p.api {
 	case API_Heartbeat:
-		return NewHeartbeatResponse(data)
+		return NewHeartbeatResponse(data, p.version)
 	case API_ProduceRequest:
-		return NewProduceResponse(data)
+		return NewProduceResponse(data, p.version)
 	case API_MetadataRequest:
 		return NewMetadataResponse(data, p.version)
 	case API_ApiVersions:
@@ -99,23 +60,23 @@ func (p defaultReadParser) Parse(data []byte) (Response, error) {
 	case API_SaslHandshake:
 		return NewSaslHandshakeResponse(data)
 	case API_SaslAuthenticate:
-		return NewSaslAuthenticateResponse(data)
+		return NewSaslAuthenticateResponse(data, p.version)
 	case API_OffsetRequest:
 		return NewOffsetsResponse(data, p.version)
 	case API_OffsetFetchRequest:
-		return NewOffsetFetchResponse(data)
+		return NewOffsetFetchResponse(data, p.version)
 	case API_FindCoordinator:
 		return NewFindCoordinatorResponse(data, p.version)
 	case API_JoinGroup:
-		return NewJoinGroupResponse(data)
+		return NewJoinGroupResponse(data, p.version)
 	case API_LeaveGroup:
 		return NewLeaveGroupResponse(data)
 	case API_OffsetCommitRequest:
-		return NewOffsetCommitResponse(data)
+		return NewOffsetCommitResponse(data, p.version)
 	case API_DescribeGroups:
 		return NewDescribeGroupsResponse(data)
 	case API_SyncGroup:
-		return NewSyncGroupResponse(data)
+		return NewSyncGroupResponse(data, p.version)
 	case API_DescribeConfigs:
 		return NewDescribeConfigsResponse(data)
 	case API_AlterPartitionReassignments:
@@ -146,6 +107,30 @@ func (p defaultReadParser) Parse(data []byte) (Response, error) {
 		return NewDescribeLogDirsResponse(data, p.version)
 	case API_ElectLeaders:
 		return NewElectLeadersResponse(data, p.version)
+	case API_InitProducerId:
+		return NewInitProducerIDResponse(data, p.version)
+	case API_DeleteRecords:
+		return NewDeleteRecordsResponse(data, p.version)
+	case API_OffsetDelete:
+		return NewOffsetDeleteResponse(data, p.version)
+	case API_DescribeClientQuotas:
+		return NewDescribeClientQuotasResponse(data, p.version)
+	case API_AlterClientQuotas:
+		return NewAlterClientQuotasResponse(data, p.version)
+	case API_DescribeUserScramCredentials:
+		return NewDescribeUserScramCredentialsResponse(data, p.version)
+	case API_AlterUserScramCredentials:
+		return NewAlterUserScramCredentialsResponse(data, p.version)
+	case API_OffsetForLeaderEpoch:
+		return NewOffsetForLeaderEpochResponse(data, p.version)
+	case API_AddPartitionsToTxn:
+		return NewAddPartitionsToTxnResponse(data, p.version)
+	case API_AddOffsetsToTxn:
+		return NewAddOffsetsToTxnResponse(data, p.version)
+	case API_EndTxn:
+		return NewEndTxnResponse(data, p.version)
+	case API_TxnOffsetCommit:
+		return NewTxnOffsetCommitResponse(data, p.version)
 	}
 	return nil, fmt.Errorf("parsing api %d not implemented", p.api)
 }
@@ -0,0 +1,36 @@
+package healer
+
+// roundRobinAssignmentStrategy lays out all the partitions of the subscribed topics, and assigns them to the members one by one.
+// a member is skipped if it does not subscribe the topic of the partition
+type roundRobinAssignmentStrategy struct {
+}
+
+// Name implements AssignmentStrategy interface
+func (r *roundRobinAssignmentStrategy) Name() string {
+	return AssignmentStrategyRoundRobin
+}
+
+// Assign implements AssignmentStrategy interface
+func (r *roundRobinAssignmentStrategy) Assign(members []Member, topicMetadatas []TopicMetadata) GroupAssignment {
+	memberIDs, subscriptions, partitions := subscribedPartitions(members, topicMetadatas)
+
+	assignments := make(map[string][]topicPartition)
+	for _, memberID := range memberIDs {
+		assignments[memberID] = make([]topicPartition, 0)
+	}
+
+	next := 0
+	for _, tp := range partitions {
+		for i := 0; i < len(memberIDs); i++ {
+			memberID := memberIDs[(next+i)%len(memberIDs)]
+			if subscriptions[memberID][tp.topic] {
+				assignments[memberID] = append(assignments[memberID], tp)
+				next = (next + i + 1) % len(memberIDs)
+				break
+			}
+		}
+	}
+
+	logger.V(5).Info("create tp assignments by RoundRobinAssignmentStrategy", "assignment", assignments)
+	return newGroupAssignment(assignments)
+}
@@ -6,42 +6,71 @@ import (
 	"strings"
 )
 
+// SaslAuth returns the first sasl_auth_bytes of a sasl mechanism
 type SaslAuth interface {
 	Encode() []byte
 }
 
+// SaslAuthChallenger is a SaslAuth which needs more than one round trip, such as SCRAM.
+// Challenge handles sasl_auth_bytes from server and returns the bytes to send, done is true if the authentication completes
+type SaslAuthChallenger interface {
+	SaslAuth
+	Challenge(serverBytes []byte) (clientBytes []byte, done bool, err error)
+}
+
+// NewSaslAuth creates a SaslAuth for the mechanism in config. PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 and OAUTHBEARER are supported
+func NewSaslAuth(config SaslConfig) (SaslAuth, error) {
+	switch strings.ToUpper(config.Mechanism) {
+	case "PLAIN":
+		return NewPlainSasl(config.User, config.Password), nil
+	case ScramSHA256.Name:
+		return NewScramSasl(ScramSHA256, config.User, config.Password), nil
+	case ScramSHA512.Name:
+		return NewScramSasl(ScramSHA512, config.User, config.Password), nil
+	case "OAUTHBEARER":
+		provider, err := config.tokenProvider()
+		if err != nil {
+			return nil, err
+		}
+		return NewOAuthBearerSasl(provider)
+	}
+	return nil, fmt.Errorf("%s NOT support for now", config.Mechanism)
+}
+
 /*
 SaslAuthenticate API (Key: 36):
 
 Requests:
-SaslAuthenticate Request (Version: 0) => sasl_auth_bytes
+SaslAuthenticate Request (Version: 0-1) => sasl_auth_bytes
   sasl_auth_bytes => BYTES
 
 FIELD	DESCRIPTION
 sasl_auth_bytes	SASL authentication bytes from client as defined by the SASL mechanism.
 */
 
-// version0
+// version 0 and 1, the request of version 1 is the same as version 0
 type SaslAuthenticateRequest struct {
 	*RequestHeader
 	SaslAuthBytes []byte
 }
 
+// NewSaslAuthenticateRequest creates a SaslAuthenticateRequest with the first sasl_auth_bytes of the mechanism.
+// use NewSaslAuth and NewSaslAuthenticateRequestWithBytes for the mechanisms having more than one round trip
 func NewSaslAuthenticateRequest(clientID string, user, password, typ string) (r SaslAuthenticateRequest) {
-	var saslAuth SaslAuth
-	switch strings.ToLower(typ) {
-	case "plain":
-		saslAuth = NewPlainSasl(user, password)
-	default:
-		logger.Error(fmt.Errorf("%s NOT support for now", typ), "not supported sasl type")
+	saslAuth, err := NewSaslAuth(SaslConfig{Mechanism: typ, User: user, Password: password})
+	if err != nil {
+		logger.Error(err, "not supported sasl type")
 		return r
 	}
+	return NewSaslAuthenticateRequestWithBytes(clientID, saslAuth.Encode())
+}
 
+// NewSaslAuthenticateRequestWithBytes creates a SaslAuthenticateRequest with sasl_auth_bytes
+func NewSaslAuthenticateRequestWithBytes(clientID string, saslAuthBytes []byte) SaslAuthenticateRequest {
 	requestHeader := &RequestHeader{
 		APIKey:   API_SaslAuthenticate,
 		ClientID: &clientID,
 	}
-	saslAuthBytes := saslAuth.Encode()
 	return SaslAuthenticateRequest{requestHeader, saslAuthBytes}
 }
 
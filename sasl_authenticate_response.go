@@ -11,14 +11,21 @@ type SaslAuthenticateResponse struct {
 	ErrorCode     int16
 	ErrorMessage  string
 	SaslAuthBytes []byte
+	// SessionLifetimeMS is the lifetime of the authenticated session in v1+, 0 if the session does not expire.
+	// the connection must be re-authenticated before it expires, or else the broker closes it
+	SessionLifetimeMS int64
 }
 
 func (r SaslAuthenticateResponse) Error() error {
-	return getErrorFromErrorCode(r.ErrorCode)
+	err := getErrorFromErrorCode(r.ErrorCode)
+	if err != nil && r.ErrorMessage != "" {
+		return fmt.Errorf("%w: %s", err, r.ErrorMessage)
+	}
+	return err
 }
 
 // NewSaslAuthenticateResponse create a NewSaslAuthenticateResponse instance from response payload bytes
-func NewSaslAuthenticateResponse(payload []byte) (r SaslAuthenticateResponse, err error) {
+func NewSaslAuthenticateResponse(payload []byte, version uint16) (r SaslAuthenticateResponse, err error) {
 	var offset = 0
 
 	responseLength := int(binary.BigEndian.Uint32(payload))
@@ -44,6 +51,11 @@ func NewSaslAuthenticateResponse(payload []byte) (r SaslAuthenticateResponse, er
 	offset += 4
 	r.SaslAuthBytes = make([]byte, l)
 	copy(r.SaslAuthBytes, payload[offset:])
+	offset += l
+
+	if version >= 1 {
+		r.SessionLifetimeMS = int64(binary.BigEndian.Uint64(payload[offset:]))
+	}
 
 	return r, err
 }
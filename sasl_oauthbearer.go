@@ -0,0 +1,183 @@
+package healer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the token is refreshed after this fraction of its lifetime, so a fresh token is used before the old one expires
+const tokenRefreshWindowFactor = 0.8
+
+var (
+	errNoTokenProvider = errors.New("token provider, token file or token env must be set for OAUTHBEARER")
+	errInvalidJWT      = errors.New("invalid jwt")
+	errTokenExpired    = errors.New("token is expired")
+)
+
+// AccessToken is the token sent to broker in SASL/OAUTHBEARER
+type AccessToken struct {
+	Token string
+	// Extensions are optional SASL extensions sent with the token (RFC 7628)
+	Extensions map[string]string
+}
+
+// TokenProvider provides the token for SASL/OAUTHBEARER.
+// Token is called every time a connection is authenticated or re-authenticated, so the provider should cache the token and refresh it before expiry
+type TokenProvider interface {
+	Token() (*AccessToken, error)
+}
+
+// jwtTokenProvider reads a JWT from a file or an environment variable. The token is cached and read again
+// after tokenRefreshWindowFactor of its lifetime, which is got from the exp claim
+type jwtTokenProvider struct {
+	read func() (string, error)
+
+	lock      sync.Mutex
+	token     *AccessToken
+	refreshAt time.Time
+}
+
+// NewFileTokenProvider returns a TokenProvider that reads the JWT from the file, the file could be updated by others before the token expires
+func NewFileTokenProvider(path string) TokenProvider {
+	return &jwtTokenProvider{
+		read: func() (string, error) {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("read token file %s error: %w", path, err)
+			}
+			return string(b), nil
+		},
+	}
+}
+
+// NewEnvTokenProvider returns a TokenProvider that reads the JWT from the environment variable
+func NewEnvTokenProvider(name string) TokenProvider {
+	return &jwtTokenProvider{
+		read: func() (string, error) {
+			token, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("env %s is not set", name)
+			}
+			return token, nil
+		},
+	}
+}
+
+// Token returns the cached token, or reads it again if it should be refreshed
+func (p *jwtTokenProvider) Token() (*AccessToken, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	if p.token != nil && now.Before(p.refreshAt) {
+		return p.token, nil
+	}
+
+	token, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	token = strings.TrimSpace(token)
+	expiry, err := jwtExpiry(token)
+	if err != nil {
+		return nil, err
+	}
+
+	p.token = &AccessToken{Token: token}
+	if expiry.IsZero() {
+		// no exp claim, read it every time
+		p.refreshAt = now
+	} else {
+		if !now.Before(expiry) {
+			return nil, fmt.Errorf("%w at %s", errTokenExpired, expiry)
+		}
+		p.refreshAt = now.Add(time.Duration(float64(expiry.Sub(now)) * tokenRefreshWindowFactor))
+	}
+	logger.V(1).Info("read oauthbearer token", "expiry", expiry, "refreshAt", p.refreshAt)
+	return p.token, nil
+}
+
+// jwtExpiry returns the exp claim of the JWT, zero time is returned if it has no exp claim. The signature is not verified, the broker does it
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("%w: expect 3 parts, got %d", errInvalidJWT, len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: decode payload error: %s", errInvalidJWT, err)
+	}
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("%w: unmarshal payload error: %s", errInvalidJWT, err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(*claims.Exp), 0), nil
+}
+
+// OAuthBearerSasl implements SASL/OAUTHBEARER (RFC 7628 and KIP-255)
+type OAuthBearerSasl struct {
+	token *AccessToken
+}
+
+// NewOAuthBearerSasl gets a token from the provider and creates a OAuthBearerSasl
+func NewOAuthBearerSasl(provider TokenProvider) (*OAuthBearerSasl, error) {
+	token, err := provider.Token()
+	if err != nil {
+		return nil, fmt.Errorf("get oauthbearer token error: %w", err)
+	}
+	return &OAuthBearerSasl{token: token}, nil
+}
+
+// Encode returns the initial client response: n,,\x01auth=Bearer <token>\x01[key=value\x01]*\x01
+func (s *OAuthBearerSasl) Encode() []byte {
+	var b strings.Builder
+	b.WriteString("n,,\x01auth=Bearer ")
+	b.WriteString(s.token.Token)
+	b.WriteString("\x01")
+
+	keys := make([]string, 0, len(s.token.Extensions))
+	for k := range s.token.Extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(k + "=" + s.token.Extensions[k] + "\x01")
+	}
+	b.WriteString("\x01")
+	return []byte(b.String())
+}
+
+// Challenge handles the server response. The server sends an error message if the token is rejected,
+// and the client must reply with a single \x01 so that the server could fail the authentication with an error code
+func (s *OAuthBearerSasl) Challenge(serverBytes []byte) (clientBytes []byte, done bool, err error) {
+	if len(serverBytes) == 0 {
+		return nil, true, nil
+	}
+	logger.Info("oauthbearer token is rejected", "message", string(serverBytes))
+	return []byte{0x01}, false, nil
+}
+
+// tokenProvider returns the TokenProvider of the sasl config. TokenProvider takes precedence over TokenFile and TokenEnv
+func (c *SaslConfig) tokenProvider() (TokenProvider, error) {
+	switch {
+	case c.TokenProvider != nil:
+		return c.TokenProvider, nil
+	case c.TokenFile != "":
+		return NewFileTokenProvider(c.TokenFile), nil
+	case c.TokenEnv != "":
+		return NewEnvTokenProvider(c.TokenEnv), nil
+	}
+	return nil, errNoTokenProvider
+}
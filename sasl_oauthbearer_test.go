@@ -0,0 +1,103 @@
+package healer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func makeJWT(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return header + "." + payload + ".signature"
+}
+
+func TestOAuthBearerSasl(t *testing.T) {
+	convey.Convey("initial client response", t, func() {
+		token := &AccessToken{Token: "abc", Extensions: map[string]string{"traceId": "1", "logicalCluster": "lc"}}
+		s, err := NewOAuthBearerSasl(tokenProviderFunc(func() (*AccessToken, error) { return token, nil }))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(string(s.Encode()), convey.ShouldEqual, "n,,\x01auth=Bearer abc\x01logicalCluster=lc\x01traceId=1\x01\x01")
+
+		_, done, err := s.Challenge(nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(done, convey.ShouldBeTrue)
+
+		clientBytes, done, err := s.Challenge([]byte(`{"status":"invalid_token"}`))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(done, convey.ShouldBeFalse)
+		convey.So(clientBytes, convey.ShouldResemble, []byte{0x01})
+	})
+
+	convey.Convey("token provider in config", t, func() {
+		_, err := NewSaslAuth(SaslConfig{Mechanism: "OAUTHBEARER"})
+		convey.So(errors.Is(err, errNoTokenProvider), convey.ShouldBeTrue)
+
+		t.Setenv("HEALER_TEST_TOKEN", makeJWT(`{"sub":"healer"}`))
+		saslAuth, err := NewSaslAuth(SaslConfig{Mechanism: "OAUTHBEARER", TokenEnv: "HEALER_TEST_TOKEN"})
+		convey.So(err, convey.ShouldBeNil)
+		_, ok := saslAuth.(SaslAuthChallenger)
+		convey.So(ok, convey.ShouldBeTrue)
+	})
+}
+
+type tokenProviderFunc func() (*AccessToken, error)
+
+func (f tokenProviderFunc) Token() (*AccessToken, error) {
+	return f()
+}
+
+func TestFileTokenProvider(t *testing.T) {
+	convey.Convey("token is cached and refreshed before expiry", t, func() {
+		path := filepath.Join(t.TempDir(), "token")
+		first := makeJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix()))
+		os.WriteFile(path, []byte(first+"\n"), 0600)
+
+		provider := NewFileTokenProvider(path)
+		token, err := provider.Token()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(token.Token, convey.ShouldEqual, first)
+
+		second := makeJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(2*time.Hour).Unix()))
+		os.WriteFile(path, []byte(second), 0600)
+		token, _ = provider.Token()
+		convey.So(token.Token, convey.ShouldEqual, first)
+
+		provider.(*jwtTokenProvider).refreshAt = time.Now()
+		token, _ = provider.Token()
+		convey.So(token.Token, convey.ShouldEqual, second)
+	})
+
+	convey.Convey("expired and invalid token", t, func() {
+		path := filepath.Join(t.TempDir(), "token")
+		os.WriteFile(path, []byte(makeJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Minute).Unix()))), 0600)
+		_, err := NewFileTokenProvider(path).Token()
+		convey.So(errors.Is(err, errTokenExpired), convey.ShouldBeTrue)
+
+		os.WriteFile(path, []byte("not-a-jwt"), 0600)
+		_, err = NewFileTokenProvider(path).Token()
+		convey.So(errors.Is(err, errInvalidJWT), convey.ShouldBeTrue)
+	})
+	convey.Convey("token provider of token file is created once for each broker", t, func() {
+		path := filepath.Join(t.TempDir(), "token")
+		os.WriteFile(path, []byte(makeJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix()))), 0600)
+
+		config := DefaultBrokerConfig()
+		config.Sasl = SaslConfig{Mechanism: "OAUTHBEARER", TokenFile: path}
+		broker := &Broker{config: config}
+
+		first, err := broker.saslConfig()
+		convey.So(err, convey.ShouldBeNil)
+		second, err := broker.saslConfig()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(first.TokenProvider, convey.ShouldNotBeNil)
+		convey.So(second.TokenProvider, convey.ShouldEqual, first.TokenProvider)
+		convey.So(config.Sasl.TokenProvider, convey.ShouldBeNil)
+	})
+}
@@ -0,0 +1,170 @@
+package healer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	errScramNonce             = errors.New("server nonce does not start with client nonce")
+	errScramServerSignature   = errors.New("server signature does not match")
+	errScramUnexpectedMessage = errors.New("unexpected scram message from server")
+)
+
+// ScramMechanism is the hash function of a SCRAM mechanism, SCRAM-SHA-256 or SCRAM-SHA-512
+type ScramMechanism struct {
+	Name    string
+	newHash func() hash.Hash
+}
+
+var (
+	ScramSHA256 = ScramMechanism{Name: "SCRAM-SHA-256", newHash: sha256.New}
+	ScramSHA512 = ScramMechanism{Name: "SCRAM-SHA-512", newHash: sha512.New}
+)
+
+func (m ScramMechanism) hmac(key, data []byte) []byte {
+	h := hmac.New(m.newHash, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func (m ScramMechanism) hash(data []byte) []byte {
+	h := m.newHash()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// SaltedPassword is Hi(password, salt, iterations) in RFC 5802
+func (m ScramMechanism) SaltedPassword(password string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(password), salt, iterations, m.newHash().Size(), m.newHash)
+}
+
+// ScramSasl implements SCRAM-SHA-256 and SCRAM-SHA-512 (RFC 5802 and RFC 7677).
+// Encode returns client-first-message, Challenge handles server-first-message and server-final-message
+type ScramSasl struct {
+	mechanism ScramMechanism
+	user      string
+	password  string
+
+	clientNonce             string
+	clientFirstBare         string
+	expectedServerSignature []byte
+	step                    int
+}
+
+// NewScramSasl creates a ScramSasl. mechanism is SCRAM-SHA-256 or SCRAM-SHA-512
+func NewScramSasl(mechanism ScramMechanism, user, password string) *ScramSasl {
+	nonce := make([]byte, 24)
+	rand.Read(nonce)
+	return &ScramSasl{
+		mechanism:   mechanism,
+		user:        user,
+		password:    password,
+		clientNonce: base64.RawStdEncoding.EncodeToString(nonce),
+	}
+}
+
+// escape user name as saslname in RFC 5802
+func scramEscape(s string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s)
+}
+
+// Encode returns client-first-message
+func (s *ScramSasl) Encode() []byte {
+	s.clientFirstBare = "n=" + scramEscape(s.user) + ",r=" + s.clientNonce
+	s.step = 1
+	return []byte("n,," + s.clientFirstBare)
+}
+
+// Challenge returns client-final-message for server-first-message, and verifies the server signature in server-final-message
+func (s *ScramSasl) Challenge(serverBytes []byte) (clientBytes []byte, done bool, err error) {
+	switch s.step {
+	case 1:
+		clientBytes, err = s.clientFinal(string(serverBytes))
+		s.step = 2
+		return clientBytes, false, err
+	case 2:
+		s.step = 3
+		return nil, true, s.verifyServerFinal(string(serverBytes))
+	}
+	return nil, true, errScramUnexpectedMessage
+}
+
+// parseScramAttributes parses message like "r=xxx,s=xxx,i=4096" to map
+func parseScramAttributes(message string) map[byte]string {
+	attributes := make(map[byte]string)
+	for _, field := range strings.Split(message, ",") {
+		if len(field) < 2 || field[1] != '=' {
+			continue
+		}
+		attributes[field[0]] = field[2:]
+	}
+	return attributes
+}
+
+func (s *ScramSasl) clientFinal(serverFirst string) ([]byte, error) {
+	attributes := parseScramAttributes(serverFirst)
+	if e, ok := attributes['e']; ok {
+		return nil, fmt.Errorf("scram error from server: %s", e)
+	}
+	if _, ok := attributes['m']; ok {
+		return nil, fmt.Errorf("%w: mandatory extension is not supported", errScramUnexpectedMessage)
+	}
+
+	nonce := attributes['r']
+	if !strings.HasPrefix(nonce, s.clientNonce) {
+		return nil, errScramNonce
+	}
+	salt, err := base64.StdEncoding.DecodeString(attributes['s'])
+	if err != nil {
+		return nil, fmt.Errorf("decode scram salt error: %w", err)
+	}
+	iterations, err := strconv.Atoi(attributes['i'])
+	if err != nil || iterations <= 0 {
+		return nil, fmt.Errorf("%w: invalid iteration count %q", errScramUnexpectedMessage, attributes['i'])
+	}
+
+	saltedPassword := s.mechanism.SaltedPassword(s.password, salt, iterations)
+	clientKey := s.mechanism.hmac(saltedPassword, []byte("Client Key"))
+	storedKey := s.mechanism.hash(clientKey)
+
+	// "biws" is base64 of gs2-header "n,,"
+	clientFinalWithoutProof := "c=biws,r=" + nonce
+	authMessage := s.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof
+
+	clientSignature := s.mechanism.hmac(storedKey, []byte(authMessage))
+	clientProof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		clientProof[i] = clientKey[i] ^ clientSignature[i]
+	}
+
+	serverKey := s.mechanism.hmac(saltedPassword, []byte("Server Key"))
+	s.expectedServerSignature = s.mechanism.hmac(serverKey, []byte(authMessage))
+
+	return []byte(clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(clientProof)), nil
+}
+
+func (s *ScramSasl) verifyServerFinal(serverFinal string) error {
+	attributes := parseScramAttributes(serverFinal)
+	if e, ok := attributes['e']; ok {
+		return fmt.Errorf("scram error from server: %s", e)
+	}
+	serverSignature, err := base64.StdEncoding.DecodeString(attributes['v'])
+	if err != nil {
+		return fmt.Errorf("decode scram server signature error: %w", err)
+	}
+	if !hmac.Equal(serverSignature, s.expectedServerSignature) {
+		return errScramServerSignature
+	}
+	return nil
+}
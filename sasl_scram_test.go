@@ -0,0 +1,64 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// test vector in RFC 7677
+func TestScramSHA256(t *testing.T) {
+	convey.Convey("SCRAM-SHA-256 exchange of RFC 7677", t, func() {
+		s := NewScramSasl(ScramSHA256, "user", "pencil")
+		s.clientNonce = "rOprNGfwEbeRWgbNEkqO"
+
+		convey.So(string(s.Encode()), convey.ShouldEqual, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO")
+
+		clientFinal, done, err := s.Challenge([]byte("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(done, convey.ShouldBeFalse)
+		convey.So(string(clientFinal), convey.ShouldEqual, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=")
+
+		_, done, err = s.Challenge([]byte("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(done, convey.ShouldBeTrue)
+	})
+
+	convey.Convey("wrong server signature and nonce", t, func() {
+		s := NewScramSasl(ScramSHA256, "user", "pencil")
+		s.clientNonce = "rOprNGfwEbeRWgbNEkqO"
+		s.Encode()
+		_, _, err := s.Challenge([]byte("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"))
+		convey.So(err, convey.ShouldBeNil)
+		_, _, err = s.Challenge([]byte("v=AAAATRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="))
+		convey.So(errors.Is(err, errScramServerSignature), convey.ShouldBeTrue)
+
+		s = NewScramSasl(ScramSHA512, "user", "pencil")
+		s.Encode()
+		_, _, err = s.Challenge([]byte("r=another-nonce,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"))
+		convey.So(errors.Is(err, errScramNonce), convey.ShouldBeTrue)
+	})
+}
+
+func TestNewSaslAuth(t *testing.T) {
+	convey.Convey("sasl mechanisms", t, func() {
+		for _, c := range []struct {
+			mechanism  string
+			challenger bool
+			err        bool
+		}{
+			{"PLAIN", false, false},
+			{"plain", false, false},
+			{"SCRAM-SHA-256", true, false},
+			{"SCRAM-SHA-512", true, false},
+			{"OAUTHBEARER", false, true},
+			{"GSSAPI", false, true},
+		} {
+			saslAuth, err := NewSaslAuth(SaslConfig{Mechanism: c.mechanism, User: "user", Password: "password"})
+			convey.So(err != nil, convey.ShouldEqual, c.err)
+			_, ok := saslAuth.(SaslAuthChallenger)
+			convey.So(ok, convey.ShouldEqual, c.challenger)
+		}
+	})
+}
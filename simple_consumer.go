@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
@@ -23,6 +24,11 @@ type SimpleConsumer struct {
 	coordinator  *Broker
 	partition    PartitionMetadataInfo
 
+	// readReplica is the preferred read replica returned by the leader, fetch requests are sent to it instead of the leader if it is not nil
+	readReplica *Broker
+	// readReplica is dropped after this time, and the leader is asked for a preferred read replica again
+	readReplicaExpireAt time.Time
+
 	ctx           context.Context
 	cancel        context.CancelFunc
 	stop          bool
@@ -32,6 +38,25 @@ type SimpleConsumer struct {
 	offset         int64
 	offsetCommited int64
 
+	// paused and seekTo are set by Pause, Resume and SeekTo* methods, and they are applied by consumeLoop before next fetch.
+	// wakeup notifies consumeLoop that they change
+	positionMutex sync.Mutex
+	paused        bool
+	seekTo        *seekTarget
+	wakeup        chan struct{}
+
+	// unacked are the offsets of the delivered messages which are not acknowledged yet, in ascending order. only used if ack.required is true
+	unacked  []int64
+	ackMutex sync.Mutex
+
+	// stopReached is set when the partition reaches stop.at.offset or stop.at.timestamp, and then consumeLoop exits
+	stopReached bool
+
+	// leader epoch of the last consumed message, -1 if unknown. it is used to detect log truncation after the leader changes
+	lastFetchedEpoch int32
+	// leader epoch of the partition when the offset is validated last time
+	validatedLeaderEpoch int32
+
 	messages chan *FullMessage
 
 	belongTO *GroupConsumer
@@ -50,6 +75,10 @@ func NewSimpleConsumerWithBrokers(topic string, partitionID int32, config Consum
 		topic:       topic,
 		partitionID: partitionID,
 		brokers:     brokers,
+
+		lastFetchedEpoch: -1,
+
+		wakeup: make(chan struct{}, 1),
 	}
 	c.ctx = context.Background()
 	c.ctx, c.cancel = context.WithCancel(c.ctx)
@@ -144,7 +173,12 @@ func (c *SimpleConsumer) getCoordinator() error {
 }
 
 var (
-	errNoLeader = errors.New("not leader found")
+	errNoLeader             = errors.New("not leader found")
+	errNoGroupID            = errors.New("group.id is not set, could not commit offset")
+	errPartitionNotConsumed = errors.New("partition is not consumed by this consumer")
+	errInvalidSeekOffset    = errors.New("seek offset must not be negative")
+
+	errListOffsetsByTimeNotSupported = errors.New("ListOffsets v1 is needed to get offset by timestamp")
 )
 
 // set c.leaderBroker
@@ -177,6 +211,61 @@ func (c *SimpleConsumer) getLeaderBroker() error {
 	return nil
 }
 
+// fetchBroker returns the broker to send fetch requests to, which is the preferred read replica if there is one, or else the leader
+func (c *SimpleConsumer) fetchBroker() *Broker {
+	if c.readReplica != nil {
+		return c.readReplica
+	}
+	return c.leaderBroker
+}
+
+// setReadReplica switches fetching to the preferred read replica returned by the leader.
+// it keeps fetching from the leader if the replica is the leader itself, is not in sync or could not be connected
+func (c *SimpleConsumer) setReadReplica(replicaID int32) {
+	if replicaID < 0 || replicaID == c.leaderBroker.nodeID || !c.isInSyncReplica(replicaID) {
+		return
+	}
+	broker, err := c.brokers.NewBroker(replicaID)
+	if err != nil {
+		logger.Error(err, "could not create broker for preferred read replica, fetch from leader", "topic", c.topic, "partitionID", c.partitionID, "replicaID", replicaID)
+		return
+	}
+	c.readReplica = broker
+	c.readReplicaExpireAt = time.Now().Add(time.Millisecond * time.Duration(c.config.MetadataMaxAgeMS))
+	logger.Info("fetch from preferred read replica", "topic", c.topic, "partitionID", c.partitionID, "replica", broker.GetAddress())
+}
+
+// resetReadReplica closes the preferred read replica and switches fetching back to the leader
+func (c *SimpleConsumer) resetReadReplica(reason string) {
+	if c.readReplica == nil {
+		return
+	}
+	logger.Info("fetch from leader instead of preferred read replica", "topic", c.topic, "partitionID", c.partitionID, "replica", c.readReplica.GetAddress(), "reason", reason)
+	c.readReplica.Close()
+	c.readReplica = nil
+}
+
+// checkReadReplica switches fetching back to the leader if the preferred read replica falls out of sync or expires
+func (c *SimpleConsumer) checkReadReplica() {
+	if c.readReplica == nil {
+		return
+	}
+	if !c.isInSyncReplica(c.readReplica.nodeID) {
+		c.resetReadReplica("replica is out of sync")
+	} else if time.Now().After(c.readReplicaExpireAt) {
+		c.resetReadReplica("replica expired")
+	}
+}
+
+func (c *SimpleConsumer) isInSyncReplica(nodeID int32) bool {
+	for _, id := range c.partition.Isr {
+		if id == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
 // init offset based on fromBeginning if not got commited offset
 func (c *SimpleConsumer) initOffset() {
 	logger.V(1).Info("init offset", "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
@@ -193,7 +282,16 @@ func (c *SimpleConsumer) initOffset() {
 	}
 
 	for !c.stop {
-		if c.offset, err = c.getOffset(c.fromBeginning); err != nil {
+		if c.config.StartFromTimestamp > 0 {
+			c.offset, err = c.getOffsetByTime(c.config.StartFromTimestamp)
+			if errors.Is(err, errListOffsetsByTimeNotSupported) {
+				logger.Error(err, "could not start from timestamp, use from.beginning instead", "topic", c.topic, "partitionID", c.partitionID)
+				c.offset, err = c.getOffset(c.fromBeginning)
+			}
+		} else {
+			c.offset, err = c.getOffset(c.fromBeginning)
+		}
+		if err != nil {
 			logger.Error(err, "could not get offset", "topic", c.topic, "partitionID", c.partitionID)
 			time.Sleep(time.Millisecond * time.Duration(c.config.RetryBackOffMS))
 		} else {
@@ -210,7 +308,28 @@ func (c *SimpleConsumer) getOffset(fromBeginning bool) (int64, error) {
 	} else {
 		time = -1
 	}
+	return c.listOffset(time)
+}
+
+// getOffsetByTime returns the earliest offset whose timestamp is greater than or equal to the given timestamp(ms),
+// or the latest offset if there is no such message
+func (c *SimpleConsumer) getOffsetByTime(timestamp int64) (int64, error) {
+	// ListOffsets v0 returns the offsets of log segments, not the offset of the timestamp
+	if c.leaderBroker.getHighestAvailableAPIVersion(API_OffsetRequest) < 1 {
+		return -1, errListOffsetsByTimeNotSupported
+	}
+	offset, err := c.listOffset(timestamp)
+	if err != nil {
+		return -1, err
+	}
+	if offset < 0 {
+		return c.getOffset(false)
+	}
+	return offset, nil
+}
 
+// listOffset requests the offset by ListOffsets, time is -1 for the latest offset, -2 for the earliest, or a timestamp(ms)
+func (c *SimpleConsumer) listOffset(time int64) (int64, error) {
 	offsetsResponse, err := c.leaderBroker.requestOffsets(c.config.ClientID, c.topic, []int32{c.partitionID}, time, 1)
 	if err != nil {
 		return -1, err
@@ -285,6 +404,9 @@ func (c *SimpleConsumer) Stop() {
 	if c.leaderBroker != nil {
 		c.leaderBroker.Close()
 	}
+	if c.readReplica != nil {
+		c.readReplica.Close()
+	}
 
 	// close(c.messages)
 
@@ -294,7 +416,7 @@ func (c *SimpleConsumer) Stop() {
 }
 
 // call this when simpleConsumer NOT belong to GroupConsumer, or call BelongTo.Commit()
-func (c *SimpleConsumer) commitOffset() bool {
+func (c *SimpleConsumer) commitOffset(offset int64) error {
 	var apiVersion uint16
 	if c.config.OffsetsStorage == 1 {
 		apiVersion = 2
@@ -306,34 +428,312 @@ func (c *SimpleConsumer) commitOffset() bool {
 	offsetComimtReq.SetMemberID("")
 	offsetComimtReq.SetGenerationID(-1)
 	offsetComimtReq.SetRetentionTime(-1)
-	offsetComimtReq.AddPartiton(c.topic, c.partitionID, c.offset, "")
+	offsetComimtReq.AddPartiton(c.topic, c.partitionID, offset, "")
 
-	_, err := c.coordinator.RequestAndGet(offsetComimtReq)
+	resp, err := c.coordinator.RequestAndGet(offsetComimtReq)
 	if err == nil {
-		logger.V(3).Info("offset committed", "GroupID", c.config.GroupID, "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
-		return true
+		err = resp.Error()
 	}
-	logger.Error(err, "commit offset failed", "GroupID", c.config.GroupID, "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
-	return false
+	if err == nil {
+		logger.V(3).Info("offset committed", "GroupID", c.config.GroupID, "topic", c.topic, "partitionID", c.partitionID, "offset", offset)
+		return nil
+	}
+	logger.Error(err, "commit offset failed", "GroupID", c.config.GroupID, "topic", c.topic, "partitionID", c.partitionID, "offset", offset)
+	return err
+}
+
+// commit commits offset by the GroupConsumer if simpleConsumer belongs to one, or else by its own coordinator
+func (c *SimpleConsumer) commit(offset int64) (err error) {
+	if c.belongTO != nil {
+		err = c.belongTO.commitOffset(c.topic, c.partitionID, offset)
+	} else if c.config.GroupID != "" {
+		err = c.commitOffset(offset)
+	} else {
+		return errNoGroupID
+	}
+	if err == nil {
+		c.offsetCommited = offset
+	}
+	return err
 }
 
 // CommitOffset commit offset to coordinator
 // if simpleConsumer belong to a GroupConsumer, it uses groupconsumer to commit
 // else if it has GroupId, it use its own coordinator to commit
 func (c *SimpleConsumer) CommitOffset() {
-	if c.offset == c.offsetCommited {
-		logger.V(3).Info("current offset does not change, skip committing", "offset", c.offset, "topic", c.topic, "partitionID", c.partitionID)
+	offset := c.committableOffset()
+	if offset == c.offsetCommited {
+		logger.V(3).Info("current offset does not change, skip committing", "offset", offset, "topic", c.topic, "partitionID", c.partitionID)
+		return
+	}
+	if c.belongTO != nil || c.config.GroupID != "" {
+		c.commit(offset)
+	}
+}
+
+// Commit commits offsets synchronously and returns when the commit is done or ctx is done.
+// offsets are the next offsets to consume keyed by topic and partition, they must belong to this consumer.
+// if offsets is nil, the offset next to the acknowledged contiguous messages is committed (the fetch position if ack.required is false)
+func (c *SimpleConsumer) Commit(ctx context.Context, offsets map[string]map[int32]int64) error {
+	return commitSimpleConsumers(ctx, []*SimpleConsumer{c}, offsets)
+}
+
+// seekTarget is the position to move to. offset is used if it is not negative,
+// or else the offset is looked up by ListOffsets with time, which is -1 for the latest, -2 for the earliest or a timestamp(ms)
+type seekTarget struct {
+	offset int64
+	time   int64
+}
+
+// Pause stops fetching until Resume is called, the connections are kept.
+// messages which are already sent to the channel are not recalled
+func (c *SimpleConsumer) Pause() {
+	c.positionMutex.Lock()
+	c.paused = true
+	c.positionMutex.Unlock()
+	c.notify()
+	logger.Info("pause consuming", "topic", c.topic, "partitionID", c.partitionID)
+}
+
+// Resume continues fetching from the position where it is paused
+func (c *SimpleConsumer) Resume() {
+	c.positionMutex.Lock()
+	c.paused = false
+	c.positionMutex.Unlock()
+	c.notify()
+	logger.Info("resume consuming", "topic", c.topic, "partitionID", c.partitionID)
+}
+
+// Paused returns true if the consumer is paused
+func (c *SimpleConsumer) Paused() bool {
+	c.positionMutex.Lock()
+	defer c.positionMutex.Unlock()
+	return c.paused
+}
+
+// SeekToOffset moves the position to offset, messages are fetched from it in the next fetch.
+// unacknowledged messages are dropped, the committed offset is based on the new position.
+// It is not named Seek because go vet expects Seek to be io.Seeker
+func (c *SimpleConsumer) SeekToOffset(offset int64) error {
+	if offset < 0 {
+		return fmt.Errorf("%w: %d", errInvalidSeekOffset, offset)
+	}
+	c.seek(&seekTarget{offset: offset})
+	return nil
+}
+
+// SeekToBeginning moves the position to the earliest offset
+func (c *SimpleConsumer) SeekToBeginning() {
+	c.seek(&seekTarget{offset: -1, time: -2})
+}
+
+// SeekToEnd moves the position to the latest offset
+func (c *SimpleConsumer) SeekToEnd() {
+	c.seek(&seekTarget{offset: -1, time: -1})
+}
+
+// SeekToTimestamp moves the position to the earliest offset whose timestamp(ms) is greater than or equal to the given timestamp,
+// or to the latest offset if there is no such message. It needs ListOffsets v1, that is Kafka 0.10.1+
+func (c *SimpleConsumer) SeekToTimestamp(timestamp int64) {
+	c.seek(&seekTarget{offset: -1, time: timestamp})
+}
+
+func (c *SimpleConsumer) seek(target *seekTarget) {
+	c.positionMutex.Lock()
+	c.seekTo = target
+	c.positionMutex.Unlock()
+	c.notify()
+	logger.Info("seek", "topic", c.topic, "partitionID", c.partitionID, "offset", target.offset, "time", target.time)
+}
+
+// notify wakes up consumeLoop if it is waiting for resume or delivering messages
+func (c *SimpleConsumer) notify() {
+	select {
+	case c.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// positionChanged returns true if the consumer is paused or there is a pending seek, so the fetched messages should not be delivered
+func (c *SimpleConsumer) positionChanged() bool {
+	c.positionMutex.Lock()
+	defer c.positionMutex.Unlock()
+	return c.paused || c.seekTo != nil
+}
+
+// waitIfPaused blocks until the consumer is resumed. it returns false if the consumer is stopped
+func (c *SimpleConsumer) waitIfPaused() bool {
+	for c.Paused() {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-c.wakeup:
+		}
+	}
+	return true
+}
+
+// applySeek moves the position to the pending seek target. the target is kept to retry if the offset could not be looked up
+func (c *SimpleConsumer) applySeek() error {
+	c.positionMutex.Lock()
+	target := c.seekTo
+	c.positionMutex.Unlock()
+	if target == nil {
+		return nil
+	}
+
+	offset := target.offset
+	if offset < 0 {
+		var err error
+		if target.time >= 0 {
+			offset, err = c.getOffsetByTime(target.time)
+		} else {
+			offset, err = c.listOffset(target.time)
+		}
+		if errors.Is(err, errListOffsetsByTimeNotSupported) {
+			c.positionMutex.Lock()
+			if c.seekTo == target {
+				c.seekTo = nil
+			}
+			c.positionMutex.Unlock()
+		}
+		if err != nil {
+			return fmt.Errorf("get offset of time %d error: %w", target.time, err)
+		}
+	}
+
+	c.positionMutex.Lock()
+	// a newer seek wins, it is applied in the next loop
+	if c.seekTo == target {
+		c.seekTo = nil
+	}
+	c.positionMutex.Unlock()
+
+	logger.Info("position moved", "topic", c.topic, "partitionID", c.partitionID, "from", c.offset, "to", offset)
+	c.offset = offset
+	c.lastFetchedEpoch = -1
+	c.ackMutex.Lock()
+	c.unacked = nil
+	c.ackMutex.Unlock()
+	return nil
+}
+
+// Ack acknowledges that the message is processed. It is a no-op if ack.required is false.
+// Messages could be acknowledged in any order, but the committed offset only moves past contiguous acknowledged messages
+func (c *SimpleConsumer) Ack(msg *FullMessage) {
+	if !c.config.AckRequired || msg == nil || msg.Message == nil {
+		return
+	}
+	c.ackMutex.Lock()
+	defer c.ackMutex.Unlock()
+
+	i := sort.Search(len(c.unacked), func(i int) bool { return c.unacked[i] >= msg.Message.Offset })
+	if i < len(c.unacked) && c.unacked[i] == msg.Message.Offset {
+		c.unacked = append(c.unacked[:i], c.unacked[i+1:]...)
+	}
+}
+
+// delivered records the offset of the message before it is sent to the application, so that it is not committed until acknowledged
+func (c *SimpleConsumer) delivered(offset int64) {
+	if !c.config.AckRequired {
+		return
+	}
+	c.ackMutex.Lock()
+	defer c.ackMutex.Unlock()
+
+	i := sort.Search(len(c.unacked), func(i int) bool { return c.unacked[i] >= offset })
+	if i < len(c.unacked) && c.unacked[i] == offset {
 		return
 	}
+	c.unacked = append(c.unacked, 0)
+	copy(c.unacked[i+1:], c.unacked[i:])
+	c.unacked[i] = offset
+}
+
+// committableOffset returns the offset to commit, which is the first unacknowledged offset, or the fetch position if all delivered messages are acknowledged
+func (c *SimpleConsumer) committableOffset() int64 {
 	offset := c.offset
-	if c.belongTO != nil {
-		if c.belongTO.commitOffset(c.topic, c.partitionID, offset) {
-			c.offsetCommited = offset
+	if !c.config.AckRequired {
+		return offset
+	}
+	c.ackMutex.Lock()
+	defer c.ackMutex.Unlock()
+
+	if len(c.unacked) > 0 && c.unacked[0] < offset {
+		return c.unacked[0]
+	}
+	return offset
+}
+
+func findSimpleConsumer(simpleConsumers []*SimpleConsumer, topic string, partitionID int32) *SimpleConsumer {
+	for _, s := range simpleConsumers {
+		if s.topic == topic && s.partitionID == partitionID {
+			return s
 		}
-	} else if c.config.GroupID != "" {
-		if c.commitOffset() {
-			c.offsetCommited = offset
+	}
+	return nil
+}
+
+// forEachPartition calls f with the simple consumers of the partitions.
+// it returns errPartitionNotConsumed and f is not called if any of the partitions is not consumed by the simple consumers
+func forEachPartition(simpleConsumers []*SimpleConsumer, partitions []*PartitionAssignment, f func(*SimpleConsumer)) error {
+	selected := make([]*SimpleConsumer, 0)
+	for _, p := range partitions {
+		for _, partitionID := range p.Partitions {
+			s := findSimpleConsumer(simpleConsumers, p.Topic, partitionID)
+			if s == nil {
+				return fmt.Errorf("%w: %s-%d", errPartitionNotConsumed, p.Topic, partitionID)
+			}
+			selected = append(selected, s)
+		}
+	}
+	for _, s := range selected {
+		f(s)
+	}
+	return nil
+}
+
+// commitSimpleConsumers commits offsets of the simple consumers synchronously, see SimpleConsumer.Commit
+func commitSimpleConsumers(ctx context.Context, simpleConsumers []*SimpleConsumer, offsets map[string]map[int32]int64) error {
+	type commit struct {
+		simpleConsumer *SimpleConsumer
+		offset         int64
+	}
+	commits := make([]commit, 0)
+	if offsets == nil {
+		for _, s := range simpleConsumers {
+			if offset := s.committableOffset(); offset >= 0 && offset != s.offsetCommited {
+				commits = append(commits, commit{s, offset})
+			}
 		}
+	} else {
+		for topic, partitions := range offsets {
+			for partitionID, offset := range partitions {
+				simpleConsumer := findSimpleConsumer(simpleConsumers, topic, partitionID)
+				if simpleConsumer == nil {
+					return fmt.Errorf("%w: %s-%d", errPartitionNotConsumed, topic, partitionID)
+				}
+				commits = append(commits, commit{simpleConsumer, offset})
+			}
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for _, t := range commits {
+			if e := t.simpleConsumer.commit(t.offset); e != nil && err == nil {
+				err = e
+			}
+		}
+		done <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
 	}
 }
 
@@ -375,21 +775,10 @@ func (c *SimpleConsumer) Consume(offset int64, messageChan chan *FullMessage) (<
 		c.initOffset()
 		logger.V(3).Info("offset after init offset", "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
 	}
+	c.validatedLeaderEpoch = c.partition.LeaderEpoch
 
 	if c.config.AutoCommit && c.config.GroupID != "" {
-		go func() {
-			ticker := time.NewTicker(time.Millisecond * time.Duration(c.config.AutoCommitIntervalMS))
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ticker.C:
-					c.CommitOffset()
-				case <-c.ctx.Done():
-					c.CommitOffset()
-					return
-				}
-			}
-		}()
+		go c.autoCommit()
 	}
 
 	go c.consumeLoop(messages)
@@ -397,6 +786,26 @@ func (c *SimpleConsumer) Consume(offset int64, messageChan chan *FullMessage) (<
 	return messages, nil
 }
 
+// autoCommit commits offset every auto.commit.interval.ms until the simple consumer stops
+func (c *SimpleConsumer) autoCommit() {
+	ticker := time.NewTicker(time.Millisecond * time.Duration(c.config.AutoCommitIntervalMS))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if c.ctx.Err() == nil {
+				c.CommitOffset()
+			}
+		case <-c.ctx.Done():
+			// GroupConsumer commits in partitionsRevoked after the rebalance listener returns, and not at all if the partitions are lost
+			if c.belongTO == nil {
+				c.CommitOffset()
+			}
+			return
+		}
+	}
+}
+
 func (c *SimpleConsumer) consumeLoop(messages chan *FullMessage) {
 	c.consumeLoopWg.Add(1)
 	defer c.consumeLoopWg.Done()
@@ -405,30 +814,78 @@ func (c *SimpleConsumer) consumeLoop(messages chan *FullMessage) {
 
 	wg := &sync.WaitGroup{}
 	for !c.stop {
+		if !c.waitIfPaused() {
+			return
+		}
+		if err := c.applySeek(); err != nil {
+			logger.Error(err, "failed to seek", "topic", c.topic, "partitionID", c.partitionID)
+			time.Sleep(time.Millisecond * time.Duration(c.config.RetryBackOffMS))
+			continue
+		}
+
+		if c.config.StopAtOffset > 0 && c.offset >= c.config.StopAtOffset {
+			c.stopReached = true
+		}
+		if c.stopReached {
+			logger.Info("stop position reached, stop consuming", "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
+			select {
+			case <-c.ctx.Done():
+			case messages <- &FullMessage{TopicName: c.topic, PartitionID: c.partitionID, Error: &StopPositionReachedError{Topic: c.topic, PartitionID: c.partitionID, Offset: c.offset}}:
+			}
+			return
+		}
+
+		if err := c.validatePosition(); err != nil {
+			var truncationErr *LogTruncationError
+			if errors.As(err, &truncationErr) {
+				logger.Error(err, "log truncation detected, stop consuming", "topic", c.topic, "partitionID", c.partitionID)
+				select {
+				case <-c.ctx.Done():
+				case messages <- &FullMessage{TopicName: c.topic, PartitionID: c.partitionID, Error: err}:
+				}
+				return
+			}
+			logger.Error(err, "failed to validate offset", "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
+			time.Sleep(time.Millisecond * time.Duration(c.config.RetryBackOffMS))
+			continue
+		}
+
+		c.checkReadReplica()
+
 		innerMessages := make(chan *FullMessage, 100)
 
 		// fetch
 		logger.V(5).Info("send fetch request", "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset)
 		r := NewFetchRequest(c.config.ClientID, c.config.FetchMaxWaitMS, c.config.FetchMinBytes)
+		r.ISOLationLevel = c.config.isolationLevel()
+		r.RackID = c.config.ClientRack
 		r.addPartition(c.topic, c.partitionID, c.offset, c.config.FetchMaxBytes, c.partition.LeaderEpoch)
 
-		reader, responseLength, err := c.leaderBroker.requestFetchStreamingly(r)
+		fetchBroker := c.fetchBroker()
+		reader, responseLength, err := fetchBroker.requestFetch(r)
 		if err != nil {
 			if err == context.Canceled {
 				return
 			}
 			logger.Error(err, "failed to fetch")
+			if c.readReplica != nil {
+				c.resetReadReplica(err.Error())
+				continue
+			}
 			time.Sleep(time.Millisecond * time.Duration(c.config.RetryBackOffMS))
 			continue
 		}
 
 		//decode
-		frsd := fetchResponseStreamDecoder{
-			ctx:         c.ctx,
-			buffers:     reader,
-			messages:    innerMessages,
-			totalLength: int(responseLength) + 4,
-			version:     c.leaderBroker.getHighestAvailableAPIVersion(API_FetchRequest),
+		frsd := &fetchResponseStreamDecoder{
+			ctx:            c.ctx,
+			buffers:        reader,
+			messages:       innerMessages,
+			totalLength:    int(responseLength) + 4,
+			version:        fetchBroker.getHighestAvailableAPIVersion(API_FetchRequest),
+			isolationLevel: r.ISOLationLevel,
+
+			preferredReadReplica: -1,
 		}
 
 		ctx, cancel := context.WithCancel(context.Background())
@@ -445,7 +902,7 @@ func (c *SimpleConsumer) consumeLoop(messages chan *FullMessage) {
 		// consume all messages from one fetch response
 		wg.Add(1)
 		go func() {
-			c.consumeMessages(innerMessages, messages)
+			c.consumeMessages(frsd, messages)
 			cancel()
 			wg.Done()
 		}()
@@ -453,20 +910,41 @@ func (c *SimpleConsumer) consumeLoop(messages chan *FullMessage) {
 	}
 }
 
-func (c *SimpleConsumer) consumeMessages(innerMessages chan *FullMessage, messages chan *FullMessage) (err error) {
+func (c *SimpleConsumer) consumeMessages(frsd *fetchResponseStreamDecoder, messages chan *FullMessage) (err error) {
 	var message *FullMessage
 	var ok bool
 	for {
+		// the rest messages are fetched again after resume or from the new position
+		if c.positionChanged() {
+			return nil
+		}
 		select {
 		case <-c.ctx.Done():
 			return c.ctx.Err()
-		case message, ok = <-innerMessages:
+		case <-c.wakeup:
+			// the position is checked at the top of the loop
+			continue
+		case message, ok = <-frsd.messages:
 		}
 		if !ok {
+			// all messages are consumed, skip the control batches and aborted batches at the end of the response
+			if frsd.skippedOffset > c.offset {
+				c.offset = frsd.skippedOffset
+			}
+			// preferred read replica is returned by the leader, the follower always returns -1
+			if c.readReplica == nil {
+				c.setReadReplica(frsd.preferredReadReplica)
+			}
 			return nil
 		}
 		if message.Error != nil {
 			logger.Error(message.Error, "message error", "topic", c.topic, "partitionID", c.partitionID)
+			// the replica may be lagging or not be a replica anymore, retry the fetch from the leader.
+			// errors of the leader are handled below
+			if c.readReplica != nil && message.Error != &maxBytesTooSmall {
+				c.resetReadReplica(message.Error.Error())
+				return
+			}
 			if os.IsTimeout(message.Error) || errors.Is(message.Error, io.EOF) || errors.Is(message.Error, syscall.EPIPE) {
 				c.leaderBroker.Close()
 			} else if message.Error == &maxBytesTooSmall {
@@ -478,6 +956,7 @@ func (c *SimpleConsumer) consumeMessages(innerMessages chan *FullMessage, messag
 				if err != nil {
 					logger.Error(err, "failed to get offset", "topic", c.topic, "partitionID", c.partitionID)
 				}
+				c.lastFetchedEpoch = -1
 			} else if message.Error == KafkaError(6) {
 				c.leaderBroker.Close()
 				c.leaderBroker = nil
@@ -489,17 +968,144 @@ func (c *SimpleConsumer) consumeMessages(innerMessages chan *FullMessage, messag
 						break
 					}
 				}
-			} else if message.Error == KafkaError(74) {
+				// get the new leader epoch, so that the offset is validated before next fetch
+				c.refreshPartiton()
+			} else if message.Error == KafkaError(74) || message.Error == KafkaError(76) {
+				// FENCED_LEADER_EPOCH or UNKNOWN_LEADER_EPOCH
 				c.refreshPartiton()
 			}
 			return
 		} else {
-			select {
-			case <-c.ctx.Done():
-				return c.ctx.Err()
-			case messages <- message:
-				c.offset = message.Message.Offset + 1
+			if c.reachStop(message.Message) {
+				c.stopReached = true
+				return nil
+			}
+			c.delivered(message.Message.Offset)
+			for {
+				select {
+				case <-c.ctx.Done():
+					return c.ctx.Err()
+				case <-c.wakeup:
+					if c.positionChanged() {
+						return nil
+					}
+					continue
+				case messages <- message:
+					c.offset = message.Message.Offset + 1
+					if message.Message.LeaderEpoch >= 0 {
+						c.lastFetchedEpoch = message.Message.LeaderEpoch
+					}
+				}
+				break
 			}
 		}
 	}
 }
+
+// reachStop returns true if the message is at or after stop.at.offset or stop.at.timestamp
+func (c *SimpleConsumer) reachStop(message *Message) bool {
+	if c.config.StopAtOffset > 0 && message.Offset >= c.config.StopAtOffset {
+		return true
+	}
+	if c.config.StopAtTimestamp > 0 && int64(message.Timestamp) >= c.config.StopAtTimestamp {
+		return true
+	}
+	return false
+}
+
+// StopPositionReachedError is sent in messages when the partition reaches stop.at.offset or stop.at.timestamp, and the partition is not fetched any more.
+// Offset is the offset of the first message which is not delivered
+type StopPositionReachedError struct {
+	Topic       string
+	PartitionID int32
+	Offset      int64
+}
+
+func (e *StopPositionReachedError) Error() string {
+	return fmt.Sprintf("%s-%d reaches the stop position at offset %d", e.Topic, e.PartitionID, e.Offset)
+}
+
+// LogTruncationError is sent in messages if the log is truncated and log.truncation.reset is none.
+// Offset is the offset to fetch, DivergentOffset is the end offset of the last consumed leader epoch on the new leader, -1 if unknown.
+// messages in [DivergentOffset, Offset) have been consumed but they are not in the log anymore
+type LogTruncationError struct {
+	Topic           string
+	PartitionID     int32
+	Offset          int64
+	DivergentOffset int64
+}
+
+func (e *LogTruncationError) Error() string {
+	return fmt.Sprintf("log of %s-%d is truncated, fetch offset %d, divergent offset %d", e.Topic, e.PartitionID, e.Offset, e.DivergentOffset)
+}
+
+// validatePosition detects log truncation by OffsetForLeaderEpoch after the leader epoch of the partition changes.
+// The log is truncated if the end offset of the last consumed leader epoch is smaller than the offset to fetch, which happens after unclean leader election.
+// The offset is reset according to log.truncation.reset, or LogTruncationError is returned if it is none
+func (c *SimpleConsumer) validatePosition() error {
+	leaderEpoch := c.partition.LeaderEpoch
+	if leaderEpoch == c.validatedLeaderEpoch {
+		return nil
+	}
+	// OffsetForLeaderEpoch v3 is the minimum version for consumers
+	if c.lastFetchedEpoch < 0 || leaderEpoch < 0 || c.leaderBroker.getHighestAvailableAPIVersion(API_OffsetForLeaderEpoch) < 3 {
+		c.validatedLeaderEpoch = leaderEpoch
+		return nil
+	}
+
+	r := NewOffsetForLeaderEpochRequest(c.config.ClientID)
+	r.AddPartition(c.topic, c.partitionID, leaderEpoch, c.lastFetchedEpoch)
+	resp, err := c.leaderBroker.RequestAndGet(r)
+	if err != nil {
+		if errors.Is(err, KafkaError(74)) || errors.Is(err, KafkaError(76)) || errors.Is(err, KafkaError(6)) {
+			c.refreshPartiton()
+		}
+		return fmt.Errorf("request offset for leader epoch %d error: %w", c.lastFetchedEpoch, err)
+	}
+	topics := resp.(OffsetForLeaderEpochResponse).Topics
+	if len(topics) != 1 || len(topics[0].Partitions) != 1 {
+		return fmt.Errorf("unexpected offset for leader epoch response of %s-%d", c.topic, c.partitionID)
+	}
+	endOffset := topics[0].Partitions[0]
+
+	if endOffset.EndOffset >= 0 && endOffset.LeaderEpoch >= 0 && endOffset.EndOffset >= c.offset {
+		c.validatedLeaderEpoch = leaderEpoch
+		return nil
+	}
+
+	// the divergent offset is unknown if the epoch is not found
+	divergentOffset := int64(-1)
+	if endOffset.EndOffset >= 0 && endOffset.LeaderEpoch >= 0 {
+		divergentOffset = endOffset.EndOffset
+	}
+	logger.Info("log truncation detected", "topic", c.topic, "partitionID", c.partitionID, "offset", c.offset, "lastFetchedEpoch", c.lastFetchedEpoch,
+		"endOffset", endOffset.EndOffset, "endOffsetLeaderEpoch", endOffset.LeaderEpoch, "policy", c.config.LogTruncationReset)
+
+	policy := c.config.LogTruncationReset
+	if policy == LogTruncationResetNone {
+		return &LogTruncationError{Topic: c.topic, PartitionID: c.partitionID, Offset: c.offset, DivergentOffset: divergentOffset}
+	}
+	if divergentOffset >= 0 && (policy == LogTruncationResetDivergence || policy == "") {
+		c.offset = divergentOffset
+		c.lastFetchedEpoch = endOffset.LeaderEpoch
+		c.validatedLeaderEpoch = leaderEpoch
+		return nil
+	}
+
+	// divergence point is unknown in divergence policy, from.beginning decides where to reset
+	fromBeginning := c.fromBeginning
+	switch policy {
+	case LogTruncationResetEarliest:
+		fromBeginning = true
+	case LogTruncationResetLatest:
+		fromBeginning = false
+	}
+	offset, err := c.getOffset(fromBeginning)
+	if err != nil {
+		return fmt.Errorf("reset offset after log truncation error: %w", err)
+	}
+	c.offset = offset
+	c.lastFetchedEpoch = -1
+	c.validatedLeaderEpoch = leaderEpoch
+	return nil
+}
@@ -51,7 +51,7 @@ func BenchmarkSimpleConsumer(b *testing.B) {
 		t := resp.Responses["test-topic"]
 		t[0].RecordBatch.Records = records
 		payload, _ := resp.Encode(version)
-		mockey.Mock((*Broker).requestFetchStreamingly).To(func(fetchRequest *FetchRequest) (io.Reader, uint32, error) {
+		mockey.Mock((*Broker).requestFetch).To(func(fetchRequest *FetchRequest) (io.Reader, uint32, error) {
 			reader := bytes.NewReader(payload)
 			return reader, uint32(len(payload)), nil
 		}).Build()
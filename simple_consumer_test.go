@@ -2,10 +2,12 @@ package healer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/bytedance/mockey"
 	"github.com/smartystreets/goconvey/convey"
@@ -136,9 +138,9 @@ func TestConsume(t *testing.T) {
 		mockey.Mock((*SimpleConsumer).getLeaderBroker).Return(nil).Build()
 		mockey.Mock((*SimpleConsumer).initOffset).Return().Build()
 		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(10).Build()
-		requestFetchStreamingly := mockey.Mock((*Broker).requestFetchStreamingly).
+		requestFetch := mockey.Mock((*Broker).requestFetch).
 			To(func(fetchRequest *FetchRequest) (r io.Reader, responseLength uint32, err error) {
-				t.Log("mock requestFetchStreamingly")
+				t.Log("mock requestFetch")
 				return nil, 0, nil
 			}).Build()
 
@@ -168,29 +170,29 @@ func TestConsume(t *testing.T) {
 		}).Build()
 
 		type testCase struct {
-			messageChanLength            int
-			maxMessage                   int
-			requestFetchStreaminglyCount []int
-			streamDecodeCount            []int
+			messageChanLength int
+			maxMessage        int
+			requestFetchCount []int
+			streamDecodeCount []int
 		}
 		for _, tc := range []testCase{
 			{
-				messageChanLength:            0,
-				maxMessage:                   1,
-				requestFetchStreaminglyCount: []int{1, 1},
-				streamDecodeCount:            []int{1, 1},
+				messageChanLength: 0,
+				maxMessage:        1,
+				requestFetchCount: []int{1, 1},
+				streamDecodeCount: []int{1, 1},
 			},
 			{
-				messageChanLength:            0,
-				maxMessage:                   2,
-				requestFetchStreaminglyCount: []int{2, 2}, // incremental, add 1 for the first time
-				streamDecodeCount:            []int{2, 2}, // incremental, add 1 for the first time
+				messageChanLength: 0,
+				maxMessage:        2,
+				requestFetchCount: []int{2, 2}, // incremental, add 1 for the first time
+				streamDecodeCount: []int{2, 2}, // incremental, add 1 for the first time
 			},
 			{
-				messageChanLength:            0,
-				maxMessage:                   5,
-				requestFetchStreaminglyCount: []int{3, 4},
-				streamDecodeCount:            []int{3, 4},
+				messageChanLength: 0,
+				maxMessage:        5,
+				requestFetchCount: []int{3, 4},
+				streamDecodeCount: []int{3, 4},
 			},
 		} {
 			t.Logf("test case: %+v", tc)
@@ -213,7 +215,7 @@ func TestConsume(t *testing.T) {
 			t.Log("stopped")
 
 			convey.So(count, convey.ShouldEqual, tc.maxMessage)
-			convey.So(requestFetchStreamingly.Times(), convey.ShouldBeBetween, tc.requestFetchStreaminglyCount[0]-1, tc.requestFetchStreaminglyCount[1]+1)
+			convey.So(requestFetch.Times(), convey.ShouldBeBetween, tc.requestFetchCount[0]-1, tc.requestFetchCount[1]+1)
 			convey.So(streamDecode.Times(), convey.ShouldBeBetween, tc.streamDecodeCount[0]-1, tc.streamDecodeCount[1]+1)
 		}
 	})
@@ -242,9 +244,9 @@ func TestOffsetOutofRangeConsume(t *testing.T) {
 		mockey.Mock((*SimpleConsumer).initOffset).Return().Build()
 		getOffset := mockey.Mock((*SimpleConsumer).getOffset).Return(0, nil).Build()
 		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(10).Build()
-		requestFetchStreamingly := mockey.Mock((*Broker).requestFetchStreamingly).
+		requestFetch := mockey.Mock((*Broker).requestFetch).
 			To(func(fetchRequest *FetchRequest) (r io.Reader, responseLength uint32, err error) {
-				t.Log("mock requestFetchStreamingly")
+				t.Log("mock requestFetch")
 				return nil, 0, nil
 			}).Build()
 
@@ -311,26 +313,26 @@ func TestOffsetOutofRangeConsume(t *testing.T) {
 		}).Build()
 
 		type testCase struct {
-			messageChanLength            int
-			maxMessage                   int
-			requestFetchStreaminglyCount []int
-			streamDecodeCount            []int
-			getOffsetCount               []int
+			messageChanLength int
+			maxMessage        int
+			requestFetchCount []int
+			streamDecodeCount []int
+			getOffsetCount    []int
 		}
 		for _, tc := range []testCase{
 			{
-				messageChanLength:            0,
-				maxMessage:                   2,
-				requestFetchStreaminglyCount: []int{1, 1},
-				streamDecodeCount:            []int{1, 1},
-				getOffsetCount:               []int{0, 1},
+				messageChanLength: 0,
+				maxMessage:        2,
+				requestFetchCount: []int{1, 1},
+				streamDecodeCount: []int{1, 1},
+				getOffsetCount:    []int{0, 1},
 			},
 			{
-				messageChanLength:            0,
-				maxMessage:                   5,
-				requestFetchStreaminglyCount: []int{4, 4},
-				streamDecodeCount:            []int{4, 4}, // incremental, add count from the first time
-				getOffsetCount:               []int{2, 3}, // incremental, add count from the first time
+				messageChanLength: 0,
+				maxMessage:        5,
+				requestFetchCount: []int{4, 4},
+				streamDecodeCount: []int{4, 4}, // incremental, add count from the first time
+				getOffsetCount:    []int{2, 3}, // incremental, add count from the first time
 			},
 		} {
 			t.Logf("test case: %+v", tc)
@@ -353,7 +355,7 @@ func TestOffsetOutofRangeConsume(t *testing.T) {
 			t.Log("stopped")
 
 			convey.So(count, convey.ShouldEqual, tc.maxMessage)
-			convey.So(requestFetchStreamingly.Times(), convey.ShouldBeBetween, tc.requestFetchStreaminglyCount[0]-1, tc.requestFetchStreaminglyCount[1]+1)
+			convey.So(requestFetch.Times(), convey.ShouldBeBetween, tc.requestFetchCount[0]-1, tc.requestFetchCount[1]+1)
 			convey.So(streamDecode.Times(), convey.ShouldBeBetween, tc.streamDecodeCount[0]-1, tc.streamDecodeCount[1]+1)
 			convey.So(getOffset.Times(), convey.ShouldBeBetween, tc.getOffsetCount[0]-1, tc.getOffsetCount[1]+1)
 		}
@@ -385,10 +387,10 @@ func TestConsumeEOFInRequest(t *testing.T) {
 		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(10).Build()
 
 		failCount := 0
-		requestFetchStreamingly := mockey.Mock((*Broker).requestFetchStreamingly).
+		requestFetch := mockey.Mock((*Broker).requestFetch).
 			To(func(fetchRequest *FetchRequest) (r io.Reader, responseLength uint32, err error) {
 				if failCount == 0 {
-					t.Log("mock requestFetchStreamingly")
+					t.Log("mock requestFetch")
 					failCount = 1
 					return nil, 0, io.EOF
 				} else {
@@ -425,17 +427,17 @@ func TestConsumeEOFInRequest(t *testing.T) {
 		}).Build()
 
 		type testCase struct {
-			messageChanLength            int
-			maxMessage                   int
-			requestFetchStreaminglyCount []int
-			streamDecodeCount            []int
+			messageChanLength int
+			maxMessage        int
+			requestFetchCount []int
+			streamDecodeCount []int
 		}
 		for _, tc := range []testCase{
 			{
-				messageChanLength:            0,
-				maxMessage:                   1,
-				requestFetchStreaminglyCount: []int{2, 2},
-				streamDecodeCount:            []int{1, 1},
+				messageChanLength: 0,
+				maxMessage:        1,
+				requestFetchCount: []int{2, 2},
+				streamDecodeCount: []int{1, 1},
 			},
 		} {
 			t.Logf("test case: %+v", tc)
@@ -458,7 +460,7 @@ func TestConsumeEOFInRequest(t *testing.T) {
 			t.Log("stopped")
 
 			convey.So(count, convey.ShouldEqual, tc.maxMessage)
-			convey.So(requestFetchStreamingly.Times(), convey.ShouldBeBetween, tc.requestFetchStreaminglyCount[0]-1, tc.requestFetchStreaminglyCount[1]+1)
+			convey.So(requestFetch.Times(), convey.ShouldBeBetween, tc.requestFetchCount[0]-1, tc.requestFetchCount[1]+1)
 			convey.So(streamDecode.Times(), convey.ShouldBeBetween, tc.streamDecodeCount[0]-1, tc.streamDecodeCount[1]+1)
 		}
 	})
@@ -498,7 +500,7 @@ func TestConsumeEOFInResponse(t *testing.T) {
 			return nil
 		}).Build()
 
-		mockey.Mock((*Broker).requestStreamingly).Return(&MockConn{}, 0, nil).Build() // ensureOpen before requestStreamingly
+		mockey.Mock((*Broker).requestBuffered).Return(&MockConn{}, 0, nil).Build() // ensureOpen before requestBuffered
 
 		hasFailed := false
 		mockey.Mock((*fetchResponseStreamDecoder).streamDecode).To(func(decoder *fetchResponseStreamDecoder, ctx context.Context, startOffset int64) error {
@@ -568,17 +570,17 @@ func TestConsumeEOFInResponse(t *testing.T) {
 		}).Build()
 
 		type testCase struct {
-			messageChanLength            int
-			maxMessage                   int
-			requestFetchStreaminglyCount []int
-			streamDecodeCount            []int
+			messageChanLength int
+			maxMessage        int
+			requestFetchCount []int
+			streamDecodeCount []int
 		}
 		for _, tc := range []testCase{
 			{
-				messageChanLength:            0,
-				maxMessage:                   10,
-				requestFetchStreaminglyCount: []int{2, 2},
-				streamDecodeCount:            []int{1, 1},
+				messageChanLength: 0,
+				maxMessage:        10,
+				requestFetchCount: []int{2, 2},
+				streamDecodeCount: []int{1, 1},
 			},
 		} {
 			t.Logf("test case: %+v", tc)
@@ -608,3 +610,396 @@ func TestConsumeEOFInResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestValidatePosition(t *testing.T) {
+	mockey.PatchConvey("TestValidatePosition", t, func() {
+		type testCase struct {
+			policy           string
+			endOffset        OffsetForLeaderEpochPartitionResult
+			expectedOffset   int64
+			expectedEpoch    int32
+			expectTruncation bool
+		}
+		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(3).Build()
+		mockey.Mock((*SimpleConsumer).getOffset).Return(int64(10), nil).Build()
+
+		var (
+			request   *OffsetForLeaderEpochRequest
+			endOffset OffsetForLeaderEpochPartitionResult
+		)
+		mockey.Mock((*Broker).RequestAndGet).To(func(broker *Broker, r Request) (Response, error) {
+			request = r.(*OffsetForLeaderEpochRequest)
+			return OffsetForLeaderEpochResponse{
+				Topics: []OffsetForLeaderEpochTopicResult{{Topic: "testTopic", Partitions: []OffsetForLeaderEpochPartitionResult{endOffset}}},
+			}, nil
+		}).Build()
+
+		for _, tc := range []testCase{
+			{LogTruncationResetDivergence, OffsetForLeaderEpochPartitionResult{LeaderEpoch: 3, EndOffset: 200}, 100, 3, false},
+			{LogTruncationResetDivergence, OffsetForLeaderEpochPartitionResult{LeaderEpoch: 2, EndOffset: 80}, 80, 2, false},
+			{LogTruncationResetDivergence, OffsetForLeaderEpochPartitionResult{LeaderEpoch: -1, EndOffset: -1}, 10, -1, false},
+			{LogTruncationResetLatest, OffsetForLeaderEpochPartitionResult{LeaderEpoch: 2, EndOffset: 80}, 10, -1, false},
+			{LogTruncationResetNone, OffsetForLeaderEpochPartitionResult{LeaderEpoch: 2, EndOffset: 80}, 100, 3, true},
+		} {
+			t.Logf("test case: %+v", tc)
+			endOffset = tc.endOffset
+
+			c := &SimpleConsumer{
+				topic:                "testTopic",
+				partitionID:          1,
+				config:               ConsumerConfig{LogTruncationReset: tc.policy},
+				leaderBroker:         &Broker{},
+				partition:            PartitionMetadataInfo{PartitionID: 1, LeaderEpoch: 5},
+				offset:               100,
+				lastFetchedEpoch:     3,
+				validatedLeaderEpoch: 4,
+			}
+			err := c.validatePosition()
+			convey.So(request.Topics[0].Partitions[0], convey.ShouldResemble, OffsetForLeaderEpochPartition{Partition: 1, CurrentLeaderEpoch: 5, LeaderEpoch: 3})
+
+			var truncationErr *LogTruncationError
+			convey.So(errors.As(err, &truncationErr), convey.ShouldEqual, tc.expectTruncation)
+			convey.So(c.offset, convey.ShouldEqual, tc.expectedOffset)
+			convey.So(c.lastFetchedEpoch, convey.ShouldEqual, tc.expectedEpoch)
+			if !tc.expectTruncation {
+				convey.So(c.validatedLeaderEpoch, convey.ShouldEqual, 5)
+				convey.So(c.validatePosition(), convey.ShouldBeNil)
+			}
+		}
+	})
+}
+
+func TestReadReplica(t *testing.T) {
+	mockey.PatchConvey("TestReadReplica", t, func() {
+		mockey.Mock((*Brokers).NewBroker).To(func(brokers *Brokers, nodeID int32) (*Broker, error) {
+			return &Broker{nodeID: nodeID}, nil
+		}).Build()
+		mockey.Mock((*Broker).Close).Return().Build()
+
+		leader := &Broker{nodeID: 1}
+		c := &SimpleConsumer{
+			topic:        "testTopic",
+			partitionID:  1,
+			config:       ConsumerConfig{MetadataMaxAgeMS: 300000},
+			ctx:          context.Background(),
+			leaderBroker: leader,
+			partition:    PartitionMetadataInfo{PartitionID: 1, Leader: 1, Replicas: []int32{1, 2, 3}, Isr: []int32{1, 2}},
+			offset:       100,
+		}
+
+		// -1, the leader itself and out of sync replica are ignored
+		for _, replicaID := range []int32{-1, 1, 3} {
+			c.setReadReplica(replicaID)
+			convey.So(c.readReplica, convey.ShouldBeNil)
+			convey.So(c.fetchBroker(), convey.ShouldEqual, leader)
+		}
+
+		c.setReadReplica(2)
+		convey.So(c.fetchBroker().nodeID, convey.ShouldEqual, 2)
+		c.checkReadReplica()
+		convey.So(c.fetchBroker().nodeID, convey.ShouldEqual, 2)
+
+		// replica falls out of sync
+		c.partition.Isr = []int32{1}
+		c.checkReadReplica()
+		convey.So(c.fetchBroker(), convey.ShouldEqual, leader)
+
+		// replica expires
+		c.partition.Isr = []int32{1, 2}
+		c.setReadReplica(2)
+		c.readReplicaExpireAt = time.Now().Add(-time.Second)
+		c.checkReadReplica()
+		convey.So(c.fetchBroker(), convey.ShouldEqual, leader)
+
+		// error from replica, fetch from leader again without resetting the offset
+		c.setReadReplica(2)
+		messages := make(chan *FullMessage, 1)
+		messages <- &FullMessage{TopicName: "testTopic", PartitionID: 1, Error: KafkaError(1)}
+		close(messages)
+		c.consumeMessages(&fetchResponseStreamDecoder{messages: messages}, make(chan *FullMessage, 1))
+		convey.So(c.fetchBroker(), convey.ShouldEqual, leader)
+		convey.So(c.offset, convey.ShouldEqual, 100)
+
+		// preferred read replica in the leader's response
+		messages = make(chan *FullMessage)
+		close(messages)
+		c.consumeMessages(&fetchResponseStreamDecoder{messages: messages, preferredReadReplica: 2}, make(chan *FullMessage, 1))
+		convey.So(c.fetchBroker().nodeID, convey.ShouldEqual, 2)
+	})
+}
+
+func TestAck(t *testing.T) {
+	mockey.PatchConvey("only acknowledged contiguous offsets are committed", t, func() {
+		committed := make(map[int32]int64)
+		block := make(chan struct{})
+		mockey.Mock((*GroupConsumer).commitOffset).To(func(g *GroupConsumer, topic string, partitionID int32, offset int64) error {
+			if partitionID == 2 {
+				<-block
+			}
+			committed[partitionID] = offset
+			return nil
+		}).Build()
+
+		g := &GroupConsumer{}
+		c := &SimpleConsumer{
+			topic:          "testTopic",
+			partitionID:    1,
+			config:         ConsumerConfig{AckRequired: true},
+			ctx:            context.Background(),
+			belongTO:       g,
+			offset:         10,
+			offsetCommited: -1,
+		}
+
+		// deliver 10, 11, 12
+		messages := make(chan *FullMessage, 3)
+		for offset := int64(10); offset < 13; offset++ {
+			messages <- &FullMessage{TopicName: "testTopic", PartitionID: 1, Message: &Message{Offset: offset, LeaderEpoch: -1}}
+		}
+		close(messages)
+		out := make(chan *FullMessage, 3)
+		c.consumeMessages(&fetchResponseStreamDecoder{messages: messages, preferredReadReplica: -1}, out)
+		convey.So(c.offset, convey.ShouldEqual, 13)
+		convey.So(c.committableOffset(), convey.ShouldEqual, 10)
+
+		msgs := make([]*FullMessage, 0)
+		for i := 0; i < 3; i++ {
+			msgs = append(msgs, <-out)
+		}
+		c.Ack(msgs[1])
+		convey.So(c.committableOffset(), convey.ShouldEqual, 10)
+		c.CommitOffset()
+		convey.So(committed[1], convey.ShouldEqual, 10)
+
+		c.Ack(msgs[0])
+		convey.So(c.committableOffset(), convey.ShouldEqual, 12)
+		convey.So(c.Commit(context.Background(), nil), convey.ShouldBeNil)
+		convey.So(committed[1], convey.ShouldEqual, 12)
+
+		c.Ack(msgs[2])
+		c.Ack(msgs[2])
+		convey.So(c.committableOffset(), convey.ShouldEqual, 13)
+
+		// explicit offsets
+		convey.So(c.Commit(context.Background(), map[string]map[int32]int64{"testTopic": {1: 11}}), convey.ShouldBeNil)
+		convey.So(committed[1], convey.ShouldEqual, 11)
+		err := c.Commit(context.Background(), map[string]map[int32]int64{"testTopic": {3: 11}})
+		convey.So(errors.Is(err, errPartitionNotConsumed), convey.ShouldBeTrue)
+
+		// fetch position is committed if ack is not required
+		c.config.AckRequired = false
+		c.delivered(13)
+		convey.So(c.committableOffset(), convey.ShouldEqual, 13)
+
+		// commit returns when ctx is done
+		other := &SimpleConsumer{topic: "testTopic", partitionID: 2, belongTO: g, offset: 5}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err = (&Consumer{simpleConsumers: []*SimpleConsumer{c, other}}).Commit(ctx, nil)
+		convey.So(errors.Is(err, context.DeadlineExceeded), convey.ShouldBeTrue)
+		close(block)
+	})
+}
+
+func TestPauseAndSeek(t *testing.T) {
+	mockey.PatchConvey("seek is applied before next fetch", t, func() {
+		mockey.Mock((*Broker).getHighestAvailableAPIVersion).Return(1).Build()
+		mockey.Mock((*SimpleConsumer).listOffset).To(func(c *SimpleConsumer, time int64) (int64, error) {
+			switch time {
+			case -2:
+				return 0, nil
+			case -1:
+				return 100, nil
+			case 1000:
+				return 50, nil
+			}
+			return -1, nil
+		}).Build()
+
+		c := &SimpleConsumer{
+			topic:            "testTopic",
+			partitionID:      1,
+			config:           ConsumerConfig{AckRequired: true},
+			ctx:              context.Background(),
+			leaderBroker:     &Broker{},
+			wakeup:           make(chan struct{}, 1),
+			offset:           10,
+			lastFetchedEpoch: 3,
+			unacked:          []int64{8, 9},
+		}
+
+		convey.So(errors.Is(c.SeekToOffset(-1), errInvalidSeekOffset), convey.ShouldBeTrue)
+		convey.So(c.applySeek(), convey.ShouldBeNil)
+		convey.So(c.offset, convey.ShouldEqual, 10)
+
+		convey.So(c.SeekToOffset(30), convey.ShouldBeNil)
+		convey.So(c.applySeek(), convey.ShouldBeNil)
+		convey.So(c.offset, convey.ShouldEqual, 30)
+		convey.So(c.lastFetchedEpoch, convey.ShouldEqual, -1)
+		convey.So(c.unacked, convey.ShouldBeNil)
+		convey.So(c.seekTo, convey.ShouldBeNil)
+
+		for _, tc := range []struct {
+			seek     func()
+			expected int64
+		}{
+			{c.SeekToBeginning, 0},
+			{c.SeekToEnd, 100},
+			{func() { c.SeekToTimestamp(1000) }, 50},
+			{func() { c.SeekToTimestamp(9999) }, 100},
+		} {
+			tc.seek()
+			convey.So(c.applySeek(), convey.ShouldBeNil)
+			convey.So(c.offset, convey.ShouldEqual, tc.expected)
+		}
+	})
+
+	mockey.PatchConvey("messages are not delivered after pause or seek", t, func() {
+		c := &SimpleConsumer{
+			topic:       "testTopic",
+			partitionID: 1,
+			ctx:         context.Background(),
+			wakeup:      make(chan struct{}, 1),
+			offset:      10,
+		}
+		newDecoder := func() *fetchResponseStreamDecoder {
+			messages := make(chan *FullMessage, 3)
+			for offset := int64(10); offset < 13; offset++ {
+				messages <- &FullMessage{TopicName: "testTopic", PartitionID: 1, Message: &Message{Offset: offset, LeaderEpoch: -1}}
+			}
+			close(messages)
+			return &fetchResponseStreamDecoder{messages: messages, preferredReadReplica: -1}
+		}
+
+		c.Pause()
+		convey.So(c.consumeMessages(newDecoder(), make(chan *FullMessage, 3)), convey.ShouldBeNil)
+		convey.So(c.offset, convey.ShouldEqual, 10)
+
+		resumed := make(chan bool)
+		go func() {
+			resumed <- c.waitIfPaused()
+		}()
+		time.Sleep(10 * time.Millisecond)
+		c.Resume()
+		convey.So(<-resumed, convey.ShouldBeTrue)
+
+		// the application does not read messages, seek interrupts the blocked delivery
+		done := make(chan error)
+		go func() {
+			done <- c.consumeMessages(newDecoder(), make(chan *FullMessage))
+		}()
+		time.Sleep(10 * time.Millisecond)
+		convey.So(c.SeekToOffset(5), convey.ShouldBeNil)
+		convey.So(<-done, convey.ShouldBeNil)
+		convey.So(c.offset, convey.ShouldEqual, 10)
+	})
+
+	mockey.PatchConvey("all partitions must be consumed", t, func() {
+		consumer := &Consumer{simpleConsumers: []*SimpleConsumer{
+			{topic: "testTopic", partitionID: 0, wakeup: make(chan struct{}, 1)},
+			{topic: "testTopic", partitionID: 1, wakeup: make(chan struct{}, 1)},
+		}}
+		err := consumer.Pause([]*PartitionAssignment{{Topic: "testTopic", Partitions: []int32{0, 2}}})
+		convey.So(errors.Is(err, errPartitionNotConsumed), convey.ShouldBeTrue)
+		convey.So(consumer.simpleConsumers[0].Paused(), convey.ShouldBeFalse)
+
+		convey.So(consumer.Pause([]*PartitionAssignment{{Topic: "testTopic", Partitions: []int32{0}}}), convey.ShouldBeNil)
+		convey.So(consumer.simpleConsumers[0].Paused(), convey.ShouldBeTrue)
+		convey.So(consumer.simpleConsumers[1].Paused(), convey.ShouldBeFalse)
+
+		convey.So(consumer.SeekToOffset("testTopic", 1, 20), convey.ShouldBeNil)
+		convey.So(consumer.simpleConsumers[1].seekTo.offset, convey.ShouldEqual, 20)
+		convey.So(errors.Is(consumer.SeekToOffset("testTopic", 3, 20), errPartitionNotConsumed), convey.ShouldBeTrue)
+	})
+}
+
+func TestStartAndStopPosition(t *testing.T) {
+	mockey.PatchConvey("start from timestamp if there is no committed offset", t, func() {
+		version := uint16(1)
+		mockey.Mock((*Broker).getHighestAvailableAPIVersion).To(func(broker *Broker, apiKey uint16) uint16 {
+			return version
+		}).Build()
+		mockey.Mock((*SimpleConsumer).listOffset).To(func(c *SimpleConsumer, time int64) (int64, error) {
+			switch time {
+			case -2:
+				return 0, nil
+			case -1:
+				return 100, nil
+			}
+			return 50, nil
+		}).Build()
+
+		c := &SimpleConsumer{
+			topic:        "testTopic",
+			partitionID:  1,
+			config:       ConsumerConfig{StartFromTimestamp: 1791936000000},
+			leaderBroker: &Broker{},
+			offset:       -1,
+		}
+		c.initOffset()
+		convey.So(c.offset, convey.ShouldEqual, 50)
+
+		// committed offset is used
+		c.offset = 30
+		c.initOffset()
+		convey.So(c.offset, convey.ShouldEqual, 30)
+
+		// ListOffsets v0 could not get offset by timestamp, from.beginning is used
+		version = 0
+		c.offset = -2
+		c.initOffset()
+		convey.So(c.offset, convey.ShouldEqual, 0)
+	})
+
+	mockey.PatchConvey("stop at offset or timestamp", t, func() {
+		for _, config := range []ConsumerConfig{{StopAtOffset: 12}, {StopAtTimestamp: 1791936000012}} {
+			c := &SimpleConsumer{
+				topic:       "testTopic",
+				partitionID: 1,
+				config:      config,
+				ctx:         context.Background(),
+				offset:      10,
+			}
+			messages := make(chan *FullMessage, 3)
+			for offset := int64(10); offset < 13; offset++ {
+				messages <- &FullMessage{TopicName: "testTopic", PartitionID: 1, Message: &Message{Offset: offset, Timestamp: uint64(1791936000000 + offset), LeaderEpoch: -1}}
+			}
+			close(messages)
+			out := make(chan *FullMessage, 3)
+			convey.So(c.consumeMessages(&fetchResponseStreamDecoder{messages: messages, preferredReadReplica: -1}, out), convey.ShouldBeNil)
+			convey.So(len(out), convey.ShouldEqual, 2)
+			convey.So(c.offset, convey.ShouldEqual, 12)
+			convey.So(c.stopReached, convey.ShouldBeTrue)
+
+			// consume loop sends StopPositionReachedError and exits
+			c.consumeLoop(out)
+			<-out
+			<-out
+			var stopErr *StopPositionReachedError
+			convey.So(errors.As((<-out).Error, &stopErr), convey.ShouldBeTrue)
+			convey.So(stopErr.Offset, convey.ShouldEqual, 12)
+		}
+	})
+}
+
+func TestAutoCommitOnStop(t *testing.T) {
+	mockey.PatchConvey("offset is committed on stop only if the simple consumer does not belong to a group consumer", t, func() {
+		commits := 0
+		mockey.Mock((*SimpleConsumer).CommitOffset).To(func(s *SimpleConsumer) { commits++ }).Build()
+
+		for _, c := range []struct {
+			belongTO *GroupConsumer
+			commits  int
+		}{
+			{nil, 1},
+			{&GroupConsumer{}, 0},
+		} {
+			commits = 0
+			ctx, cancel := context.WithCancel(context.Background())
+			s := &SimpleConsumer{ctx: ctx, config: ConsumerConfig{AutoCommitIntervalMS: 3600000}, belongTO: c.belongTO}
+			cancel()
+			s.autoCommit()
+			convey.So(commits, convey.ShouldEqual, c.commits)
+		}
+	})
+}
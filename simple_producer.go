@@ -2,14 +2,24 @@ package healer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"sync"
+	"syscall"
 	"time"
 )
 
 // ErrProducerClosed is returned when adding message while producer is closed
 var ErrProducerClosed = fmt.Errorf("producer closed")
 
+var (
+	errZstdNeedsRecordBatch        = fmt.Errorf("zstd compression needs Produce v3+, the broker does not support it")
+	errIdempotenceNeedsRecordBatch = fmt.Errorf("idempotence needs Produce v3+, the broker does not support it")
+	errTransactionalSimpleProducer = fmt.Errorf("transactional.id is only supported by Producer")
+)
+
 // SimpleProducer is a simple producer that send message to certain one topic-partition
 type SimpleProducer struct {
 	config *ProducerConfig
@@ -31,6 +41,9 @@ type SimpleProducer struct {
 	compressionValue int8
 	compressor       Compressor
 
+	// state is not nil if idempotence is enabled
+	state *producerState
+
 	once sync.Once
 }
 
@@ -90,10 +103,12 @@ func NewSimpleProducer(ctx context.Context, topic string, partition int32, confi
 		p.compressionValue = COMPRESSION_SNAPPY
 	case "lz4":
 		p.compressionValue = COMPRESSION_LZ4
+	case "zstd":
+		p.compressionValue = COMPRESSION_ZSTD
 	default:
 		return nil, fmt.Errorf("unknown compress type")
 	}
-	p.compressor = NewCompressor(cfg.CompressionType)
+	p.compressor = NewCompressorWithLevel(cfg.CompressionType, cfg.CompressionLevel)
 
 	p.messageSet = make([]*Message, 0, cfg.MessageMaxCount)
 
@@ -112,6 +127,22 @@ func NewSimpleProducer(ctx context.Context, topic string, partition int32, confi
 		p.parent = parent.(*Producer)
 	}
 
+	if cfg.EnableIdempotence {
+		if state := ctx.Value(producerStateKey); state != nil {
+			p.state = state.(*producerState)
+		} else if cfg.TransactionalID != "" {
+			return nil, errTransactionalSimpleProducer
+		} else {
+			if p.brokers == nil {
+				brokerConfig := getBrokerConfigFromProducerConfig(p.config)
+				if p.brokers, err = NewBrokersWithConfig(p.config.BootstrapServers, brokerConfig); err != nil {
+					return nil, fmt.Errorf("init brokers error: %w", err)
+				}
+			}
+			p.state = newProducerState(p.brokers, &cfg)
+		}
+	}
+
 	// TODO wait to the next ticker to see if messageSet changes
 	go func() {
 		ticker := time.NewTicker(time.Duration(p.config.FlushIntervalMS) * time.Millisecond)
@@ -128,13 +159,17 @@ func NewSimpleProducer(ctx context.Context, topic string, partition int32, confi
 	return p, nil
 }
 
-// AddMessage add message to message set. If message set is full, send it to kafka synchronously
-func (p *SimpleProducer) AddMessage(key []byte, value []byte) error {
+// AddMessage add message to message set. If message set is full, send it to kafka synchronously.
+// headers are only sent if the broker supports RecordBatch (Produce v3+), they are dropped for older brokers.
+func (p *SimpleProducer) AddMessage(key []byte, value []byte, headers ...RecordHeader) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	if p.closed {
 		return ErrProducerClosed
 	}
+	if p.state != nil && p.state.transactional() && !p.state.isInTransaction() {
+		return errNoTransactionInProgress
+	}
 
 	valueCopy := make([]byte, len(value))
 	copy(valueCopy, value)
@@ -146,11 +181,10 @@ func (p *SimpleProducer) AddMessage(key []byte, value []byte) error {
 		Crc:        0,    // compute in message encode
 		Attributes: 0x00, // compress in upper message set level
 		MagicByte:  int8(p.config.HealerMagicByte),
+		Timestamp:  uint64(time.Now().UnixMilli()),
 		Key:        key,
 		Value:      valueCopy,
-	}
-	if p.config.HealerMagicByte == 1 {
-		message.Timestamp = uint64(time.Now().UnixMilli())
+		Headers:    headers,
 	}
 
 	p.messageSet = append(p.messageSet, message)
@@ -175,9 +209,18 @@ func (p *SimpleProducer) Flush() error {
 	return nil
 }
 
-func (p *SimpleProducer) flush(messageSet MessageSet) error {
+func (p *SimpleProducer) flush(messageSet MessageSet) (err error) {
 	logger.V(5).Info("flush messsages", "count", len(messageSet), "topic", p.topic, "partition", p.partition)
 
+	// the error of background flush is dropped, so it is recorded to fail the commit of the transaction
+	if p.state != nil && p.state.transactional() {
+		defer func() {
+			if err != nil {
+				p.state.setTxnError(err)
+			}
+		}()
+	}
+
 	produceRequest := &ProduceRequest{
 		RequiredAcks: p.config.Acks,
 		Timeout:      p.config.RequestTimeoutMS,
@@ -194,6 +237,7 @@ func (p *SimpleProducer) flush(messageSet MessageSet) error {
 			Partition      int32
 			MessageSetSize int32
 			MessageSet     MessageSet
+			Records        []byte
 		}
 	}, 1)
 	produceRequest.TopicBlocks[0].TopicName = p.topic
@@ -201,45 +245,209 @@ func (p *SimpleProducer) flush(messageSet MessageSet) error {
 		Partition      int32
 		MessageSetSize int32
 		MessageSet     MessageSet
+		Records        []byte
 	}, 1)
+	produceRequest.TopicBlocks[0].PartitonBlocks[0].Partition = p.partition
 
-	if p.compressionValue != 0 {
-		// FIXME: compressed message size if larger than before?
-		value := make([]byte, messageSet.Length())
-		offset := messageSet.Encode(value, 0)
-		value = value[:offset]
-		compressedValue, err := p.compressor.Compress(value)
+	// RecordBatch is used if the leader supports Produce v3+, legacy MessageSet otherwise
+	var batch *RecordBatch
+	version := p.leader.getHighestAvailableAPIVersion(API_ProduceRequest)
+	if version >= 3 {
+		batch = p.buildRecordBatch(messageSet)
+		if p.state != nil {
+			// sequence numbers must be sent in order, so other SimpleProducers of the same partition wait here
+			lock := p.state.partitionLock(p.topic, p.partition)
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err := p.state.initProducerID(); err != nil {
+				return err
+			}
+			if p.state.transactional() {
+				// the transaction must be aborted, batches after a failed one are not sent
+				if err := p.state.txnError(); err != nil {
+					return fmt.Errorf("transaction has failed: %w", err)
+				}
+				if err := p.state.addPartitionToTxn(p.topic, p.partition); err != nil {
+					return err
+				}
+				produceRequest.TransactionalID = &p.config.TransactionalID
+				batch.Attributes |= batchAttributeTransactional
+			}
+			batch.ProducerID, batch.ProducerEpoch, batch.BaseSequence = p.state.nextSequence(p.topic, p.partition)
+		}
+		records, err := batch.encode(version, p.compressor)
 		if err != nil {
-			return fmt.Errorf("compress messageset error:%s", err)
+			return fmt.Errorf("encode record batch error: %w", err)
 		}
-		var message *Message = &Message{
-			Offset:      0,
-			MessageSize: 0, // compute in message encode
-
-			Crc:        0, // compute in message encode
-			Attributes: 0x00 | p.compressionValue,
-			MagicByte:  int8(p.config.HealerMagicByte),
-			Key:        nil,
-			Value:      compressedValue,
+		produceRequest.TopicBlocks[0].PartitonBlocks[0].Records = records
+	} else {
+		if p.state != nil {
+			return errIdempotenceNeedsRecordBatch
 		}
-		if p.config.HealerMagicByte == 1 {
-			message.Timestamp = uint64(time.Now().UnixMilli())
+		messageSet, err := p.buildMessageSet(messageSet)
+		if err != nil {
+			return err
 		}
-		messageSet = []*Message{message}
+		produceRequest.TopicBlocks[0].PartitonBlocks[0].MessageSetSize = int32(len(messageSet))
+		produceRequest.TopicBlocks[0].PartitonBlocks[0].MessageSet = messageSet
 	}
-	produceRequest.TopicBlocks[0].PartitonBlocks[0].Partition = p.partition
-	produceRequest.TopicBlocks[0].PartitonBlocks[0].MessageSetSize = int32(len(messageSet))
-	produceRequest.TopicBlocks[0].PartitonBlocks[0].MessageSet = messageSet
 
-	rp, err := p.leader.RequestAndGet(produceRequest)
-	if err == nil {
-		err = rp.Error()
+	producerIDReset := false
+	// retried is true if the same batch with the same sequence has been sent before
+	retried := false
+	for retries := 0; ; retries++ {
+		_, err := p.leader.RequestAndGet(produceRequest)
+		if err == nil {
+			if p.state != nil {
+				p.state.commitSequence(p.topic, p.partition, batch.ProducerID, batch.ProducerEpoch, int32(len(messageSet)))
+			}
+			return nil
+		}
+
+		// the batch has been written by the previous request whose response is lost.
+		// it is not ours if the batch is sent for the first time, the sequence has been used by another batch
+		if p.state != nil && retried && errors.Is(err, KafkaError(46)) {
+			logger.Info("duplicate sequence number, batch has been written", "topic", p.topic, "partition", p.partition, "baseSequence", batch.BaseSequence)
+			p.state.commitSequence(p.topic, p.partition, batch.ProducerID, batch.ProducerEpoch, int32(len(messageSet)))
+			return nil
+		}
+
+		// OUT_OF_ORDER_SEQUENCE_NUMBER or UNKNOWN_PRODUCER_ID, reset producer id and send the batch again with new sequence number.
+		// only once for each batch. transactional producer could not reset producer id, the transaction should be aborted
+		if p.state != nil && !p.state.transactional() && !producerIDReset && (errors.Is(err, KafkaError(45)) || errors.Is(err, KafkaError(59))) {
+			logger.Info("reset producer id", "reason", err, "topic", p.topic, "partition", p.partition, "producerID", batch.ProducerID, "producerEpoch", batch.ProducerEpoch)
+			producerIDReset = true
+			if err := p.state.reset(batch.ProducerID, batch.ProducerEpoch); err != nil {
+				return err
+			}
+			batch.ProducerID, batch.ProducerEpoch, batch.BaseSequence = p.state.nextSequence(p.topic, p.partition)
+			records, err := batch.encode(version, p.compressor)
+			if err != nil {
+				return fmt.Errorf("encode record batch error: %w", err)
+			}
+			produceRequest.TopicBlocks[0].PartitonBlocks[0].Records = records
+			retried = false
+			continue
+		}
+
+		if retries >= p.config.Retries || !isRetriableProduceError(err) || errors.Is(err, KafkaError(46)) {
+			logger.Error(err, "failed to produce request", "topic", p.topic, "partition", p.partition, "retries", retries)
+			if p.state != nil {
+				p.abandonBatch(batch)
+			}
+			return err
+		}
+		logger.Info("produce request failed, retry", "reason", err, "topic", p.topic, "partition", p.partition, "retries", retries)
+		retried = true
+		time.Sleep(time.Duration(p.config.RetryBackOffMS) * time.Millisecond)
 	}
+}
+
+// abandonBatch is called if the batch of idempotent producer fails finally. The batch may have been written if the response is lost,
+// so its sequence must not be used by other records, or else they are taken as duplicate and dropped by broker.
+// producer id is reset for idempotent producer. for transactional producer, the error is recorded by flush and the transaction must be aborted,
+// and the epoch is bumped in the next transaction
+func (p *SimpleProducer) abandonBatch(batch *RecordBatch) {
+	if p.state.transactional() {
+		return
+	}
+	logger.Info("reset producer id after failed batch", "topic", p.topic, "partition", p.partition, "producerID", batch.ProducerID, "producerEpoch", batch.ProducerEpoch)
+	if err := p.state.reset(batch.ProducerID, batch.ProducerEpoch); err != nil {
+		logger.Error(err, "reset producer id failed, a new one is requested by the next batch")
+	}
+}
+
+// isRetriableProduceError returns true if the produce request could be sent again. if idempotence is not enabled, retrying may cause duplicate messages
+func isRetriableProduceError(err error) bool {
+	var kafkaError KafkaError
+	if errors.As(err, &kafkaError) {
+		return kafkaError.IsRetriable()
+	}
+	return os.IsTimeout(err) || errors.Is(err, io.EOF) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// buildMessageSet wraps messages to one compressed message if compression is enabled. it is used for brokers before Produce v3.
+// magic byte 2 is not valid in MessageSet, it falls back to 1.
+func (p *SimpleProducer) buildMessageSet(messageSet MessageSet) (MessageSet, error) {
+	magicByte := int8(p.config.HealerMagicByte)
+	if magicByte > 1 {
+		magicByte = 1
+	}
+	for _, message := range messageSet {
+		message.MagicByte = magicByte
+	}
+
+	if p.compressionValue == COMPRESSION_NONE {
+		return messageSet, nil
+	}
+	if p.compressionValue == COMPRESSION_ZSTD {
+		return nil, errZstdNeedsRecordBatch
+	}
+
+	// FIXME: compressed message size if larger than before?
+	value := make([]byte, messageSet.Length())
+	offset := messageSet.Encode(value, 0)
+	value = value[:offset]
+	compressedValue, err := p.compressor.Compress(value)
 	if err != nil {
-		logger.Error(err, "failed to produce request")
-		return err
+		return nil, fmt.Errorf("compress messageset error:%s", err)
 	}
-	return err
+	var message *Message = &Message{
+		Offset:      0,
+		MessageSize: 0, // compute in message encode
+
+		Crc:        0, // compute in message encode
+		Attributes: 0x00 | p.compressionValue,
+		MagicByte:  magicByte,
+		Key:        nil,
+		Value:      compressedValue,
+	}
+	if magicByte == 1 {
+		message.Timestamp = uint64(time.Now().UnixMilli())
+	}
+	return []*Message{message}, nil
+}
+
+// buildRecordBatch assembles messages to a RecordBatch (magic 2), records are compressed as a whole when encoding
+func (p *SimpleProducer) buildRecordBatch(messageSet MessageSet) *RecordBatch {
+	batch := &RecordBatch{
+		BaseOffset:           0,
+		PartitionLeaderEpoch: -1,
+		Magic:                2,
+		Attributes:           int16(p.compressionValue),
+		LastOffsetDelta:      int32(len(messageSet) - 1),
+		ProducerID:           -1,
+		ProducerEpoch:        -1,
+		BaseSequence:         -1,
+		Records:              make([]Record, len(messageSet)),
+	}
+	if len(messageSet) > 0 {
+		batch.BaseTimestamp = int64(messageSet[0].Timestamp)
+	}
+
+	for i, message := range messageSet {
+		timestamp := int64(message.Timestamp)
+		if timestamp > batch.MaxTimestamp {
+			batch.MaxTimestamp = timestamp
+		}
+		batch.Records[i] = Record{
+			attributes:     0,
+			timestampDelta: timestamp - batch.BaseTimestamp,
+			offsetDelta:    int32(i),
+			key:            message.Key,
+			value:          message.Value,
+			Headers:        message.Headers,
+		}
+	}
+	return batch
+}
+
+// clearMessages drops the messages which are not sent yet, it is used when aborting transaction
+func (p *SimpleProducer) clearMessages() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.messageSet = make([]*Message, 0, p.config.MessageMaxCount)
 }
 
 // Close closes the producer
@@ -0,0 +1,205 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+// stickyAssignmentStrategy balances the partitions among the members, and keeps the partitions on their previous owners as much as possible.
+// it is an eager strategy, all partitions are revoked before rebalance, so the previous assignment is sent in the user data,
+// which is compatible with the StickyAssignor of the java client
+type stickyAssignmentStrategy struct {
+}
+
+// Name implements AssignmentStrategy interface
+func (s *stickyAssignmentStrategy) Name() string {
+	return AssignmentStrategySticky
+}
+
+// UserData implements UserDataAssignmentStrategy interface. it encodes the previous assignment:
+// StickyAssignorUserData => [topic [partitions]] generation
+func (s *stickyAssignmentStrategy) UserData(assignments []*PartitionAssignment, generationID int32) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, int32(len(assignments)))
+	for _, a := range assignments {
+		writeString(buf, a.Topic)
+		binary.Write(buf, binary.BigEndian, int32(len(a.Partitions)))
+		for _, partitionID := range a.Partitions {
+			binary.Write(buf, binary.BigEndian, partitionID)
+		}
+	}
+	binary.Write(buf, binary.BigEndian, generationID)
+	return buf.Bytes()
+}
+
+// decodeStickyUserData returns the previous assignment in the user data, generation is ignored
+func decodeStickyUserData(userData []byte) (assignments []*PartitionAssignment) {
+	defer func() {
+		// user data may be sent by other strategies or clients, ignore it if it could not be decoded
+		if recover() != nil {
+			assignments = nil
+		}
+	}()
+
+	if len(userData) < 4 {
+		return nil
+	}
+	offset := 0
+	count := int(binary.BigEndian.Uint32(userData[offset:]))
+	offset += 4
+	assignments = make([]*PartitionAssignment, count)
+	for i := range assignments {
+		l := int(binary.BigEndian.Uint16(userData[offset:]))
+		offset += 2
+		a := &PartitionAssignment{Topic: string(userData[offset : offset+l])}
+		offset += l
+
+		n := int(binary.BigEndian.Uint32(userData[offset:]))
+		offset += 4
+		a.Partitions = make([]int32, n)
+		for j := range a.Partitions {
+			a.Partitions[j] = int32(binary.BigEndian.Uint32(userData[offset:]))
+			offset += 4
+		}
+		assignments[i] = a
+	}
+	return assignments
+}
+
+// Assign implements AssignmentStrategy interface
+func (s *stickyAssignmentStrategy) Assign(members []Member, topicMetadatas []TopicMetadata) GroupAssignment {
+	assignments, _ := stickyAssign(members, topicMetadatas, func(m *ProtocolMetadata) []*PartitionAssignment {
+		return decodeStickyUserData(m.UserData)
+	})
+
+	logger.V(5).Info("create tp assignments by StickyAssignmentStrategy", "assignment", assignments)
+	return newGroupAssignment(assignments)
+}
+
+// cooperativeStickyAssignmentStrategy is the cooperative version of stickyAssignmentStrategy, the owned partitions in ProtocolMetadata are kept.
+// A partition which moves to another member is not assigned in this round, its owner revokes it first,
+// and the member rejoins at once so that the partition is assigned in the second round
+type cooperativeStickyAssignmentStrategy struct {
+}
+
+// Name implements AssignmentStrategy interface
+func (s *cooperativeStickyAssignmentStrategy) Name() string {
+	return AssignmentStrategyCooperativeSticky
+}
+
+// Cooperative implements CooperativeAssignmentStrategy interface
+func (s *cooperativeStickyAssignmentStrategy) Cooperative() bool {
+	return true
+}
+
+// Assign implements AssignmentStrategy interface
+func (s *cooperativeStickyAssignmentStrategy) Assign(members []Member, topicMetadatas []TopicMetadata) GroupAssignment {
+	assignments, owners := stickyAssign(members, topicMetadatas, func(m *ProtocolMetadata) []*PartitionAssignment {
+		return m.OwnedPartitions
+	})
+
+	// partitions moving to another member are assigned after their owners revoke them
+	for memberID, partitions := range assignments {
+		kept := partitions[:0]
+		for _, tp := range partitions {
+			if owner, ok := owners[tp]; ok && owner != memberID {
+				continue
+			}
+			kept = append(kept, tp)
+		}
+		assignments[memberID] = kept
+	}
+
+	logger.V(5).Info("create tp assignments by CooperativeStickyAssignmentStrategy", "assignment", assignments)
+	return newGroupAssignment(assignments)
+}
+
+// stickyAssign returns the balanced assignments of all members, and the valid previous owners of the partitions.
+// a member keeps its previous partitions, which are got by ownedPartitions, unless it has more than its quota
+func stickyAssign(members []Member, topicMetadatas []TopicMetadata, ownedPartitions func(*ProtocolMetadata) []*PartitionAssignment) (assignments map[string][]topicPartition, owners map[topicPartition]string) {
+	memberIDs, subscriptions, partitions := subscribedPartitions(members, topicMetadatas)
+
+	assignments = make(map[string][]topicPartition)
+	owners = make(map[topicPartition]string)
+	if len(memberIDs) == 0 {
+		return
+	}
+
+	exists := make(map[topicPartition]bool)
+	for _, tp := range partitions {
+		exists[tp] = true
+	}
+	metadatas := make(map[string]*ProtocolMetadata)
+	for _, member := range members {
+		metadatas[member.MemberID] = NewProtocolMetadata(member.MemberMetadata)
+	}
+
+	// owned partitions are valid if they still exist and are subscribed. the first member keeps it if more than one member claim the same partition
+	for _, memberID := range memberIDs {
+		assignments[memberID] = make([]topicPartition, 0)
+		for _, owned := range ownedPartitions(metadatas[memberID]) {
+			if !subscriptions[memberID][owned.Topic] {
+				continue
+			}
+			for _, partitionID := range owned.Partitions {
+				tp := topicPartition{owned.Topic, partitionID}
+				if _, ok := owners[tp]; ok || !exists[tp] {
+					continue
+				}
+				owners[tp] = memberID
+				assignments[memberID] = append(assignments[memberID], tp)
+			}
+		}
+		sortTopicPartitions(assignments[memberID])
+	}
+
+	// members owning the most partitions have the priority to keep one more partition than the others
+	var (
+		minQuota = len(partitions) / len(memberIDs)
+		extra    = len(partitions) % len(memberIDs)
+		byOwned  = make([]string, len(memberIDs))
+	)
+	copy(byOwned, memberIDs)
+	sort.SliceStable(byOwned, func(i, j int) bool {
+		return len(assignments[byOwned[i]]) > len(assignments[byOwned[j]])
+	})
+	for _, memberID := range byOwned {
+		quota := minQuota
+		if len(assignments[memberID]) > minQuota && extra > 0 {
+			quota++
+			extra--
+		}
+		if len(assignments[memberID]) > quota {
+			assignments[memberID] = assignments[memberID][:quota]
+		}
+	}
+
+	assigned := make(map[topicPartition]bool)
+	for _, memberID := range memberIDs {
+		for _, tp := range assignments[memberID] {
+			assigned[tp] = true
+		}
+	}
+
+	// the other partitions go to the member with the fewest partitions which subscribes the topic
+	for _, tp := range partitions {
+		if assigned[tp] {
+			continue
+		}
+		target := ""
+		for _, memberID := range memberIDs {
+			if !subscriptions[memberID][tp.topic] {
+				continue
+			}
+			if target == "" || len(assignments[memberID]) < len(assignments[target]) {
+				target = memberID
+			}
+		}
+		if target != "" {
+			assignments[target] = append(assignments[target], tp)
+		}
+	}
+
+	return assignments, owners
+}
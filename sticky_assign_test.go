@@ -0,0 +1,120 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func newTestMember(memberID string, topics []string, owned map[string][]int32) Member {
+	m := &ProtocolMetadata{Version: 1, Subscription: topics}
+	for topic, partitions := range owned {
+		m.OwnedPartitions = append(m.OwnedPartitions, &PartitionAssignment{Topic: topic, Partitions: partitions})
+	}
+	return Member{MemberID: memberID, MemberMetadata: m.Encode()}
+}
+
+func newTestTopicMetadata(topic string, count int) TopicMetadata {
+	t := TopicMetadata{TopicName: topic}
+	for i := 0; i < count; i++ {
+		t.PartitionMetadatas = append(t.PartitionMetadatas, &PartitionMetadataInfo{PartitionID: int32(i)})
+	}
+	return t
+}
+
+// decodeGroupAssignment returns member -> topic -> partitions
+func decodeGroupAssignment(groupAssignment GroupAssignment) map[string]map[string][]int32 {
+	rst := make(map[string]map[string][]int32)
+	for _, a := range groupAssignment {
+		memberAssignment, _ := NewMemberAssignment(a.MemberAssignment)
+		rst[a.MemberID] = make(map[string][]int32)
+		for _, p := range memberAssignment.PartitionAssignments {
+			rst[a.MemberID][p.Topic] = p.Partitions
+		}
+	}
+	return rst
+}
+
+func TestCooperativeStickyAssign(t *testing.T) {
+	s := &cooperativeStickyAssignmentStrategy{}
+	topics := []TopicMetadata{newTestTopicMetadata("test", 6), newTestTopicMetadata("other", 2)}
+
+	convey.Convey("assign all partitions at the first time", t, func() {
+		members := []Member{
+			newTestMember("a", []string{"test"}, nil),
+			newTestMember("b", []string{"test"}, nil),
+		}
+		convey.So(decodeGroupAssignment(s.Assign(members, topics)), convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"test": {0, 2, 4}},
+			"b": {"test": {1, 3, 5}},
+		})
+	})
+
+	convey.Convey("a new member joins, moving partitions are assigned in the second round", t, func() {
+		members := []Member{
+			newTestMember("a", []string{"test"}, map[string][]int32{"test": {0, 1, 2}}),
+			newTestMember("b", []string{"test"}, map[string][]int32{"test": {3, 4, 5}}),
+			newTestMember("c", []string{"test"}, nil),
+		}
+		first := decodeGroupAssignment(s.Assign(members, topics))
+		convey.So(first, convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"test": {0, 1}},
+			"b": {"test": {3, 4}},
+			"c": {},
+		})
+
+		members = []Member{
+			newTestMember("a", []string{"test"}, first["a"]),
+			newTestMember("b", []string{"test"}, first["b"]),
+			newTestMember("c", []string{"test"}, nil),
+		}
+		convey.So(decodeGroupAssignment(s.Assign(members, topics)), convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"test": {0, 1}},
+			"b": {"test": {3, 4}},
+			"c": {"test": {2, 5}},
+		})
+	})
+
+	convey.Convey("partitions of the left member are assigned at once, the others do not move", t, func() {
+		members := []Member{
+			newTestMember("a", []string{"test"}, map[string][]int32{"test": {0, 1}}),
+			newTestMember("c", []string{"test"}, map[string][]int32{"test": {2, 5}}),
+		}
+		convey.So(decodeGroupAssignment(s.Assign(members, topics)), convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"test": {0, 1, 3}},
+			"c": {"test": {2, 4, 5}},
+		})
+	})
+
+	convey.Convey("invalid owned partitions are ignored", t, func() {
+		members := []Member{
+			newTestMember("a", []string{"test"}, map[string][]int32{"test": {0, 1, 9}, "other": {0}}),
+			newTestMember("b", []string{"test", "other"}, map[string][]int32{"test": {1, 2}}),
+		}
+		convey.So(decodeGroupAssignment(s.Assign(members, topics)), convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"test": {0, 1, 3, 4}},
+			"b": {"other": {0, 1}, "test": {2, 5}},
+		})
+	})
+}
+
+func TestStickyAssign(t *testing.T) {
+	convey.Convey("eager sticky strategy gets the previous assignment from user data", t, func() {
+		s := &stickyAssignmentStrategy{}
+		topics := []TopicMetadata{newTestTopicMetadata("test", 6)}
+
+		previous := []*PartitionAssignment{{Topic: "test", Partitions: []int32{0, 1, 2, 5}}}
+		convey.So(decodeStickyUserData(s.UserData(previous, 3)), convey.ShouldResemble, previous)
+		convey.So(decodeStickyUserData([]byte{0, 0, 0, 9}), convey.ShouldBeNil)
+
+		a := &ProtocolMetadata{Subscription: []string{"test"}, UserData: s.UserData(previous, 3)}
+		b := &ProtocolMetadata{Subscription: []string{"test"}, UserData: s.UserData(nil, 3)}
+		members := []Member{{MemberID: "a", MemberMetadata: a.Encode()}, {MemberID: "b", MemberMetadata: b.Encode()}}
+
+		// moving partitions are assigned at once in eager protocol
+		convey.So(decodeGroupAssignment(s.Assign(members, topics)), convey.ShouldResemble, map[string]map[string][]int32{
+			"a": {"test": {0, 1, 2}},
+			"b": {"test": {3, 4, 5}},
+		})
+	})
+}
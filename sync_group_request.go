@@ -2,11 +2,23 @@ package healer
 
 import "encoding/binary"
 
+/*
+SyncGroup Request (Version: 3) => group_id generation_id member_id group_instance_id [assignments]
+  group_id => STRING
+  generation_id => INT32
+  member_id => STRING
+  group_instance_id => NULLABLE_STRING (since version 3)
+  assignments => member_id assignment
+    member_id => STRING
+    assignment => BYTES
+*/
+
 type SyncGroupRequest struct {
 	*RequestHeader
 	GroupID         string
 	GenerationID    int32
 	MemberID        string
+	GroupInstanceID *string // static membership, since version 3
 	GroupAssignment GroupAssignment
 }
 
@@ -28,6 +40,9 @@ func NewSyncGroupRequest(clientID, groupID string, generationID int32, memberID
 
 func (r *SyncGroupRequest) Length() int {
 	requestLength := r.RequestHeader.length() + 2 + len(r.GroupID) + 4 + 2 + len(r.MemberID)
+	if r.Version() >= 3 {
+		requestLength += len(encodeNullableString(r.GroupInstanceID))
+	}
 	requestLength += 4
 	for _, x := range r.GroupAssignment {
 		requestLength += 2 + len(x.MemberID)
@@ -58,6 +73,10 @@ func (r *SyncGroupRequest) Encode(version uint16) []byte {
 	offset += 2
 	offset += copy(payload[offset:], r.MemberID)
 
+	if r.Version() >= 3 {
+		offset += copy(payload[offset:], encodeNullableString(r.GroupInstanceID))
+	}
+
 	binary.BigEndian.PutUint32(payload[offset:], uint32(len(r.GroupAssignment)))
 	offset += 4
 	for _, x := range r.GroupAssignment {
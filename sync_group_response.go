@@ -1,6 +1,7 @@
 package healer
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 )
@@ -8,6 +9,7 @@ import (
 // SyncGroupResponse is the response of syncgroup request
 type SyncGroupResponse struct {
 	CorrelationID    uint32 `json:"correlation_id"`
+	ThrottleTimeMS   int32  `json:"throttle_time_ms"` // since version 1
 	ErrorCode        int16  `json:"error_code"`
 	MemberAssignment []byte `json:"member_assignment"`
 }
@@ -20,7 +22,7 @@ func (r SyncGroupResponse) Error() error {
 }
 
 // NewSyncGroupResponse create a NewSyncGroupResponse instance from response payload bytes
-func NewSyncGroupResponse(payload []byte) (r SyncGroupResponse, err error) {
+func NewSyncGroupResponse(payload []byte, version uint16) (r SyncGroupResponse, err error) {
 	offset := 0
 	responseLength := int(binary.BigEndian.Uint32(payload))
 	if responseLength+4 != len(payload) {
@@ -31,6 +33,11 @@ func NewSyncGroupResponse(payload []byte) (r SyncGroupResponse, err error) {
 	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
 	offset += 4
 
+	if version >= 1 {
+		r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+	}
+
 	r.ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
 	offset += 2
 	if r.ErrorCode != 0 {
@@ -45,3 +52,24 @@ func NewSyncGroupResponse(payload []byte) (r SyncGroupResponse, err error) {
 
 	return r, err
 }
+
+// Encode encodes SyncGroupResponse to []byte, just for test
+func (r SyncGroupResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	if version >= 1 {
+		binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+	}
+	binary.Write(buf, binary.BigEndian, r.ErrorCode)
+	binary.Write(buf, binary.BigEndian, int32(len(r.MemberAssignment)))
+	buf.Write(r.MemberAssignment)
+
+	return buf.Bytes()
+}
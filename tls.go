@@ -0,0 +1,191 @@
+package healer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	errTLSCertKeyPair       = errors.New("tls cert and key must be set together")
+	errTLSNoCertificate     = errors.New("no certificate found in tls ca")
+	errInvalidTLSMinVersion = errors.New("tls min.version must be one of 1.0, 1.1, 1.2, 1.3")
+	errUnknownCipherSuite   = errors.New("unknown tls cipher suite")
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// certReloader loads the client certificate from the files, and loads it again if the files are modified.
+// the old certificate is kept if the new one could not be loaded, cert and key may be written one after the other in rotation
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	lock        sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+// certReloaders are shared by the connections using the same cert and key files
+var certReloaders = struct {
+	sync.Mutex
+	m map[[2]string]*certReloader
+}{m: make(map[[2]string]*certReloader)}
+
+func getCertReloader(certFile, keyFile string) *certReloader {
+	certReloaders.Lock()
+	defer certReloaders.Unlock()
+
+	k := [2]string{certFile, keyFile}
+	if r, ok := certReloaders.m[k]; ok {
+		return r
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	certReloaders.m[k] = r
+	return r
+}
+
+// getCertificate returns the client certificate, it is reloaded if cert or key file is modified
+func (r *certReloader) getCertificate() (*tls.Certificate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	certInfo, certErr := os.Stat(r.certFile)
+	keyInfo, keyErr := os.Stat(r.keyFile)
+	if r.cert != nil && certErr == nil && keyErr == nil && certInfo.ModTime().Equal(r.certModTime) && keyInfo.ModTime().Equal(r.keyModTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err == nil {
+		err = certErr
+	}
+	if err == nil {
+		err = keyErr
+	}
+	if err != nil {
+		if r.cert != nil {
+			logger.Error(err, "reload tls client certificate failed, use the old one", "cert", r.certFile, "key", r.keyFile)
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("load tls client certificate error: %w", err)
+	}
+
+	if r.cert != nil {
+		logger.Info("tls client certificate reloaded", "cert", r.certFile, "key", r.keyFile)
+	}
+	r.cert = &cert
+	r.certModTime, r.keyModTime = certInfo.ModTime(), keyInfo.ModTime()
+	return r.cert, nil
+}
+
+func createTLSConfig(tlsConfig *TLSConfig) (*tls.Config, error) {
+	t := &tls.Config{
+		InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
+		ServerName:         tlsConfig.ServerName,
+	}
+
+	// client certificate is optional
+	switch {
+	case tlsConfig.CertPEM != "" || tlsConfig.KeyPEM != "":
+		if tlsConfig.CertPEM == "" || tlsConfig.KeyPEM == "" {
+			return nil, errTLSCertKeyPair
+		}
+		cert, err := tls.X509KeyPair([]byte(tlsConfig.CertPEM), []byte(tlsConfig.KeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("load tls client certificate error: %w", err)
+		}
+		t.Certificates = []tls.Certificate{cert}
+	case tlsConfig.Cert != "" || tlsConfig.Key != "":
+		if tlsConfig.Cert == "" || tlsConfig.Key == "" {
+			return nil, errTLSCertKeyPair
+		}
+		reloader := getCertReloader(tlsConfig.Cert, tlsConfig.Key)
+		if _, err := reloader.getCertificate(); err != nil {
+			return nil, err
+		}
+		t.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return reloader.getCertificate()
+		}
+	}
+
+	rootCAs, err := tlsConfig.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+	t.RootCAs = rootCAs
+
+	if tlsConfig.MinVersion != "" {
+		v, ok := tlsVersions[tlsConfig.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errInvalidTLSMinVersion, tlsConfig.MinVersion)
+		}
+		t.MinVersion = v
+	}
+
+	if len(tlsConfig.CipherSuites) > 0 {
+		if t.CipherSuites, err = cipherSuiteIDs(tlsConfig.CipherSuites); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// rootCAs returns nil to use the system roots if CA is not set
+func (tlsConfig *TLSConfig) rootCAs() (*x509.CertPool, error) {
+	var caPEM []byte
+	switch {
+	case tlsConfig.CAPEM != "":
+		caPEM = []byte(tlsConfig.CAPEM)
+	case tlsConfig.CA != "":
+		b, err := os.ReadFile(tlsConfig.CA)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca error: %w", err)
+		}
+		caPEM = b
+	default:
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if tlsConfig.SystemRoots {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("load system cert pool error: %w", err)
+		}
+		pool = systemPool
+	}
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errTLSNoCertificate
+	}
+	return pool, nil
+}
+
+// cipherSuiteIDs converts the names of cipher suites to ids
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownCipherSuite, name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
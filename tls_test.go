@@ -0,0 +1,105 @@
+package healer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// generateCert returns PEM of a self-signed certificate and its key
+func generateCert(commonName string) (certPEM, keyPEM []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestCreateTLSConfig(t *testing.T) {
+	certPEM, keyPEM := generateCert("healer")
+
+	convey.Convey("one-way tls with system roots", t, func() {
+		c, err := createTLSConfig(&TLSConfig{ServerName: "kafka"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.RootCAs, convey.ShouldBeNil)
+		convey.So(c.Certificates, convey.ShouldBeEmpty)
+		convey.So(c.GetClientCertificate, convey.ShouldBeNil)
+		convey.So(c.ServerName, convey.ShouldEqual, "kafka")
+	})
+
+	convey.Convey("inline pem", t, func() {
+		c, err := createTLSConfig(&TLSConfig{CertPEM: string(certPEM), KeyPEM: string(keyPEM), CAPEM: string(certPEM)})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(len(c.Certificates), convey.ShouldEqual, 1)
+		convey.So(c.RootCAs, convey.ShouldNotBeNil)
+
+		_, err = createTLSConfig(&TLSConfig{CertPEM: string(certPEM)})
+		convey.So(errors.Is(err, errTLSCertKeyPair), convey.ShouldBeTrue)
+
+		_, err = createTLSConfig(&TLSConfig{CAPEM: "not a pem"})
+		convey.So(errors.Is(err, errTLSNoCertificate), convey.ShouldBeTrue)
+	})
+
+	convey.Convey("min version and cipher suites", t, func() {
+		c, err := createTLSConfig(&TLSConfig{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.MinVersion, convey.ShouldEqual, tls.VersionTLS13)
+		convey.So(c.CipherSuites, convey.ShouldResemble, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256})
+
+		_, err = createTLSConfig(&TLSConfig{MinVersion: "1.4"})
+		convey.So(errors.Is(err, errInvalidTLSMinVersion), convey.ShouldBeTrue)
+
+		_, err = createTLSConfig(&TLSConfig{CipherSuites: []string{"TLS_UNKNOWN"}})
+		convey.So(errors.Is(err, errUnknownCipherSuite), convey.ShouldBeTrue)
+	})
+}
+
+func TestCertReload(t *testing.T) {
+	convey.Convey("client certificate is reloaded after the files are rotated", t, func() {
+		dir := t.TempDir()
+		certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+		certPEM, keyPEM := generateCert("first")
+		os.WriteFile(certFile, certPEM, 0600)
+		os.WriteFile(keyFile, keyPEM, 0600)
+
+		c, err := createTLSConfig(&TLSConfig{Cert: certFile, Key: keyFile})
+		convey.So(err, convey.ShouldBeNil)
+		first, err := c.GetClientCertificate(nil)
+		convey.So(err, convey.ShouldBeNil)
+
+		// only cert is rotated, the old certificate is used
+		certPEM, keyPEM = generateCert("second")
+		os.WriteFile(certFile, certPEM, 0600)
+		os.Chtimes(certFile, time.Now(), time.Now().Add(time.Minute))
+		cert, err := c.GetClientCertificate(nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cert, convey.ShouldEqual, first)
+
+		os.WriteFile(keyFile, keyPEM, 0600)
+		os.Chtimes(keyFile, time.Now(), time.Now().Add(time.Minute))
+		cert, err = c.GetClientCertificate(nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cert, convey.ShouldNotEqual, first)
+		leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+		convey.So(leaf.Subject.CommonName, convey.ShouldEqual, "second")
+	})
+}
@@ -0,0 +1,165 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+TxnOffsetCommit Request (Version: 0) => transactional_id group_id producer_id producer_epoch [topics]
+  transactional_id => STRING
+  group_id => STRING
+  producer_id => INT64
+  producer_epoch => INT16
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index committed_offset committed_metadata
+      partition_index => INT32
+      committed_offset => INT64
+      committed_metadata => NULLABLE_STRING
+
+version 1 is the same as version 0, committed_leader_epoch (INT32) is added after committed_offset in version 2
+*/
+
+// TxnOffsetCommitRequest commits offsets of the group in the transaction, it is sent to group coordinator
+type TxnOffsetCommitRequest struct {
+	*RequestHeader
+	TransactionalID string
+	GroupID         string
+	ProducerID      int64
+	ProducerEpoch   int16
+	Topics          []TxnOffsetCommitTopic
+}
+
+// TxnOffsetCommitTopic is the partitions of one topic in TxnOffsetCommitRequest
+type TxnOffsetCommitTopic struct {
+	Name       string
+	Partitions []TxnOffsetCommitPartition
+}
+
+// TxnOffsetCommitPartition is the offset of one partition in TxnOffsetCommitRequest
+type TxnOffsetCommitPartition struct {
+	PartitionIndex       int32
+	CommittedOffset      int64
+	CommittedLeaderEpoch int32 // version 2+
+	CommittedMetadata    *string
+}
+
+// NewTxnOffsetCommitRequest creates a new TxnOffsetCommitRequest without offsets
+func NewTxnOffsetCommitRequest(clientID string, transactionalID string, groupID string, producerID int64, producerEpoch int16) *TxnOffsetCommitRequest {
+	requestHeader := &RequestHeader{
+		APIKey:     API_TxnOffsetCommit,
+		APIVersion: 0,
+		ClientID:   &clientID,
+	}
+	return &TxnOffsetCommitRequest{
+		RequestHeader:   requestHeader,
+		TransactionalID: transactionalID,
+		GroupID:         groupID,
+		ProducerID:      producerID,
+		ProducerEpoch:   producerEpoch,
+		Topics:          make([]TxnOffsetCommitTopic, 0),
+	}
+}
+
+// AddPartition adds the offset of a partition to the request
+func (r *TxnOffsetCommitRequest) AddPartition(topic string, partition int32, offset int64) {
+	p := TxnOffsetCommitPartition{
+		PartitionIndex:       partition,
+		CommittedOffset:      offset,
+		CommittedLeaderEpoch: -1,
+	}
+	for i := range r.Topics {
+		if r.Topics[i].Name == topic {
+			r.Topics[i].Partitions = append(r.Topics[i].Partitions, p)
+			return
+		}
+	}
+	r.Topics = append(r.Topics, TxnOffsetCommitTopic{Name: topic, Partitions: []TxnOffsetCommitPartition{p}})
+}
+
+// Encode encodes TxnOffsetCommitRequest to []byte
+func (r *TxnOffsetCommitRequest) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	// length
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	header := make([]byte, r.RequestHeader.length())
+	l := r.RequestHeader.EncodeTo(header)
+	buf.Write(header[:l])
+
+	writeString(buf, r.TransactionalID)
+	writeString(buf, r.GroupID)
+	binary.Write(buf, binary.BigEndian, r.ProducerID)
+	binary.Write(buf, binary.BigEndian, r.ProducerEpoch)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.PartitionIndex)
+			binary.Write(buf, binary.BigEndian, partition.CommittedOffset)
+			if version >= 2 {
+				binary.Write(buf, binary.BigEndian, partition.CommittedLeaderEpoch)
+			}
+			writeNullableString(buf, partition.CommittedMetadata)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeTxnOffsetCommitRequest decodes []byte to TxnOffsetCommitRequest, just for test
+func DecodeTxnOffsetCommitRequest(payload []byte) (r TxnOffsetCommitRequest, err error) {
+	offset := 4 // payload length
+
+	header, o := DecodeRequestHeader(payload[offset:])
+	r.RequestHeader = &header
+	offset += o
+	version := r.APIVersion
+
+	r.TransactionalID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	r.GroupID, o = nonnullableString(payload[offset:])
+	offset += o
+
+	r.ProducerID = int64(binary.BigEndian.Uint64(payload[offset:]))
+	offset += 8
+
+	r.ProducerEpoch = int16(binary.BigEndian.Uint16(payload[offset:]))
+	offset += 2
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]TxnOffsetCommitTopic, topicCount)
+	for i := range r.Topics {
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]TxnOffsetCommitPartition, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			p := &r.Topics[i].Partitions[j]
+			p.PartitionIndex = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			p.CommittedOffset = int64(binary.BigEndian.Uint64(payload[offset:]))
+			offset += 8
+			if version >= 2 {
+				p.CommittedLeaderEpoch = int32(binary.BigEndian.Uint32(payload[offset:]))
+				offset += 4
+			}
+			p.CommittedMetadata, o = nullableString(payload[offset:])
+			offset += o
+		}
+	}
+
+	return r, nil
+}
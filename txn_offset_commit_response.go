@@ -0,0 +1,111 @@
+package healer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+TxnOffsetCommit Response (Version: 0) => throttle_time_ms [topics]
+  throttle_time_ms => INT32
+  topics => name [partitions]
+    name => STRING
+    partitions => partition_index error_code
+      partition_index => INT32
+      error_code => INT16
+*/
+
+// TxnOffsetCommitResponse is the response of TxnOffsetCommitRequest
+type TxnOffsetCommitResponse struct {
+	CorrelationID  uint32
+	ThrottleTimeMS int32
+	Topics         []TxnOffsetCommitTopicResult
+}
+
+// TxnOffsetCommitTopicResult is the result of one topic in TxnOffsetCommitResponse
+type TxnOffsetCommitTopicResult struct {
+	Name       string
+	Partitions []TxnOffsetCommitPartitionResult
+}
+
+// TxnOffsetCommitPartitionResult is the result of one partition in TxnOffsetCommitResponse
+type TxnOffsetCommitPartitionResult struct {
+	PartitionIndex int32
+	ErrorCode      int16
+}
+
+// Error returns the first error in the partitions
+func (r TxnOffsetCommitResponse) Error() error {
+	for _, topic := range r.Topics {
+		for _, partition := range topic.Partitions {
+			if partition.ErrorCode != 0 {
+				return fmt.Errorf("commit offset of %s-%d in txn error: %w", topic.Name, partition.PartitionIndex, KafkaError(partition.ErrorCode))
+			}
+		}
+	}
+	return nil
+}
+
+// NewTxnOffsetCommitResponse creates a new TxnOffsetCommitResponse from []byte
+func NewTxnOffsetCommitResponse(payload []byte, version uint16) (r TxnOffsetCommitResponse, err error) {
+	offset := 0
+	responseLength := int(binary.BigEndian.Uint32(payload))
+	if responseLength+4 != len(payload) {
+		return r, fmt.Errorf("TxnOffsetCommit response length did not match: %d!=%d", responseLength+4, len(payload))
+	}
+	offset += 4
+
+	r.CorrelationID = binary.BigEndian.Uint32(payload[offset:])
+	offset += 4
+
+	r.ThrottleTimeMS = int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+
+	topicCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+	offset += 4
+	r.Topics = make([]TxnOffsetCommitTopicResult, topicCount)
+	for i := range r.Topics {
+		var o int
+		r.Topics[i].Name, o = nonnullableString(payload[offset:])
+		offset += o
+
+		partitionCount := int32(binary.BigEndian.Uint32(payload[offset:]))
+		offset += 4
+		r.Topics[i].Partitions = make([]TxnOffsetCommitPartitionResult, partitionCount)
+		for j := range r.Topics[i].Partitions {
+			r.Topics[i].Partitions[j].PartitionIndex = int32(binary.BigEndian.Uint32(payload[offset:]))
+			offset += 4
+			r.Topics[i].Partitions[j].ErrorCode = int16(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+		}
+	}
+
+	return r, nil
+}
+
+// Encode encodes TxnOffsetCommitResponse to []byte, just for test
+func (r TxnOffsetCommitResponse) Encode(version uint16) (rst []byte) {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	defer func() {
+		length := len(rst) - 4
+		binary.BigEndian.PutUint32(rst, uint32(length))
+	}()
+
+	binary.Write(buf, binary.BigEndian, r.CorrelationID)
+	binary.Write(buf, binary.BigEndian, r.ThrottleTimeMS)
+
+	binary.Write(buf, binary.BigEndian, int32(len(r.Topics)))
+	for _, topic := range r.Topics {
+		writeString(buf, topic.Name)
+		binary.Write(buf, binary.BigEndian, int32(len(topic.Partitions)))
+		for _, partition := range topic.Partitions {
+			binary.Write(buf, binary.BigEndian, partition.PartitionIndex)
+			binary.Write(buf, binary.BigEndian, partition.ErrorCode)
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,52 @@
+package healer
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestTxnOffsetCommitEncodeDecode(t *testing.T) {
+	convey.Convey("Test TxnOffsetCommit Request and Response Encode and Decode", t, func() {
+		metadata := "metadata"
+		for _, version := range availableVersions[API_TxnOffsetCommit] {
+			t.Logf("version: %v", version)
+
+			request := NewTxnOffsetCommitRequest("healer", "txn-1", "group-1", 100, 2)
+			request.APIVersion = version
+			request.AddPartition("topic-1", 0, 1000)
+			request.AddPartition("topic-1", 1, 2000)
+			request.AddPartition("topic-2", 0, 3000)
+			request.Topics[1].Partitions[0].CommittedMetadata = &metadata
+			if version < 2 {
+				for i := range request.Topics {
+					for j := range request.Topics[i].Partitions {
+						request.Topics[i].Partitions[j].CommittedLeaderEpoch = 0
+					}
+				}
+			}
+
+			decodedRequest, err := DecodeTxnOffsetCommitRequest(request.Encode(version))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+			response := TxnOffsetCommitResponse{
+				CorrelationID:  1,
+				ThrottleTimeMS: 10,
+				Topics: []TxnOffsetCommitTopicResult{
+					{
+						Name: "topic-1",
+						Partitions: []TxnOffsetCommitPartitionResult{
+							{PartitionIndex: 0, ErrorCode: 0},
+							{PartitionIndex: 1, ErrorCode: 0},
+						},
+					},
+				},
+			}
+			decodedResponse, err := NewTxnOffsetCommitResponse(response.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(decodedResponse, convey.ShouldResemble, response)
+			convey.So(decodedResponse.Error(), convey.ShouldBeNil)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package healer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestDescribeUserScramCredentialsEncodeDecode(t *testing.T) {
+	convey.Convey("Test DescribeUserScramCredentials Request and Response Encode and Decode", t, func() {
+		version := uint16(0)
+
+		for _, users := range [][]string{nil, {"alice", "bob"}} {
+			request := NewDescribeUserScramCredentialsRequest("healer", users)
+			decodedRequest, err := DecodeDescribeUserScramCredentialsRequest(request.Encode(version), version)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(&decodedRequest, convey.ShouldResemble, request)
+		}
+
+		message := "unknown user"
+		header := NewResponseHeader(API_DescribeUserScramCredentials, version)
+		header.CorrelationID = 1
+		response := DescribeUserScramCredentialsResponse{
+			ResponseHeader: header,
+			ThrottleTimeMS: 10,
+			Results: []DescribeUserScramCredentialsResult{
+				{
+					User: "alice",
+					CredentialInfos: []ScramCredentialInfo{
+						{Mechanism: ScramMechanismTypeSHA256, Iterations: 4096},
+						{Mechanism: ScramMechanismTypeSHA512, Iterations: 8192},
+					},
+				},
+				{User: "bob", ErrorCode: 91, ErrorMessage: &message, CredentialInfos: []ScramCredentialInfo{}},
+			},
+		}
+		decodedResponse, err := NewDescribeUserScramCredentialsResponse(response.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(decodedResponse, convey.ShouldResemble, response)
+		convey.So(errors.Is(decodedResponse.Error(), KafkaError(91)), convey.ShouldBeTrue)
+	})
+}
+
+func TestAlterUserScramCredentialsEncodeDecode(t *testing.T) {
+	convey.Convey("Test AlterUserScramCredentials Request and Response Encode and Decode", t, func() {
+		version := uint16(0)
+
+		upsertion, err := NewScramCredentialUpsertion("alice", ScramMechanismTypeSHA512, "alice-secret", DefaultScramIterations)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(len(upsertion.Salt), convey.ShouldEqual, scramSaltLength)
+		convey.So(upsertion.SaltedPassword, convey.ShouldResemble, ScramSHA512.SaltedPassword("alice-secret", upsertion.Salt, DefaultScramIterations))
+
+		_, err = NewScramCredentialUpsertion("alice", ScramMechanismTypeUnknown, "alice-secret", DefaultScramIterations)
+		convey.So(err, convey.ShouldNotBeNil)
+
+		request := NewAlterUserScramCredentialsRequest("healer", []ScramCredentialDeletion{{Name: "bob", Mechanism: ScramMechanismTypeSHA256}}, []ScramCredentialUpsertion{upsertion})
+		decodedRequest, err := DecodeAlterUserScramCredentialsRequest(request.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(&decodedRequest, convey.ShouldResemble, request)
+
+		message := "no credential"
+		header := NewResponseHeader(API_AlterUserScramCredentials, version)
+		header.CorrelationID = 1
+		response := AlterUserScramCredentialsResponse{
+			ResponseHeader: header,
+			Results: []AlterUserScramCredentialsResult{
+				{User: "alice"},
+				{User: "bob", ErrorCode: 91, ErrorMessage: &message},
+			},
+		}
+		decodedResponse, err := NewAlterUserScramCredentialsResponse(response.Encode(version), version)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(decodedResponse, convey.ShouldResemble, response)
+		convey.So(errors.Is(decodedResponse.Error(), KafkaError(91)), convey.ShouldBeTrue)
+	})
+}
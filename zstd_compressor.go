@@ -0,0 +1,39 @@
+package healer
+
+import (
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ZstdCompressor compresses value with zstd, it is only valid in RecordBatch (magic 2)
+type ZstdCompressor struct {
+	encoder *zstd.Encoder
+}
+
+func newZstdCompressor(level int) *ZstdCompressor {
+	encoderLevel := zstd.SpeedDefault
+	if level > 0 {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	// options are valid, so error is always nil
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+	return &ZstdCompressor{encoder: encoder}
+}
+
+func (c *ZstdCompressor) Compress(value []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(value, nil), nil
+}
+
+var (
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderOnce sync.Once
+)
+
+// zstdDecompress decompresses value with a shared zstd decoder, DecodeAll is safe for concurrent use
+func zstdDecompress(value []byte) ([]byte, error) {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	})
+	return zstdDecoder.DecodeAll(value, nil)
+}